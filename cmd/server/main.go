@@ -3,7 +3,10 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -17,6 +20,7 @@ import (
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/setup"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/worker"
 
 	_ "github.com/joho/godotenv/autoload"
 	"go.uber.org/zap"
@@ -74,32 +78,153 @@ func main() {
 	newsletterRepo := repository.NewPostgresNewsletterRepo(dbPool)
 	postRepo := repository.NewPostRepository(dbPool)
 	subscriberRepo := repository.NewFirestoreSubscriberRepository(firestoreClient)
+	deliveryRepo := repository.NewDeliveryRepository(dbPool)
+	digestRepo := repository.NewDigestRepository(dbPool)
+	emailTemplateRepo := repository.NewEmailTemplateRepository(dbPool)
+	bounceRepo := repository.NewBounceRepository(dbPool)
+	complaintRepo := repository.NewComplaintRepository(dbPool)
+	suppressionRepo := repository.NewSuppressionRepository(dbPool)
+	segmentRepo := repository.NewSegmentRepository(dbPool)
 
 	// Initialize Email Service
-	emailService, err := setup.NewGmailEmailService(
-		cfg.EmailFrom,
-		cfg.GoogleAppPassword,
-		cfg.SMTPHost,
-		cfg.SMTPPort,
-		zap.NewStdLog(logger),
-	)
+	emailRenderer, err := service.NewTemplateEmailRenderer(emailTemplateRepo)
+	if err != nil {
+		sugar.Fatalf("Error loading email templates: %v", err)
+	}
+	emailService, emailProvider, err := setup.NewEmailService(cfg, emailRenderer, zap.NewStdLog(logger))
 	if err != nil {
 		sugar.Fatalf("Error initializing email service: %v", err)
 	}
+	devMailbox, _ := emailProvider.(*service.MemoryMailbox)
+	if devMailbox != nil {
+		sugar.Infof("Emails are captured in memory, see http://localhost:%d/dev/mailbox", cfg.Port)
+	}
+	if fileProvider, ok := emailProvider.(*service.FileEmailProvider); ok {
+		sugar.Infof("Emails are written to %s (%s)", fileProvider.Dir(), fileProvider.Name())
+	}
 
 	// Initialize Services
-	passwordResetSvc, err := setup.NewPasswordResetService(
-		cfg.FirebaseAPIKey,
-		&http.Client{Timeout: 10 * time.Second},
+	passwordResetSvc, err := setup.NewEmailPasswordResetService(
+		firebaseAuthClient,
+		emailService,
 		zap.NewStdLog(logger),
 	)
 	if err != nil {
 		sugar.Fatalf("Error initializing password reset service: %v", err)
 	}
 	editorSvc := service.NewEditorService(editorRepo, setup.NewFirebaseAuthAdapter(firebaseAuthClient), &http.Client{Timeout: 10 * time.Second}, cfg.FirebaseAPIKey)
-	subscriberSvc := service.NewSubscriberService(subscriberRepo, newsletterRepo, editorRepo, emailService, cfg.AppBaseURL)
+	tokenKeys, tokenKeyID := cfg.SubscriptionTokenKeys, cfg.SubscriptionTokenKeyID
+	if len(tokenKeys) == 0 {
+		// Only reached in development; configuration validation requires keys everywhere else.
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			sugar.Fatalf("Error generating subscription token secret: %v", err)
+		}
+		tokenKeys, tokenKeyID = map[string]string{"ephemeral": hex.EncodeToString(secret)}, "ephemeral"
+		sugar.Warn("SUBSCRIPTION_TOKEN_KEYS is not set; using a random development key, so confirmation and unsubscribe links stop working when the server restarts")
+	}
+	tokenSigner, err := service.NewSubscriptionTokenSigner(tokenKeyID, tokenKeys)
+	if err != nil {
+		sugar.Fatalf("Error initializing subscription token signer: %v", err)
+	}
+	subscriberSvc := service.NewSubscriberService(subscriberRepo, newsletterRepo, editorRepo, suppressionRepo, emailService, cfg.AppBaseURL, tokenSigner, cfg.ConfirmationTokenTTL, cfg.UnsubscribeTokenTTL)
 	newsletterSvc := service.NewNewsletterService(newsletterRepo, postRepo, subscriberSvc)
-	publishingSvc := service.NewPublishingService(newsletterSvc, subscriberSvc, emailService, cfg)
+	segmentSvc := service.NewSegmentService(newsletterSvc, segmentRepo, subscriberRepo, deliveryRepo)
+	publishingSvc := service.NewPublishingService(newsletterSvc, subscriberSvc, segmentSvc, postRepo, deliveryRepo, digestRepo, suppressionRepo, emailService, emailRenderer, tokenSigner, cfg)
+	emailTemplateSvc := service.NewEmailTemplateService(newsletterSvc, emailTemplateRepo, emailRenderer)
+	bounceSvc := service.NewBounceService(bounceRepo, subscriberRepo, cfg.BounceHardLimit)
+	complaintSvc := service.NewComplaintService(complaintRepo, deliveryRepo, subscriberRepo, newsletterSvc, tokenSigner)
+	suppressionSvc := service.NewSuppressionService(suppressionRepo)
+
+	// Initialize Delivery Worker
+	deliveryWorker, err := worker.NewDeliveryWorker(
+		deliveryRepo,
+		postRepo,
+		newsletterRepo,
+		subscriberRepo,
+		suppressionRepo,
+		emailService,
+		worker.DeliveryWorkerConfig{
+			Workers:      cfg.DeliveryWorkerCount,
+			BatchSize:    cfg.DeliveryBatchSize,
+			PollInterval: cfg.DeliveryPollInterval,
+			LockTimeout:  cfg.DeliveryLockTimeout,
+			MaxAttempts:  cfg.DeliveryMaxAttempts,
+			RetryBase:    cfg.DeliveryRetryBase,
+			RetryMax:     cfg.DeliveryRetryMax,
+			AppBaseURL:   cfg.AppBaseURL,
+			Limits: worker.SendLimits{
+				RatePerSecond:     cfg.SendRatePerSecond,
+				DailyLimit:        cfg.SendDailyLimit,
+				DomainConcurrency: cfg.SendDomainConcurrency,
+				DomainLimits:      cfg.SendDomainLimits,
+			},
+		},
+		zap.NewStdLog(logger),
+	)
+	if err != nil {
+		sugar.Fatalf("Error initializing delivery worker: %v", err)
+	}
+	deliveryWorker.Start(ctx)
+
+	// Initialize Post Scheduler
+	postScheduler, err := worker.NewPostScheduler(
+		postRepo,
+		publishingSvc,
+		worker.PostSchedulerConfig{
+			PollInterval: cfg.SchedulerPollInterval,
+			BatchSize:    20,
+		},
+		zap.NewStdLog(logger),
+	)
+	if err != nil {
+		sugar.Fatalf("Error initializing post scheduler: %v", err)
+	}
+	postScheduler.Start(ctx)
+
+	// Initialize Digest Worker, sharing the delivery worker's send limits
+	digestWorker, err := worker.NewDigestWorker(
+		digestRepo,
+		subscriberRepo,
+		suppressionRepo,
+		postRepo,
+		newsletterRepo,
+		emailService,
+		deliveryWorker.Limiter(),
+		worker.DigestWorkerConfig{
+			PollInterval: cfg.DigestPollInterval,
+			BatchSize:    cfg.DeliveryBatchSize,
+			LockTimeout:  cfg.DeliveryLockTimeout,
+			MaxAttempts:  cfg.DeliveryMaxAttempts,
+			RetryBase:    cfg.DeliveryRetryBase,
+			RetryMax:     cfg.DeliveryRetryMax,
+			AppBaseURL:   cfg.AppBaseURL,
+		},
+		zap.NewStdLog(logger),
+	)
+	if err != nil {
+		sugar.Fatalf("Error initializing digest worker: %v", err)
+	}
+	digestWorker.Start(ctx)
+
+	// Initialize Bounce Mailbox Worker, when bounces and feedback loop reports are delivered to a local mailbox
+	var bounceWorker *worker.BounceMailboxWorker
+	if cfg.BounceMailbox != "" {
+		bounceWorker, err = worker.NewBounceMailboxWorker(
+			bounceSvc,
+			complaintSvc,
+			worker.BounceMailboxConfig{
+				Path:         cfg.BounceMailbox,
+				Format:       cfg.BounceMailboxFormat,
+				PollInterval: cfg.BouncePollInterval,
+			},
+			zap.NewStdLog(logger),
+		)
+		if err != nil {
+			sugar.Fatalf("Error initializing bounce mailbox worker: %v", err)
+		}
+		bounceWorker.Start(ctx)
+	}
 
 	// Initialize Router
 	routerDeps := router.RouterDependencies{
@@ -108,11 +233,19 @@ func main() {
 		NewsletterService: newsletterSvc,
 		SubscriberService: subscriberSvc,
 		PublishingService: publishingSvc,
+		EmailTemplateService: emailTemplateSvc,
+		SegmentService:    segmentSvc,
 		EditorService:     editorSvc,
 		PasswordResetSvc:  passwordResetSvc,
 		EditorRepo:        editorRepo,
 		Logger:            sugar,
 		CORSAllowedOrigins: cfg.CORSAllowedOrigins,
+		DevMailbox:        devMailbox,
+		BounceService:     bounceSvc,
+		ComplaintService:  complaintSvc,
+		BounceWebhookSecret: cfg.BounceWebhookSecret,
+		SuppressionService: suppressionSvc,
+		AdminEmails:       cfg.AdminEmails,
 	}
 	mainRouter := router.NewRouter(routerDeps)
 
@@ -151,5 +284,21 @@ func main() {
 		sugar.Fatalf("Could not start server: %v", err)
 	}
 
+	// Let in-flight deliveries finish; anything still queued is picked up after restart
+	sugar.Info("Stopping post scheduler, delivery and digest workers...")
+	postScheduler.Stop()
+	deliveryWorker.Stop()
+	digestWorker.Stop()
+	if bounceWorker != nil {
+		bounceWorker.Stop()
+	}
+
+	// Close pooled email connections once nothing sends anymore
+	if closer, ok := emailProvider.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			sugar.Errorf("Email provider shutdown error: %v", err)
+		}
+	}
+
 	sugar.Info("Server stopped.")
 }
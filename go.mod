@@ -17,6 +17,7 @@ require (
 	github.com/pressly/goose/v3 v3.15.1
 	github.com/stretchr/testify v1.10.0
 	go.uber.org/zap v1.25.0
+	golang.org/x/time v0.8.0
 	google.golang.org/api v0.215.0
 	google.golang.org/grpc v1.71.0
 )
@@ -84,7 +85,6 @@ require (
 	golang.org/x/sync v0.14.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
-	golang.org/x/time v0.8.0 // indirect
 	golang.org/x/tools v0.33.0 // indirect
 	google.golang.org/appengine/v2 v2.0.6 // indirect
 	google.golang.org/genproto v0.0.0-20241118233622-e639e219e697 // indirect
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,20 +23,75 @@ type Config struct {
 	FirebaseAPIKey         string
 
 	// Email configuration
+	EmailProvider     string // gmail, smtp, http, or in development file, maildir, mbox or memory
 	GoogleAppPassword string
 	EmailFrom         string
 	SMTPHost          string
 	SMTPPort          string
+	SMTPUsername      string
+	SMTPPassword      string
+	SMTPAuth          string // plain, login, cram-md5 or none
+	SMTPTLS           string // starttls, implicit or none
+	SMTPPoolSize      int    // Maximum open connections to the SMTP server
+	SMTPIdleTimeout   time.Duration
+
+	SMTPMaxMessagesPerConnection int
+	EmailAPIURL       string
+	EmailAPIKey       string
+	EmailDir          string // Directory of the file, maildir and mbox providers
+
+	// DKIM signing configuration, optional. Either all or none of these are set.
+	DKIMDomain     string
+	DKIMSelector   string
+	DKIMPrivateKey string // PEM encoded RSA or Ed25519 private key
+
+	// Delivery worker configuration
+	DeliveryWorkerCount  int
+	DeliveryBatchSize    int
+	DeliveryPollInterval time.Duration
+	DeliveryLockTimeout  time.Duration
+	DeliveryMaxAttempts  int
+	DeliveryRetryBase    time.Duration
+	DeliveryRetryMax     time.Duration
+
+	// Outbound send limits, applied by the delivery worker; zero disables a limit
+	SendRatePerSecond     float64
+	SendDailyLimit        int            // Messages per UTC day, 500 by default with the gmail provider
+	SendDomainConcurrency int            // Concurrent sends to one recipient domain
+	SendDomainLimits      map[string]int // Per-domain overrides of SendDomainConcurrency
+
+	// Bounce processing configuration
+	BounceHardLimit     int    // Hard bounces after which an address is no longer sent to
+	BounceMailbox       string // Optional maildir or mbox the bounce address is delivered to
+	BounceMailboxFormat string // maildir or mbox
+	BouncePollInterval  time.Duration
+	BounceWebhookSecret string // Enables POST /api/webhooks/bounces when set
+
+	// Post scheduler configuration
+	SchedulerPollInterval time.Duration
+
+	// Digest configuration
+	DigestPollInterval time.Duration // How often due daily and weekly digests are looked up
+
+	// Subscription token configuration
+	SubscriptionTokenKeys  map[string]string // Secrets by key ID verifying the links emailed to subscribers; required outside development, where a random key is used for the process when empty
+	SubscriptionTokenKeyID string            // Key signing new links, the first one listed
+	ConfirmationTokenTTL   time.Duration     // How long a double opt-in confirmation link stays valid
+	UnsubscribeTokenTTL    time.Duration     // How long an unsubscribe link stays valid; zero never expires them
 
 	// Application configuration
 	AppBaseURL string
 	Port       int
 
 	// Environment
+	AppEnv             string // "development" enables the local email providers and the /dev/mailbox viewer
 	RailwayEnvironment string
 
 	// CORS configuration
 	CORSAllowedOrigins []string
+
+	// AdminEmails are the lower-case emails of editors allowed to manage instance-wide settings such as the global suppression list
+	AdminEmails []string
 }
 
 // Load reads configuration from environment variables and validates required fields
@@ -56,6 +113,27 @@ func Load() (*Config, error) {
 	config.SMTPHost = getEnvWithDefault("SMTP_HOST", "smtp.gmail.com")
 	config.SMTPPort = getEnvWithDefault("SMTP_PORT", "587")
 
+	// Email provider settings; the generic SMTP credentials fall back to the Gmail ones
+	config.EmailProvider = strings.ToLower(getEnvWithDefault("EMAIL_PROVIDER", "gmail"))
+	config.SMTPUsername = getEnvWithDefault("SMTP_USERNAME", config.EmailFrom)
+	config.SMTPPassword = getEnvWithDefault("SMTP_PASSWORD", config.GoogleAppPassword)
+	config.SMTPAuth = strings.ToLower(getEnvWithDefault("SMTP_AUTH", "plain"))
+	config.SMTPTLS = strings.ToLower(getEnvWithDefault("SMTP_TLS", "starttls"))
+	config.EmailAPIURL = os.Getenv("EMAIL_API_URL")
+	config.EmailAPIKey = os.Getenv("EMAIL_API_KEY")
+	config.EmailDir = getEnvWithDefault("EMAIL_DIR", "tmp/mail")
+	config.AppEnv = strings.ToLower(getEnvWithDefault("APP_ENV", "production"))
+
+	// Bounce processing settings
+	config.BounceMailbox = os.Getenv("BOUNCE_MAILBOX")
+	config.BounceMailboxFormat = strings.ToLower(getEnvWithDefault("BOUNCE_MAILBOX_FORMAT", "maildir"))
+	config.BounceWebhookSecret = os.Getenv("BOUNCE_WEBHOOK_SECRET")
+
+	// DKIM settings; keys set from a single-line env var carry literal \n sequences
+	config.DKIMDomain = os.Getenv("DKIM_DOMAIN")
+	config.DKIMSelector = os.Getenv("DKIM_SELECTOR")
+	config.DKIMPrivateKey = strings.ReplaceAll(os.Getenv("DKIM_PRIVATE_KEY"), "\\n", "\n")
+
 	// Parse port with default
 	port, err := strconv.Atoi(getEnvWithDefault("PORT", "8080"))
 	if err != nil {
@@ -63,6 +141,85 @@ func Load() (*Config, error) {
 	}
 	config.Port = port
 
+	// Parse delivery worker settings with defaults
+	if config.DeliveryWorkerCount, err = strconv.Atoi(getEnvWithDefault("DELIVERY_WORKER_COUNT", "4")); err != nil || config.DeliveryWorkerCount <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_WORKER_COUNT: must be a positive integer")
+	}
+	if config.DeliveryBatchSize, err = strconv.Atoi(getEnvWithDefault("DELIVERY_BATCH_SIZE", "20")); err != nil || config.DeliveryBatchSize <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_BATCH_SIZE: must be a positive integer")
+	}
+	if config.DeliveryPollInterval, err = time.ParseDuration(getEnvWithDefault("DELIVERY_POLL_INTERVAL", "2s")); err != nil || config.DeliveryPollInterval <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_POLL_INTERVAL: must be a positive duration")
+	}
+	if config.DeliveryLockTimeout, err = time.ParseDuration(getEnvWithDefault("DELIVERY_LOCK_TIMEOUT", "5m")); err != nil || config.DeliveryLockTimeout <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_LOCK_TIMEOUT: must be a positive duration")
+	}
+	if config.DeliveryMaxAttempts, err = strconv.Atoi(getEnvWithDefault("DELIVERY_MAX_ATTEMPTS", "5")); err != nil || config.DeliveryMaxAttempts <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_MAX_ATTEMPTS: must be a positive integer")
+	}
+	if config.DeliveryRetryBase, err = time.ParseDuration(getEnvWithDefault("DELIVERY_RETRY_BASE_DELAY", "1m")); err != nil || config.DeliveryRetryBase <= 0 {
+		return nil, fmt.Errorf("invalid DELIVERY_RETRY_BASE_DELAY: must be a positive duration")
+	}
+	if config.DeliveryRetryMax, err = time.ParseDuration(getEnvWithDefault("DELIVERY_RETRY_MAX_DELAY", "1h")); err != nil || config.DeliveryRetryMax < config.DeliveryRetryBase {
+		return nil, fmt.Errorf("invalid DELIVERY_RETRY_MAX_DELAY: must be a duration no shorter than DELIVERY_RETRY_BASE_DELAY")
+	}
+	if config.SchedulerPollInterval, err = time.ParseDuration(getEnvWithDefault("SCHEDULER_POLL_INTERVAL", "30s")); err != nil || config.SchedulerPollInterval <= 0 {
+		return nil, fmt.Errorf("invalid SCHEDULER_POLL_INTERVAL: must be a positive duration")
+	}
+	if config.DigestPollInterval, err = time.ParseDuration(getEnvWithDefault("DIGEST_POLL_INTERVAL", "1m")); err != nil || config.DigestPollInterval <= 0 {
+		return nil, fmt.Errorf("invalid DIGEST_POLL_INTERVAL: must be a positive duration")
+	}
+
+	if config.SendRatePerSecond, err = strconv.ParseFloat(getEnvWithDefault("SEND_RATE_PER_SECOND", "5"), 64); err != nil || config.SendRatePerSecond < 0 {
+		return nil, fmt.Errorf("invalid SEND_RATE_PER_SECOND: must be a non-negative number")
+	}
+	// Gmail accounts may send about 500 messages a day, so do not exceed that unless told otherwise
+	defaultDailyLimit := "0"
+	if config.EmailProvider == "gmail" {
+		defaultDailyLimit = "500"
+	}
+	if config.SendDailyLimit, err = strconv.Atoi(getEnvWithDefault("SEND_DAILY_LIMIT", defaultDailyLimit)); err != nil || config.SendDailyLimit < 0 {
+		return nil, fmt.Errorf("invalid SEND_DAILY_LIMIT: must be a non-negative integer")
+	}
+	if config.SendDomainConcurrency, err = strconv.Atoi(getEnvWithDefault("SEND_DOMAIN_CONCURRENCY", "2")); err != nil || config.SendDomainConcurrency < 0 {
+		return nil, fmt.Errorf("invalid SEND_DOMAIN_CONCURRENCY: must be a non-negative integer")
+	}
+	if config.SendDomainLimits, err = parseDomainLimits(os.Getenv("SEND_DOMAIN_LIMITS")); err != nil {
+		return nil, fmt.Errorf("invalid SEND_DOMAIN_LIMITS: %w", err)
+	}
+
+	if config.BounceHardLimit, err = strconv.Atoi(getEnvWithDefault("BOUNCE_HARD_LIMIT", "1")); err != nil || config.BounceHardLimit <= 0 {
+		return nil, fmt.Errorf("invalid BOUNCE_HARD_LIMIT: must be a positive integer")
+	}
+	if config.BouncePollInterval, err = time.ParseDuration(getEnvWithDefault("BOUNCE_POLL_INTERVAL", "1m")); err != nil || config.BouncePollInterval <= 0 {
+		return nil, fmt.Errorf("invalid BOUNCE_POLL_INTERVAL: must be a positive duration")
+	}
+	if config.BounceMailboxFormat != "maildir" && config.BounceMailboxFormat != "mbox" {
+		return nil, fmt.Errorf("invalid BOUNCE_MAILBOX_FORMAT: must be maildir or mbox")
+	}
+
+	if config.ConfirmationTokenTTL, err = time.ParseDuration(getEnvWithDefault("CONFIRMATION_TOKEN_TTL", "72h")); err != nil || config.ConfirmationTokenTTL <= 0 {
+		return nil, fmt.Errorf("invalid CONFIRMATION_TOKEN_TTL: must be a positive duration")
+	}
+	// Unsubscribe links must keep working for at least 30 days after a message is sent (CAN-SPAM)
+	if config.UnsubscribeTokenTTL, err = time.ParseDuration(getEnvWithDefault("UNSUBSCRIBE_TOKEN_TTL", "8760h")); err != nil ||
+		(config.UnsubscribeTokenTTL != 0 && config.UnsubscribeTokenTTL < 30*24*time.Hour) {
+		return nil, fmt.Errorf("invalid UNSUBSCRIBE_TOKEN_TTL: must be 0 or a duration of at least 720h")
+	}
+	if config.SubscriptionTokenKeys, config.SubscriptionTokenKeyID, err = parseTokenKeys(os.Getenv("SUBSCRIPTION_TOKEN_KEYS")); err != nil {
+		return nil, fmt.Errorf("invalid SUBSCRIPTION_TOKEN_KEYS: %w", err)
+	}
+
+	if config.SMTPPoolSize, err = strconv.Atoi(getEnvWithDefault("SMTP_POOL_SIZE", "4")); err != nil || config.SMTPPoolSize <= 0 {
+		return nil, fmt.Errorf("invalid SMTP_POOL_SIZE: must be a positive integer")
+	}
+	if config.SMTPMaxMessagesPerConnection, err = strconv.Atoi(getEnvWithDefault("SMTP_MAX_MESSAGES_PER_CONNECTION", "100")); err != nil || config.SMTPMaxMessagesPerConnection <= 0 {
+		return nil, fmt.Errorf("invalid SMTP_MAX_MESSAGES_PER_CONNECTION: must be a positive integer")
+	}
+	if config.SMTPIdleTimeout, err = time.ParseDuration(getEnvWithDefault("SMTP_IDLE_TIMEOUT", "30s")); err != nil || config.SMTPIdleTimeout <= 0 {
+		return nil, fmt.Errorf("invalid SMTP_IDLE_TIMEOUT: must be a positive duration")
+	}
+
 	// Parse CORS allowed origins from environment variable
 	corsOrigins := os.Getenv("CORS_ALLOWED_ORIGINS")
 	if corsOrigins != "" {
@@ -71,6 +228,13 @@ func Load() (*Config, error) {
 		config.CORSAllowedOrigins = []string{"http://localhost:3000"}
 	}
 
+	// Parse instance admins from environment variable
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			config.AdminEmails = append(config.AdminEmails, email)
+		}
+	}
+
 	// Validate required fields
 	if err := config.validate(); err != nil {
 		return nil, err
@@ -79,6 +243,58 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// parseDomainLimits parses a comma-separated list of domain=limit pairs, e.g. "gmail.com=1,outlook.com=3".
+func parseDomainLimits(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		domain, limit, ok := strings.Cut(pair, "=")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !ok || domain == "" {
+			return nil, fmt.Errorf("expected domain=limit, got %q", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("limit for %s must be a positive integer", domain)
+		}
+		limits[domain] = n
+	}
+	return limits, nil
+}
+
+// tokenKeyIDRegex restricts key IDs to characters that need no escaping in a token.
+var tokenKeyIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseTokenKeys parses a comma-separated list of key-id:secret pairs, e.g. "2024-06:s3cret,2024-01:old".
+// The first key signs new tokens; the others only verify tokens signed before a rotation.
+// Errors never include the secrets.
+func parseTokenKeys(value string) (map[string]string, string, error) {
+	keys := make(map[string]string)
+	currentKeyID := ""
+	for i, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyID, secret, ok := strings.Cut(pair, ":")
+		keyID = strings.TrimSpace(keyID)
+		if !ok || !tokenKeyIDRegex.MatchString(keyID) || secret == "" {
+			return nil, "", fmt.Errorf("entry %d: expected key-id:secret with a key ID of letters, digits, - and _", i+1)
+		}
+		if _, exists := keys[keyID]; exists {
+			return nil, "", fmt.Errorf("key ID %s is listed twice", keyID)
+		}
+		keys[keyID] = secret
+		if currentKeyID == "" {
+			currentKeyID = keyID
+		}
+	}
+	return keys, currentKeyID, nil
+}
+
 // validate checks required configuration fields
 func (c *Config) validate() error {
 	required := map[string]string{
@@ -94,9 +310,25 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if c.DKIMDomain != "" || c.DKIMSelector != "" || c.DKIMPrivateKey != "" {
+		if c.DKIMDomain == "" || c.DKIMSelector == "" || c.DKIMPrivateKey == "" {
+			return fmt.Errorf("DKIM_DOMAIN, DKIM_SELECTOR and DKIM_PRIVATE_KEY must be set together")
+		}
+	}
+
+	// A random key would break every emailed link on restart and differ between instances, so it is only for development.
+	if len(c.SubscriptionTokenKeys) == 0 && !c.IsDevelopment() {
+		return fmt.Errorf("SUBSCRIPTION_TOKEN_KEYS is required outside development")
+	}
+
 	return nil
 }
 
+// IsDevelopment reports whether the server runs in a local development environment
+func (c *Config) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
+
 // GetDatabaseURL returns the appropriate database URL
 func (c *Config) GetDatabaseURL() string {
 	if c.DatabasePublicURL != "" && c.RailwayEnvironment == "" {
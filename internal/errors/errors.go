@@ -36,6 +36,10 @@ var (
 	ErrEditorNotFound     = fmt.Errorf("%w: editor not found", ErrNotFound) // 404
 	ErrPostNotFound       = fmt.Errorf("%w: post not found", ErrNotFound) // 404
 	ErrSubscriberNotFound = fmt.Errorf("%w: subscriber not found", ErrNotFound) // 404
+	ErrDeliveryNotFound   = fmt.Errorf("%w: delivery not found", ErrNotFound) // 404
+	ErrEmailTemplateNotFound = fmt.Errorf("%w: email template not found", ErrNotFound) // 404
+	ErrSuppressionNotFound   = fmt.Errorf("%w: suppression not found", ErrNotFound) // 404
+	ErrSegmentNotFound       = fmt.Errorf("%w: segment not found", ErrNotFound) // 404
 )
 
 // Validation errors - wrap ErrValidation for specific validation failures
@@ -44,6 +48,7 @@ var (
 	ErrInvalidEmail   = fmt.Errorf("%w: invalid email format", ErrValidation) // 400
 	ErrContentTooLong = fmt.Errorf("%w: content is too long", ErrValidation) // 400
 	ErrTokenInvalid   = fmt.Errorf("%w: token is invalid", ErrValidation) // 400
+	ErrTokenExpired   = fmt.Errorf("%w: token has expired", ErrValidation) // 400
 )
 
 // Business logic errors - wrap appropriate base errors for specific business rules
@@ -52,6 +57,8 @@ var (
 	ErrAlreadyConfirmed      = fmt.Errorf("%w: already confirmed", ErrConflict) // 409
 	ErrSubscriptionNotFound  = fmt.Errorf("%w: subscription not found", ErrNotFound) // 404
 	ErrInvalidOrExpiredToken = fmt.Errorf("%w: invalid or expired token", ErrUnauthorized) // 401
+	ErrInvalidPostTransition = fmt.Errorf("%w: invalid post status transition", ErrConflict) // 409
+	ErrAddressSuppressed     = fmt.Errorf("%w: email address is on the suppression list", ErrConflict) // 409
 )
 
 // Error wrapping functions provide consistent error context formatting
@@ -314,6 +314,8 @@ func TestDomainSpecificErrors(t *testing.T) {
 		assert.True(t, IsNotFound(ErrNewsletterNotFound))
 		assert.False(t, IsValidation(ErrNewsletterNotFound))
 		assert.Contains(t, ErrNewsletterNotFound.Error(), "newsletter not found")
+		assert.True(t, IsNotFound(ErrEmailTemplateNotFound))
+		assert.True(t, IsNotFound(ErrSegmentNotFound))
 	})
 
 	t.Run("validation specific errors", func(t *testing.T) {
@@ -326,6 +328,7 @@ func TestDomainSpecificErrors(t *testing.T) {
 	t.Run("business logic errors", func(t *testing.T) {
 		assert.True(t, IsConflict(ErrAlreadySubscribed))
 		assert.True(t, IsUnauthorized(ErrInvalidOrExpiredToken))
+		assert.True(t, IsConflict(ErrInvalidPostTransition))
 		assert.Contains(t, ErrAlreadySubscribed.Error(), "already subscribed")
 	})
 }
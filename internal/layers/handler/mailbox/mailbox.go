@@ -0,0 +1,99 @@
+package mailbox
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+)
+
+// MessagesResponse is the JSON listing of the development mailbox.
+type MessagesResponse struct {
+	Data []service.CapturedEmail `json:"data"`
+}
+
+// mailboxPage lists the captured messages; each links to its HTML, text and raw renderings.
+var mailboxPage = template.Must(template.New("mailbox").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Mailbox ({{len .}})</title>
+<style>body{font-family:Arial,sans-serif;margin:24px}table{border-collapse:collapse;width:100%}td,th{border-bottom:1px solid #ddd;padding:6px;text-align:left}</style>
+</head>
+<body>
+<h1>Mailbox</h1>
+<p>Messages sent by the memory email provider. Nothing here was delivered.</p>
+<table>
+<tr><th>#</th><th>Received</th><th>From</th><th>To</th><th>Subject</th><th></th></tr>
+{{range .}}<tr>
+<td>{{.ID}}</td><td>{{.ReceivedAt.Format "2006-01-02 15:04:05"}}</td><td>{{.From}}</td><td>{{.To}}</td><td>{{.Subject}}</td>
+<td><a href="/dev/mailbox/{{.ID}}">html</a> <a href="/dev/mailbox/{{.ID}}?format=text">text</a> <a href="/dev/mailbox/{{.ID}}?format=raw">raw</a></td>
+</tr>{{else}}<tr><td colspan="6">No messages yet.</td></tr>{{end}}
+</table>
+</body>
+</html>
+`))
+
+// ListMessagesHandler shows the messages captured by the development mailbox, newest first.
+// GET /dev/mailbox?format=html|json
+func ListMessagesHandler(mailbox *service.MemoryMailbox) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		messages := mailbox.Messages()
+		if r.URL.Query().Get("format") == "json" {
+			commonHandler.JSONResponse(w, MessagesResponse{Data: messages}, http.StatusOK)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		mailboxPage.Execute(w, messages)
+	}
+}
+
+// GetMessageHandler returns a captured message as rendered HTML, its plain-text part or the raw encoded message.
+// GET /dev/mailbox/{messageID}?format=html|text|raw
+func GetMessageHandler(mailbox *service.MemoryMailbox) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "messageID"))
+		if err != nil {
+			commonHandler.JSONError(w, "Invalid message ID", http.StatusBadRequest)
+			return
+		}
+		msg, ok := mailbox.Message(id)
+		if !ok {
+			commonHandler.JSONError(w, "Message not found", http.StatusNotFound)
+			return
+		}
+
+		contentType, body := "text/html; charset=utf-8", msg.HTMLBody
+		switch r.URL.Query().Get("format") {
+		case "", "html":
+			if body == "" {
+				contentType, body = "text/plain; charset=utf-8", msg.TextBody
+			}
+		case "text":
+			contentType, body = "text/plain; charset=utf-8", msg.TextBody
+		case "raw":
+			contentType, body = "text/plain; charset=utf-8", msg.Raw
+		default:
+			commonHandler.JSONError(w, "Invalid format parameter, expected html, text or raw", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}
+}
+
+// ClearMessagesHandler empties the development mailbox.
+// DELETE /dev/mailbox
+func ClearMessagesHandler(mailbox *service.MemoryMailbox) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mailbox.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
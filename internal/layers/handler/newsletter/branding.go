@@ -0,0 +1,62 @@
+package newsletter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// UpdateBrandingRequest defines the expected request body for replacing a newsletter's branding.
+// Omitted fields are cleared, so the layout defaults apply to them again.
+type UpdateBrandingRequest struct {
+	LogoURL        string `json:"logo_url" validate:"max=500"`
+	AccentColor    string `json:"accent_color"`
+	FooterText     string `json:"footer_text" validate:"max=500"`
+	MailingAddress string `json:"mailing_address" validate:"max=500"`
+	HeaderHTML     string `json:"header_html" validate:"max=10000"`
+	FooterHTML     string `json:"footer_html" validate:"max=10000"`
+}
+
+// UpdateBrandingHandler replaces the branding applied to a newsletter's emails.
+// PUT /api/newsletters/{newsletterID}/branding
+func UpdateBrandingHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized: editor ID not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		var req UpdateBrandingRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		branding := models.NewsletterBranding{
+			LogoURL:        req.LogoURL,
+			AccentColor:    req.AccentColor,
+			FooterText:     req.FooterText,
+			MailingAddress: req.MailingAddress,
+			HeaderHTML:     req.HeaderHTML,
+			FooterHTML:     req.FooterHTML,
+		}
+		updatedNewsletter, err := svc.UpdateNewsletterBranding(r.Context(), editorAuthID, newsletterID, branding)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "newsletter branding update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, updatedNewsletter, http.StatusOK)
+	}
+}
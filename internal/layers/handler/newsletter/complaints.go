@@ -0,0 +1,55 @@
+package newsletter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+const (
+	DefaultComplaintStatsDays = 30
+	MaxComplaintStatsDays     = 365
+)
+
+// ComplaintStatsHandler returns the spam complaint rate of the newsletter's issues over the last days.
+// GET /api/newsletters/{newsletterID}/complaints?days=30
+func ComplaintStatsHandler(svc service.ComplaintServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		days := DefaultComplaintStatsDays
+		if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+			parsedDays, err := strconv.Atoi(daysStr)
+			if err != nil || parsedDays <= 0 || parsedDays > MaxComplaintStatsDays {
+				commonHandler.JSONError(w, "Invalid days parameter, expected 1 to 365", http.StatusBadRequest)
+				return
+			}
+			days = parsedDays
+		}
+
+		since := time.Now().UTC().AddDate(0, 0, -days)
+		stats, err := svc.GetComplaintStats(r.Context(), editorAuthID, newsletterID, since)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "complaint stats")
+			return
+		}
+
+		commonHandler.JSONResponse(w, stats, http.StatusOK)
+	}
+}
@@ -0,0 +1,105 @@
+package newsletter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+// SetEmailTemplateRequest defines the expected request body for overriding an email template.
+type SetEmailTemplateRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+// EmailTemplatesResponse defines the structure for the email template list response.
+type EmailTemplatesResponse struct {
+	Data []service.EmailTemplateView `json:"data"`
+}
+
+// ListEmailTemplatesHandler lists the newsletter's email templates, customized or default.
+// GET /api/newsletters/{newsletterID}/email-templates
+func ListEmailTemplatesHandler(svc service.EmailTemplateServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		templates, err := svc.ListEmailTemplates(r.Context(), editorAuthID, newsletterID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "email template listing")
+			return
+		}
+
+		commonHandler.JSONResponse(w, EmailTemplatesResponse{Data: templates}, http.StatusOK)
+	}
+}
+
+// SetEmailTemplateHandler overrides one of the newsletter's email templates.
+// PUT /api/newsletters/{newsletterID}/email-templates/{templateName}
+func SetEmailTemplateHandler(svc service.EmailTemplateServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		templateName := chi.URLParam(r, "templateName")
+		if newsletterID == "" || templateName == "" {
+			commonHandler.JSONError(w, "Newsletter ID and template name are required in path", http.StatusBadRequest)
+			return
+		}
+
+		var req SetEmailTemplateRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		template, err := svc.SetEmailTemplate(r.Context(), editorAuthID, newsletterID, templateName, req.Body)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "email template update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, template, http.StatusOK)
+	}
+}
+
+// ResetEmailTemplateHandler removes a newsletter's override so the default template is used again.
+// DELETE /api/newsletters/{newsletterID}/email-templates/{templateName}
+func ResetEmailTemplateHandler(svc service.EmailTemplateServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		templateName := chi.URLParam(r, "templateName")
+		if newsletterID == "" || templateName == "" {
+			commonHandler.JSONError(w, "Newsletter ID and template name are required in path", http.StatusBadRequest)
+			return
+		}
+
+		if err := svc.ResetEmailTemplate(r.Context(), editorAuthID, newsletterID, templateName); err != nil {
+			commonHandler.JSONErrorSecure(w, err, "email template reset")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
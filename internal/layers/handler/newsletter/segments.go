@@ -0,0 +1,230 @@
+package newsletter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// SegmentRequest defines the expected request body for creating or replacing a segment.
+// The rules are checked against the newsletter's subscriber fields by the service.
+type SegmentRequest struct {
+	Name        string               `json:"name" validate:"required,max=100"`
+	Description string               `json:"description" validate:"max=500"`
+	Match       string               `json:"match" validate:"omitempty,oneof=all any"`
+	Rules       []SegmentRuleRequest `json:"rules" validate:"required,min=1,max=20,dive"`
+}
+
+// SegmentRuleRequest describes one rule of a segment.
+type SegmentRuleRequest struct {
+	Field    string `json:"field" validate:"required,oneof=tag attribute subscribed_at engagement"`
+	Operator string `json:"operator" validate:"required"`
+	Key      string `json:"key" validate:"max=40"`
+	Value    string `json:"value" validate:"max=500"`
+	Days     int    `json:"days" validate:"min=0,max=3650"`
+}
+
+// toModel converts the request to a segment for the service.
+func (req SegmentRequest) toModel() models.Segment {
+	rules := make([]models.SegmentRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, models.SegmentRule{
+			Field:    models.SegmentRuleField(rule.Field),
+			Operator: models.SegmentOperator(rule.Operator),
+			Key:      rule.Key,
+			Value:    rule.Value,
+			Days:     rule.Days,
+		})
+	}
+	return models.Segment{
+		Name:        req.Name,
+		Description: req.Description,
+		Match:       models.SegmentMatch(req.Match),
+		Rules:       rules,
+	}
+}
+
+// SegmentsResponse defines the structure for the segment list response.
+type SegmentsResponse struct {
+	Data []models.Segment `json:"data"`
+}
+
+// SegmentCountResponse defines the structure for the segment count response.
+type SegmentCountResponse struct {
+	SegmentID   string `json:"segment_id"`
+	Subscribers int    `json:"subscribers"`
+}
+
+// ListSegmentsHandler lists the newsletter's saved segments.
+// GET /api/newsletters/{newsletterID}/segments
+func ListSegmentsHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		segments, err := svc.ListSegments(r.Context(), editorAuthID, newsletterID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment listing")
+			return
+		}
+
+		commonHandler.JSONResponse(w, SegmentsResponse{Data: segments}, http.StatusOK)
+	}
+}
+
+// CreateSegmentHandler saves a new segment of the newsletter.
+// POST /api/newsletters/{newsletterID}/segments
+func CreateSegmentHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		var req SegmentRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		segment, err := svc.CreateSegment(r.Context(), editorAuthID, newsletterID, req.toModel())
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment creation")
+			return
+		}
+
+		commonHandler.JSONResponse(w, segment, http.StatusCreated)
+	}
+}
+
+// GetSegmentHandler returns one of the newsletter's segments.
+// GET /api/newsletters/{newsletterID}/segments/{segmentID}
+func GetSegmentHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		segmentID := chi.URLParam(r, "segmentID")
+		if newsletterID == "" || segmentID == "" {
+			commonHandler.JSONError(w, "Newsletter ID and segment ID are required in path", http.StatusBadRequest)
+			return
+		}
+
+		segment, err := svc.GetSegment(r.Context(), editorAuthID, newsletterID, segmentID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment retrieval")
+			return
+		}
+
+		commonHandler.JSONResponse(w, segment, http.StatusOK)
+	}
+}
+
+// UpdateSegmentHandler replaces the name, description and rules of one of the newsletter's segments.
+// PUT /api/newsletters/{newsletterID}/segments/{segmentID}
+func UpdateSegmentHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		segmentID := chi.URLParam(r, "segmentID")
+		if newsletterID == "" || segmentID == "" {
+			commonHandler.JSONError(w, "Newsletter ID and segment ID are required in path", http.StatusBadRequest)
+			return
+		}
+
+		var req SegmentRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		segment, err := svc.UpdateSegment(r.Context(), editorAuthID, newsletterID, segmentID, req.toModel())
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, segment, http.StatusOK)
+	}
+}
+
+// DeleteSegmentHandler removes one of the newsletter's segments. Posts already published to it are not affected.
+// DELETE /api/newsletters/{newsletterID}/segments/{segmentID}
+func DeleteSegmentHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		segmentID := chi.URLParam(r, "segmentID")
+		if newsletterID == "" || segmentID == "" {
+			commonHandler.JSONError(w, "Newsletter ID and segment ID are required in path", http.StatusBadRequest)
+			return
+		}
+
+		if err := svc.DeleteSegment(r.Context(), editorAuthID, newsletterID, segmentID); err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment deletion")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// CountSegmentSubscribersHandler returns how many active subscribers currently match a segment.
+// GET /api/newsletters/{newsletterID}/segments/{segmentID}/count
+func CountSegmentSubscribersHandler(svc service.SegmentServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterID := chi.URLParam(r, "newsletterID")
+		segmentID := chi.URLParam(r, "segmentID")
+		if newsletterID == "" || segmentID == "" {
+			commonHandler.JSONError(w, "Newsletter ID and segment ID are required in path", http.StatusBadRequest)
+			return
+		}
+
+		count, err := svc.CountSegmentSubscribers(r.Context(), editorAuthID, newsletterID, segmentID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "segment count")
+			return
+		}
+
+		commonHandler.JSONResponse(w, SegmentCountResponse{SegmentID: segmentID, Subscribers: count}, http.StatusOK)
+	}
+}
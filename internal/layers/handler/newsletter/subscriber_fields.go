@@ -0,0 +1,65 @@
+package newsletter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// UpdateSubscriberFieldsRequest defines the expected request body for replacing a newsletter's subscriber fields.
+type UpdateSubscriberFieldsRequest struct {
+	Fields []SubscriberFieldRequest `json:"fields" validate:"max=30,dive"`
+}
+
+// SubscriberFieldRequest describes one custom subscriber attribute.
+type SubscriberFieldRequest struct {
+	Key      string `json:"key" validate:"required,max=40"`
+	Label    string `json:"label" validate:"max=100"`
+	Type     string `json:"type" validate:"required,oneof=text number boolean date"`
+	Required bool   `json:"required"`
+}
+
+// UpdateSubscriberFieldsHandler replaces the custom attributes the subscribers of a newsletter can carry.
+// PUT /api/newsletters/{newsletterID}/subscriber-fields
+func UpdateSubscriberFieldsHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		newsletterID := chi.URLParam(r, "newsletterID")
+		if newsletterID == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		editorAuthID := middleware.GetEditorIDFromContext(r.Context())
+		if editorAuthID == "" {
+			commonHandler.JSONError(w, "Unauthorized: editor ID not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		var req UpdateSubscriberFieldsRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		fields := make(models.SubscriberFields, 0, len(req.Fields))
+		for _, field := range req.Fields {
+			fields = append(fields, models.SubscriberField{
+				Key:      field.Key,
+				Label:    field.Label,
+				Type:     models.SubscriberFieldType(field.Type),
+				Required: field.Required,
+			})
+		}
+		updatedNewsletter, err := svc.UpdateNewsletterSubscriberFields(r.Context(), editorAuthID, newsletterID, fields)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "newsletter subscriber fields update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, updatedNewsletter, http.StatusOK)
+	}
+}
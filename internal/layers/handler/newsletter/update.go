@@ -8,6 +8,7 @@ import (
 	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
 )
 
 // UpdateNewsletterRequest defines the expected request body for updating a newsletter.
@@ -15,6 +16,7 @@ import (
 type UpdateNewsletterRequest struct {
 	Name        *string `json:"name" validate:"omitempty,min=2,max=100"`
 	Description *string `json:"description" validate:"omitempty,max=500"`
+	OptInMode   *string `json:"opt_in_mode" validate:"omitempty,oneof=single double"`
 }
 
 // UpdateHandler handles partial updates to a newsletter.
@@ -40,13 +42,19 @@ func UpdateHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
 		}
 
 		// Ensure at least one field is provided for update
-		if req.Name == nil && req.Description == nil {
-			commonHandler.JSONError(w, "At least one field (name or description) must be provided for update", http.StatusBadRequest)
+		if req.Name == nil && req.Description == nil && req.OptInMode == nil {
+			commonHandler.JSONError(w, "At least one field (name, description or opt_in_mode) must be provided for update", http.StatusBadRequest)
 			return
 		}
 
-		// The service UpdateNewsletter expects editorAuthID (e.g. FirebaseUID), newsletterID, and pointers for name/description.
-		updatedNewsletter, err := svc.UpdateNewsletter(r.Context(), editorAuthID, newsletterID, req.Name, req.Description)
+		var optInMode *models.OptInMode
+		if req.OptInMode != nil {
+			mode := models.OptInMode(*req.OptInMode)
+			optInMode = &mode
+		}
+
+		// The service UpdateNewsletter expects editorAuthID (e.g. FirebaseUID), newsletterID, and pointers for the provided fields.
+		updatedNewsletter, err := svc.UpdateNewsletter(r.Context(), editorAuthID, newsletterID, req.Name, req.Description, optInMode)
 		if err != nil {
 			commonHandler.JSONErrorSecure(w, err, "newsletter update")
 			return
@@ -0,0 +1,37 @@
+package post_handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+// ArchivePostHandler handles requests to archive a post.
+// POST /api/posts/{postID}/archive
+func ArchivePostHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		post, err := svc.ArchivePost(r.Context(), editorID, postIDStr)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "post archive")
+			return
+		}
+
+		commonHandler.JSONResponse(w, post, http.StatusOK)
+	}
+}
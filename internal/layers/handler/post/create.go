@@ -2,6 +2,7 @@ package post_handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -11,8 +12,9 @@ import (
 )
 
 type CreatePostRequest struct {
-	Title   string `json:"title" validate:"required,min=3,max=150"`
-	Content string `json:"content" validate:"required,min=10"`
+	Title       string     `json:"title" validate:"required,min=3,max=150"`
+	Content     string     `json:"content" validate:"required,min=10"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // Optional RFC 3339 time to publish the post automatically
 }
 
 func CreatePostHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
@@ -34,7 +36,7 @@ func CreatePostHandler(svc service.NewsletterServiceInterface) http.HandlerFunc
 			return // Validation failed, response already sent
 		}
 
-		post, err := svc.CreatePost(r.Context(), editorID, newsletterIDStr, req.Title, req.Content)
+		post, err := svc.CreatePost(r.Context(), editorID, newsletterIDStr, req.Title, req.Content, req.ScheduledAt)
 		if err != nil {
 			commonHandler.JSONErrorSecure(w, err, "post creation")
 			return
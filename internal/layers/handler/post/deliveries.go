@@ -0,0 +1,115 @@
+package post_handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+const (
+	DefaultDeliveryLimit  = 50
+	MaxDeliveryLimit      = 500
+	DefaultDeliveryOffset = 0
+)
+
+type PaginatedDeliveriesResponse struct {
+	Data   []models.Delivery `json:"data"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// ListDeliveriesHandler lists the per-recipient deliveries of a post, optionally filtered by status.
+// GET /api/posts/{postID}/deliveries?status=permanently_failed&limit=50&offset=0
+func ListDeliveriesHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		status := models.DeliveryStatus(r.URL.Query().Get("status"))
+		if status != "" && !status.IsValid() {
+			commonHandler.JSONError(w, "Invalid status parameter", http.StatusBadRequest)
+			return
+		}
+
+		limitStr := r.URL.Query().Get("limit")
+		limit := DefaultDeliveryLimit
+		if limitStr != "" {
+			parsedLimit, err := strconv.Atoi(limitStr)
+			if err != nil || parsedLimit <= 0 {
+				commonHandler.JSONError(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			limit = parsedLimit
+		}
+		if limit > MaxDeliveryLimit {
+			limit = MaxDeliveryLimit
+		}
+
+		offsetStr := r.URL.Query().Get("offset")
+		offset := DefaultDeliveryOffset
+		if offsetStr != "" {
+			parsedOffset, err := strconv.Atoi(offsetStr)
+			if err != nil || parsedOffset < 0 {
+				commonHandler.JSONError(w, "Invalid offset parameter", http.StatusBadRequest)
+				return
+			}
+			offset = parsedOffset
+		}
+
+		deliveries, total, err := publishingService.ListDeliveries(r.Context(), editorID, postIDStr, status, limit, offset)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "delivery list")
+			return
+		}
+
+		response := PaginatedDeliveriesResponse{
+			Data:   deliveries,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}
+		commonHandler.JSONResponse(w, response, http.StatusOK)
+	}
+}
+
+// DeliverySummaryHandler returns delivery counts per status for a post.
+// GET /api/posts/{postID}/deliveries/summary
+func DeliverySummaryHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		summary, err := publishingService.GetDeliverySummary(r.Context(), editorID, postIDStr)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "delivery summary")
+			return
+		}
+
+		commonHandler.JSONResponse(w, summary, http.StatusOK)
+	}
+}
@@ -68,7 +68,13 @@ func ListPostsByNewsletterHandler(svc service.NewsletterServiceInterface) http.H
 			offset = parsedOffset
 		}
 
-		posts, total, err := svc.ListPostsByNewsletterID(r.Context(), editorID, newsletterIDStr, limit, offset)
+		status := models.PostStatus(r.URL.Query().Get("status"))
+		if status != "" && !status.IsValid() {
+			commonHandler.JSONError(w, "Invalid status parameter", http.StatusBadRequest)
+			return
+		}
+
+		posts, total, err := svc.ListPostsByNewsletterID(r.Context(), editorID, newsletterIDStr, status, limit, offset)
 		if err != nil {
 			commonHandler.JSONErrorSecure(w, err, "post list")
 			return
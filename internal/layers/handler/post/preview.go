@@ -0,0 +1,54 @@
+package post_handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+// PreviewPostHandler returns the rendered email body of a post so it can be shown in an iframe.
+// The response is the raw HTML or text document rather than JSON.
+// GET /api/posts/{postID}/preview?format=html|text
+func PreviewPostHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = service.PreviewFormatHTML
+		}
+		if format != service.PreviewFormatHTML && format != service.PreviewFormatText {
+			commonHandler.JSONError(w, "Invalid format parameter, expected html or text", http.StatusBadRequest)
+			return
+		}
+
+		rendered, err := publishingService.PreviewPost(r.Context(), postIDStr, editorID, format)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "post preview")
+			return
+		}
+
+		contentType := "text/html; charset=utf-8"
+		if format == service.PreviewFormatText {
+			contentType = "text/plain; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(rendered))
+	}
+}
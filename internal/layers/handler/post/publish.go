@@ -11,7 +11,14 @@ import (
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
 )
 
+// PublishPostRequest defines the optional audience of a publish.
+// When SegmentID is empty the post goes to every active subscriber of its newsletter.
+type PublishPostRequest struct {
+	SegmentID string `json:"segment_id" validate:"omitempty,uuid"`
+}
+
 // PublishPostHandler handles requests to publish a post.
+// Deliveries are queued and sent in the background, so it responds with 202 Accepted.
 // POST /api/posts/{postID}/publish
 func PublishPostHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -29,9 +36,15 @@ func PublishPostHandler(publishingService service.PublishingServiceInterface) ht
 			return
 		}
 
+		// The body is optional: an empty request publishes to every active subscriber.
+		var req PublishPostRequest
+		if r.ContentLength != 0 && !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
 		// The publishingService.PublishPostToSubscribers will handle ownership checks internally.
 		// It needs the editorID (Firebase UID) for that.
-		err := publishingService.PublishPostToSubscribers(ctx, postIDStr, editorID)
+		err := publishingService.PublishPostToSubscribers(ctx, postIDStr, editorID, req.SegmentID)
 		if err != nil {
 			if errors.Is(err, service.ErrPostAlreadyPublished) {
 				commonHandler.JSONError(w, err.Error(), http.StatusConflict)
@@ -43,6 +56,6 @@ func PublishPostHandler(publishingService service.PublishingServiceInterface) ht
 			return
 		}
 
-		commonHandler.JSONResponse(w, map[string]string{"message": "Post published successfully and is being sent to subscribers."}, http.StatusOK)
+		commonHandler.JSONResponse(w, map[string]string{"message": "Post published successfully and is being sent to subscribers."}, http.StatusAccepted)
 	}
 }
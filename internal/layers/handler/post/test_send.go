@@ -0,0 +1,54 @@
+package post_handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+// TestSendPostRequest defines the optional recipients of a test send.
+// When Recipients is empty the test copy goes to the authenticated editor.
+type TestSendPostRequest struct {
+	Recipients []string `json:"recipients" validate:"omitempty,max=5,dive,email"`
+}
+
+// TestSendPostHandler sends a test copy of a post without publishing it.
+// POST /api/posts/{postID}/test-send
+func TestSendPostHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		editorID := middleware.GetEditorIDFromContext(ctx)
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		// The body is optional: an empty request sends the test copy to the editor.
+		var req TestSendPostRequest
+		if r.ContentLength != 0 && !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		sentTo, err := publishingService.SendTestPost(ctx, postIDStr, editorID, req.Recipients)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "post test send")
+			return
+		}
+
+		commonHandler.JSONResponse(w, map[string]interface{}{
+			"message":    "Test copy sent.",
+			"recipients": sentTo,
+		}, http.StatusOK)
+	}
+}
@@ -0,0 +1,46 @@
+package post_handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+type UnpublishPostResponse struct {
+	Post                *models.Post `json:"post"`
+	CancelledDeliveries int          `json:"cancelled_deliveries"`
+}
+
+// UnpublishPostHandler handles requests to recall a published post back to draft.
+// Deliveries that have not been sent yet are cancelled; emails already sent cannot be taken back.
+// POST /api/posts/{postID}/unpublish
+func UnpublishPostHandler(publishingService service.PublishingServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		editorID := middleware.GetEditorIDFromContext(ctx)
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		postIDStr := chi.URLParam(r, "postID")
+		if postIDStr == "" {
+			commonHandler.JSONError(w, "Post ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		post, cancelled, err := publishingService.RecallPost(ctx, postIDStr, editorID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "post unpublish")
+			return
+		}
+
+		commonHandler.JSONResponse(w, UnpublishPostResponse{Post: post, CancelledDeliveries: cancelled}, http.StatusOK)
+	}
+}
@@ -2,6 +2,7 @@ package post_handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -13,8 +14,9 @@ import (
 // UpdatePostRequest defines the expected request body for updating a post.
 // Using pointers to distinguish between a field not provided and a field provided with an empty value.
 type UpdatePostRequest struct {
-	Title   *string `json:"title" validate:"omitempty,min=3,max=150"`
-	Content *string `json:"content" validate:"omitempty,min=10"`
+	Title       *string    `json:"title" validate:"omitempty,min=3,max=150"`
+	Content     *string    `json:"content" validate:"omitempty,min=10"`
+	ScheduledAt *time.Time `json:"scheduled_at"` // Reschedules the post; cancel via DELETE /posts/{postID}/schedule
 }
 
 func UpdatePostHandler(svc service.NewsletterServiceInterface) http.HandlerFunc {
@@ -37,13 +39,13 @@ func UpdatePostHandler(svc service.NewsletterServiceInterface) http.HandlerFunc
 		}
 
 		// Ensure at least one field is provided for update
-		if req.Title == nil && req.Content == nil {
-			commonHandler.JSONError(w, "At least one field (title or content) must be provided for update", http.StatusBadRequest)
+		if req.Title == nil && req.Content == nil && req.ScheduledAt == nil {
+			commonHandler.JSONError(w, "At least one field (title, content or scheduled_at) must be provided for update", http.StatusBadRequest)
 			return
 		}
 
-		// The UpdatePost service method expects editorID, postID, and pointers for title and content.
-		updatedPost, err := svc.UpdatePost(r.Context(), editorID, postIDStr, req.Title, req.Content)
+		// The UpdatePost service method expects editorID, postID, and pointers for the fields to change.
+		updatedPost, err := svc.UpdatePost(r.Context(), editorID, postIDStr, req.Title, req.Content, req.ScheduledAt)
 		if err != nil {
 			commonHandler.JSONErrorSecure(w, err, "post update")
 			return
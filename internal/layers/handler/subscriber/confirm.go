@@ -0,0 +1,96 @@
+package subscriber
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+)
+
+// confirmPage is shown for the confirmation link in double opt-in emails. Like the unsubscribe page,
+// confirming takes a POST from its form, so link scanners cannot confirm a subscription on their own.
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Confirm subscription</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 480px; margin: 40px auto; text-align: center;">
+{{if .Done}}<p>Your subscription is confirmed. Thank you!</p>{{else}}<p>Please confirm that you want to receive this newsletter.</p>
+<form method="post" action="{{.Action}}">
+<button type="submit">Confirm subscription</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type confirmPageData struct {
+	Action string
+	Done   bool
+}
+
+// ConfirmPageHandler shows a page that asks the subscriber to confirm their subscription.
+// It does not change the subscription, as links in emails are opened by scanners and prefetchers too.
+// GET /api/subscriptions/confirm?token={token}
+func ConfirmPageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			commonHandler.JSONError(w, "token query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		action := r.URL.Path + "?token=" + url.QueryEscape(token)
+		writeConfirmPage(w, confirmPageData{Action: action})
+	}
+}
+
+// ConfirmSubscriptionHandler activates a pending double opt-in subscription using the token of its confirmation link.
+// The token is taken from the query string or a form body.
+// POST /api/subscriptions/confirm?token={token}
+func ConfirmSubscriptionHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			commonHandler.JSONError(w, "Invalid form body", http.StatusBadRequest)
+			return
+		}
+
+		token := r.Form.Get("token")
+		if token == "" {
+			commonHandler.JSONError(w, "token query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		subscriberModel, err := subscriberService.ConfirmSubscription(r.Context(), token)
+		if err != nil {
+			statusCode := apperrors.ErrorToHTTPStatus(err)
+			// Provide a more generic message for token-related errors to avoid information leakage.
+			message := err.Error()
+			if statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound {
+				message = "Invalid or expired confirmation token."
+			}
+			commonHandler.JSONError(w, message, statusCode)
+			return
+		}
+
+		// The confirmation page is submitted by a browser, which should get a page rather than JSON.
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			writeConfirmPage(w, confirmPageData{Done: true})
+			return
+		}
+		commonHandler.JSONResponse(w, SubscribeResponse{
+			SubscriberID: subscriberModel.ID,
+			Email:        subscriberModel.Email,
+			NewsletterID: subscriberModel.NewsletterID,
+			Status:       subscriberModel.Status,
+		}, http.StatusOK)
+	}
+}
+
+func writeConfirmPage(w http.ResponseWriter, data confirmPageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	confirmPage.Execute(w, data)
+}
@@ -0,0 +1,56 @@
+package subscriber
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// maxImportBodySize limits the request body of an import, which holds at most service.MaxSubscriberImportSize entries.
+const maxImportBodySize = 4 << 20
+
+// ImportSubscribersRequest defines the expected JSON request body for importing subscribers.
+// Entries are validated one by one, so a bad entry is reported without rejecting the rest.
+type ImportSubscribersRequest struct {
+	Subscribers []models.SubscriberImport `json:"subscribers" validate:"required,min=1,max=1000"`
+}
+
+// ImportSubscribersHandler handles requests for an editor to import existing subscribers into their newsletter.
+// POST /api/newsletters/{newsletterID}/subscribers/import
+// Protected endpoint: Requires editor authentication.
+func ImportSubscribersHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		editorID := middleware.GetEditorIDFromContext(ctx)
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterIDStr := chi.URLParam(r, "newsletterID")
+		if newsletterIDStr == "" {
+			commonHandler.JSONError(w, "Newsletter ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportBodySize)
+		var req ImportSubscribersRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		result, err := subscriberService.ImportSubscribers(ctx, editorID, newsletterIDStr, req.Subscribers)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "subscriber import")
+			return
+		}
+
+		commonHandler.JSONResponse(w, result, http.StatusOK)
+	}
+}
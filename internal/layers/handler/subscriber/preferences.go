@@ -0,0 +1,172 @@
+package subscriber
+
+import (
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// preferencesPage is the preference center linked from the footer of every email. It lists the newsletters
+// the subscriber's address receives and submits changes as a form to UpdatePreferencesHandler.
+var preferencesPage = template.Must(template.New("preferences").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Subscription preferences</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 480px; margin: 40px auto;">
+<h1 style="font-size: 1.4em;">Subscription preferences</h1>
+{{if .Saved}}<p>Your preferences have been saved.</p>{{end}}
+{{with .Preferences}}<p>Newsletters sent to {{.Email}}</p>
+<form method="post" action="{{$.Action}}">
+<p><label>Your name <input type="text" name="name" value="{{.Name}}" maxlength="{{$.MaxNameLength}}"></label></p>
+{{range .Subscriptions}}<fieldset style="margin-bottom: 16px;">
+<legend>{{.NewsletterName}}</legend>
+<input type="hidden" name="newsletter_id" value="{{.NewsletterID}}">
+<p><label>Delivery <select name="frequency_{{.NewsletterID}}">
+<option value="immediate"{{if eq .Frequency "immediate"}} selected{{end}}>Every issue</option>
+<option value="daily"{{if eq .Frequency "daily"}} selected{{end}}>Daily digest</option>
+<option value="weekly"{{if eq .Frequency "weekly"}} selected{{end}}>Weekly digest</option>
+</select></label></p>
+<p><label>Pause <select name="pause_weeks_{{.NewsletterID}}">
+<option value="">{{if .PausedUntil}}Paused until {{.PausedUntil.Format "2 January 2006"}}{{else}}Not paused{{end}}</option>
+{{if .PausedUntil}}<option value="0">Resume now</option>
+{{end}}{{range $.PauseOptions}}<option value="{{.}}">For {{.}} week{{if ne . 1}}s{{end}}</option>
+{{end}}</select></label></p>
+<p><label><input type="checkbox" name="unsubscribe_{{.NewsletterID}}" value="1"> Unsubscribe</label></p>
+</fieldset>
+{{else}}<p>You are not subscribed to any newsletter.</p>
+{{end}}<button type="submit">Save preferences</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type preferencesPageData struct {
+	Action        string
+	Saved         bool
+	Preferences   *models.SubscriberPreferences
+	PauseOptions  []int
+	MaxNameLength int
+}
+
+// preferencesPauseOptions are the pauses offered on the preferences page, in weeks.
+var preferencesPauseOptions = []int{1, 2, 4, 8, 12}
+
+// PreferencesHandler returns the preferences of a subscriber: a page for browsers, JSON otherwise.
+// GET /api/subscriptions/preferences?token={token}
+func PreferencesHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			commonHandler.JSONError(w, "token query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		preferences, err := subscriberService.GetPreferences(r.Context(), token)
+		if err != nil {
+			writePreferencesError(w, err)
+			return
+		}
+		respondPreferences(w, r, token, preferences, false)
+	}
+}
+
+// UpdatePreferencesHandler changes the preferences of a subscriber. It takes a JSON body or the form of the
+// preferences page, where each listed newsletter_id has frequency_{id}, pause_weeks_{id} and unsubscribe_{id} fields.
+// POST /api/subscriptions/preferences?token={token}
+func UpdatePreferencesHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			commonHandler.JSONError(w, "token query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		var update models.SubscriberPreferencesUpdate
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+			if !commonHandler.ValidateAndRespond(w, r, &update) {
+				return // Validation failed, response already sent
+			}
+		} else {
+			if err := r.ParseForm(); err != nil {
+				commonHandler.JSONError(w, "Invalid form body", http.StatusBadRequest)
+				return
+			}
+			parsed, err := preferencesUpdateFromForm(r.PostForm)
+			if err != nil {
+				commonHandler.JSONError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			update = parsed
+		}
+
+		preferences, err := subscriberService.UpdatePreferences(r.Context(), token, update)
+		if err != nil {
+			writePreferencesError(w, err)
+			return
+		}
+		respondPreferences(w, r, token, preferences, true)
+	}
+}
+
+// preferencesUpdateFromForm reads an update from the form of the preferences page. Empty fields are left unchanged.
+func preferencesUpdateFromForm(form url.Values) (models.SubscriberPreferencesUpdate, error) {
+	var update models.SubscriberPreferencesUpdate
+	if names, ok := form["name"]; ok && len(names) > 0 {
+		name := names[0]
+		update.Name = &name
+	}
+	for _, newsletterID := range form["newsletter_id"] {
+		change := models.SubscriptionPreferenceUpdate{
+			NewsletterID: newsletterID,
+			Unsubscribe:  form.Get("unsubscribe_"+newsletterID) != "",
+		}
+		if frequency := form.Get("frequency_" + newsletterID); frequency != "" {
+			deliveryFrequency := models.DeliveryFrequency(frequency)
+			change.Frequency = &deliveryFrequency
+		}
+		if pause := form.Get("pause_weeks_" + newsletterID); pause != "" {
+			weeks, err := strconv.Atoi(pause)
+			if err != nil {
+				return update, errors.New("pause_weeks must be a number of weeks")
+			}
+			change.PauseWeeks = &weeks
+		}
+		update.Subscriptions = append(update.Subscriptions, change)
+	}
+	return update, nil
+}
+
+// respondPreferences writes the preferences page for browsers and JSON for everyone else.
+func respondPreferences(w http.ResponseWriter, r *http.Request, token string, preferences *models.SubscriberPreferences, saved bool) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		commonHandler.JSONResponse(w, preferences, http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	preferencesPage.Execute(w, preferencesPageData{
+		Action:        r.URL.Path + "?token=" + url.QueryEscape(token),
+		Saved:         saved,
+		Preferences:   preferences,
+		PauseOptions:  preferencesPauseOptions,
+		MaxNameLength: service.MaxSubscriberNameLength,
+	})
+}
+
+func writePreferencesError(w http.ResponseWriter, err error) {
+	// Provide a more generic message for token-related errors to avoid information leakage.
+	if errors.Is(err, apperrors.ErrTokenInvalid) || errors.Is(err, apperrors.ErrTokenExpired) {
+		commonHandler.JSONError(w, "Invalid or expired token.", http.StatusBadRequest)
+		return
+	}
+	commonHandler.JSONErrorSecure(w, err, "subscriber preferences")
+}
@@ -12,8 +12,11 @@ import (
 
 // SubscribeRequest defines the expected JSON request body for subscribing.
 // Note: NewsletterID is taken from the path, not the body.
+// Attributes are checked against the newsletter's subscriber fields.
 type SubscribeRequest struct {
-	Email string `json:"email" validate:"required,email"`
+	Email      string            `json:"email" validate:"required,email"`
+	Name       string            `json:"name" validate:"max=100"`
+	Attributes map[string]string `json:"attributes"`
 }
 
 // SubscribeResponse defines the JSON response for a successful subscription.
@@ -40,7 +43,8 @@ func SubscribeHandler(subscriberService service.SubscriberServiceInterface) http
 		}
 
 		// Call service with Email from request body and NewsletterID from path
-		subscriberModel, err := subscriberService.SubscribeToNewsletter(r.Context(), req.Email, newsletterIDStr)
+		profile := models.SubscriberProfile{Name: req.Name, Attributes: req.Attributes}
+		subscriberModel, err := subscriberService.SubscribeToNewsletter(r.Context(), req.Email, newsletterIDStr, profile)
 		if err != nil {
 			commonHandler.JSONErrorSecure(w, err, "subscriber subscribe")
 			return
@@ -0,0 +1,52 @@
+package subscriber
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+)
+
+// UpdateSubscriberTagsRequest defines the expected JSON request body for replacing a subscriber's tags.
+// An empty list removes every tag.
+type UpdateSubscriberTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=50"`
+}
+
+// UpdateSubscriberTagsHandler handles requests for an editor to replace the tags of one of their subscribers.
+// PUT /api/newsletters/{newsletterID}/subscribers/{subscriberID}/tags
+// Protected endpoint: Requires editor authentication.
+func UpdateSubscriberTagsHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		editorID := middleware.GetEditorIDFromContext(ctx)
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		newsletterIDStr := chi.URLParam(r, "newsletterID")
+		subscriberIDStr := chi.URLParam(r, "subscriberID")
+		if newsletterIDStr == "" || subscriberIDStr == "" {
+			commonHandler.JSONError(w, "Newsletter ID and subscriber ID are required in path", http.StatusBadRequest)
+			return
+		}
+
+		var req UpdateSubscriberTagsRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		subscriber, err := subscriberService.UpdateSubscriberTags(ctx, editorID, newsletterIDStr, subscriberIDStr, req.Tags)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "subscriber tags update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, subscriber, http.StatusOK)
+	}
+}
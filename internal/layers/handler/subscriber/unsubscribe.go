@@ -1,14 +1,44 @@
 package subscriber
 
 import (
+	"html/template"
 	"net/http"
+	"net/url"
+	"strings"
 
 	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
 	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
 )
 
-// UnsubscribeHandler handles requests to unsubscribe from a newsletter using a token.
+// The form field and value mail clients POST for an RFC 8058 one-click unsubscribe.
+const (
+	oneClickUnsubscribeField = "List-Unsubscribe"
+	oneClickUnsubscribeValue = "One-Click"
+)
+
+// unsubscribePage is shown for the unsubscribe link in emails. Unsubscribing takes a POST from its
+// form, so link scanners and prefetchers that follow the link cannot unsubscribe anyone.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 480px; margin: 40px auto; text-align: center;">
+{{if .Done}}<p>You have been unsubscribed.</p>{{else}}<p>Do you want to stop receiving this newsletter?</p>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit">Unsubscribe</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Action string
+	Done   bool
+}
+
+// UnsubscribeHandler shows a page that asks the subscriber to confirm unsubscribing.
+// It does not change the subscription, as links in emails are opened by scanners and prefetchers too.
 // GET /api/subscriptions/unsubscribe?token={token}
 func UnsubscribeHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -18,6 +48,32 @@ func UnsubscribeHandler(subscriberService service.SubscriberServiceInterface) ht
 			return
 		}
 
+		action := r.URL.Path + "?token=" + url.QueryEscape(token)
+		writeUnsubscribePage(w, unsubscribePageData{Action: action})
+	}
+}
+
+// OneClickUnsubscribeHandler unsubscribes using the token of the List-Unsubscribe URL.
+// Mail clients call it with the RFC 8058 form body "List-Unsubscribe=One-Click", which the
+// confirmation page of UnsubscribeHandler submits as well.
+// POST /api/subscriptions/unsubscribe?token={token}
+func OneClickUnsubscribeHandler(subscriberService service.SubscriberServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			commonHandler.JSONError(w, "Invalid form body", http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get(oneClickUnsubscribeField) != oneClickUnsubscribeValue {
+			commonHandler.JSONError(w, "List-Unsubscribe=One-Click form body is required", http.StatusBadRequest)
+			return
+		}
+
+		token := r.Form.Get("token")
+		if token == "" {
+			commonHandler.JSONError(w, "token query parameter is required", http.StatusBadRequest)
+			return
+		}
+
 		err := subscriberService.UnsubscribeByToken(r.Context(), token)
 		if err != nil {
 			statusCode := apperrors.ErrorToHTTPStatus(err)
@@ -30,6 +86,18 @@ func UnsubscribeHandler(subscriberService service.SubscriberServiceInterface) ht
 			return
 		}
 
+		// The confirmation page is submitted by a browser, which should get a page rather than JSON.
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			writeUnsubscribePage(w, unsubscribePageData{Done: true})
+			return
+		}
 		commonHandler.JSONResponse(w, map[string]string{"message": "Successfully unsubscribed."}, http.StatusOK)
 	}
 }
+
+func writeUnsubscribePage(w http.ResponseWriter, data unsubscribePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	unsubscribePage.Execute(w, data)
+}
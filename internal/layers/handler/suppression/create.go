@@ -0,0 +1,43 @@
+package suppression
+
+import (
+	"net/http"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// CreateSuppressionRequest defines the expected request body for suppressing an address or a domain.
+type CreateSuppressionRequest struct {
+	Value  string `json:"value" validate:"required,max=320"` // An email address, or a domain such as example.com or @example.com
+	Reason string `json:"reason" validate:"max=500"`
+	Source string `json:"source" validate:"omitempty,oneof=manual import bounce complaint"`
+}
+
+// CreateHandler adds an address or a whole domain to the editor's suppression list, or to the global list for the global scope.
+// POST /api/suppressions
+// POST /api/admin/suppressions
+func CreateHandler(svc service.SuppressionServiceInterface, scope models.SuppressionScope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req CreateSuppressionRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+
+		suppression, err := svc.CreateSuppression(r.Context(), editorID, scope, req.Value, req.Reason, models.SuppressionSource(req.Source))
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "suppression creation")
+			return
+		}
+
+		commonHandler.JSONResponse(w, suppression, http.StatusCreated)
+	}
+}
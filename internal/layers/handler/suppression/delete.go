@@ -0,0 +1,38 @@
+package suppression
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// DeleteHandler removes a suppression, so the address or domain can subscribe and receive issues again.
+// DELETE /api/suppressions/{suppressionID}
+// DELETE /api/admin/suppressions/{suppressionID}
+func DeleteHandler(svc service.SuppressionServiceInterface, scope models.SuppressionScope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		suppressionID := chi.URLParam(r, "suppressionID")
+		if suppressionID == "" {
+			commonHandler.JSONError(w, "Suppression ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		if err := svc.DeleteSuppression(r.Context(), editorID, scope, suppressionID); err != nil {
+			commonHandler.JSONErrorSecure(w, err, "suppression deletion")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,39 @@
+package suppression
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// GetHandler returns one entry of the editor's suppression list, or of the global list for the global scope.
+// GET /api/suppressions/{suppressionID}
+// GET /api/admin/suppressions/{suppressionID}
+func GetHandler(svc service.SuppressionServiceInterface, scope models.SuppressionScope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		suppressionID := chi.URLParam(r, "suppressionID")
+		if suppressionID == "" {
+			commonHandler.JSONError(w, "Suppression ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		suppression, err := svc.GetSuppression(r.Context(), editorID, scope, suppressionID)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "suppression retrieval")
+			return
+		}
+
+		commonHandler.JSONResponse(w, suppression, http.StatusOK)
+	}
+}
@@ -0,0 +1,74 @@
+package suppression
+
+import (
+	"net/http"
+	"strconv"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+const (
+	DefaultSuppressionLimit  = 50
+	MaxSuppressionLimit      = 500
+	DefaultSuppressionOffset = 0
+)
+
+// PaginatedSuppressionsResponse defines the structure for paginated suppression lists.
+type PaginatedSuppressionsResponse struct {
+	Data   []models.Suppression `json:"data"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// ListHandler lists the entries of the editor's suppression list, or of the global list for the global scope.
+// GET /api/suppressions
+// GET /api/admin/suppressions
+func ListHandler(svc service.SuppressionServiceInterface, scope models.SuppressionScope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		limit := DefaultSuppressionLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsedLimit, err := strconv.Atoi(limitStr)
+			if err != nil || parsedLimit <= 0 {
+				commonHandler.JSONError(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			limit = parsedLimit
+		}
+		if limit > MaxSuppressionLimit {
+			limit = MaxSuppressionLimit
+		}
+
+		offset := DefaultSuppressionOffset
+		if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+			parsedOffset, err := strconv.Atoi(offsetStr)
+			if err != nil || parsedOffset < 0 {
+				commonHandler.JSONError(w, "Invalid offset parameter", http.StatusBadRequest)
+				return
+			}
+			offset = parsedOffset
+		}
+
+		suppressions, total, err := svc.ListSuppressions(r.Context(), editorID, scope, limit, offset)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "suppression list")
+			return
+		}
+
+		commonHandler.JSONResponse(w, PaginatedSuppressionsResponse{
+			Data:   suppressions,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}, http.StatusOK)
+	}
+}
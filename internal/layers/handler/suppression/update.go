@@ -0,0 +1,61 @@
+package suppression
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// UpdateSuppressionRequest defines the expected request body for updating a suppression.
+// The suppressed value cannot change; delete the entry and create a new one instead.
+type UpdateSuppressionRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+	Source *string `json:"source,omitempty" validate:"omitempty,oneof=manual import bounce complaint"`
+}
+
+// UpdateHandler changes the reason or source of a suppression.
+// PATCH /api/suppressions/{suppressionID}
+// PATCH /api/admin/suppressions/{suppressionID}
+func UpdateHandler(svc service.SuppressionServiceInterface, scope models.SuppressionScope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editorID := middleware.GetEditorIDFromContext(r.Context())
+		if editorID == "" {
+			commonHandler.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		suppressionID := chi.URLParam(r, "suppressionID")
+		if suppressionID == "" {
+			commonHandler.JSONError(w, "Suppression ID is required in path", http.StatusBadRequest)
+			return
+		}
+
+		var req UpdateSuppressionRequest
+		if !commonHandler.ValidateAndRespond(w, r, &req) {
+			return // Validation failed, response already sent
+		}
+		if req.Reason == nil && req.Source == nil {
+			commonHandler.JSONError(w, "At least one field (reason or source) must be provided", http.StatusBadRequest)
+			return
+		}
+
+		var source *models.SuppressionSource
+		if req.Source != nil {
+			value := models.SuppressionSource(*req.Source)
+			source = &value
+		}
+
+		suppression, err := svc.UpdateSuppression(r.Context(), editorID, scope, suppressionID, req.Reason, source)
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "suppression update")
+			return
+		}
+
+		commonHandler.JSONResponse(w, suppression, http.StatusOK)
+	}
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// maxBounceMessageSize limits the size of a posted bounce message; DSNs quote at most the original message.
+const maxBounceMessageSize = 10 << 20
+
+// rawMessageFields are the form fields inbound email services post the raw message in,
+// e.g. "email" for SendGrid Inbound Parse in raw mode and "body-mime" for Mailgun routes.
+var rawMessageFields = []string{"email", "body-mime"}
+
+// BounceResponse is returned for a processed bounce message.
+type BounceResponse struct {
+	service.BounceResult
+	Ignored bool `json:"ignored"` // The message was not a delivery status notification
+}
+
+// InboundBounceHandler accepts a delivery status notification forwarded by an inbound email service.
+// The raw message is posted either as the request body (message/rfc822 or text/plain) or as a form field.
+// Callers authenticate with the shared secret as a bearer token or the secret query parameter.
+// Messages that are not delivery status notifications are acknowledged and ignored, so the sender does not retry them.
+// POST /api/webhooks/bounces
+func InboundBounceHandler(bounceService service.BounceServiceInterface, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validWebhookSecret(r, secret) {
+			commonHandler.JSONError(w, "Invalid webhook secret", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBounceMessageSize)
+		message, err := readRawMessage(r)
+		if err != nil {
+			commonHandler.JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := bounceService.ProcessBounceMessage(r.Context(), bytes.NewReader(message))
+		if errors.Is(err, service.ErrNotDeliveryStatusReport) {
+			commonHandler.JSONResponse(w, BounceResponse{
+				BounceResult: service.BounceResult{Bounces: []models.Bounce{}, Suppressed: []string{}},
+				Ignored:      true,
+			}, http.StatusOK)
+			return
+		}
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "process bounce")
+			return
+		}
+
+		commonHandler.JSONResponse(w, BounceResponse{BounceResult: *result}, http.StatusOK)
+	}
+}
+
+// validWebhookSecret compares the bearer token or secret query parameter with the configured secret in constant time.
+func validWebhookSecret(r *http.Request, secret string) bool {
+	provided := r.URL.Query().Get("secret")
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		provided = token
+	}
+	return secret != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) == 1
+}
+
+// readRawMessage returns the raw message from a form field or, for any other content type, the request body.
+func readRawMessage(r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseMultipartForm(maxBounceMessageSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return nil, errors.New("invalid form body")
+		}
+		for _, field := range rawMessageFields {
+			if value := r.FormValue(field); value != "" {
+				return []byte(value), nil
+			}
+		}
+		return nil, errors.New("form body has no raw message field, expected email or body-mime")
+	}
+
+	message, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+	if len(bytes.TrimSpace(message)) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+	return message, nil
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	commonHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+)
+
+// ComplaintResponse is returned for a processed abuse report.
+type ComplaintResponse struct {
+	service.ComplaintResult
+	Ignored bool `json:"ignored"` // The message was not an abuse feedback report
+}
+
+// InboundComplaintHandler accepts an abuse feedback report (ARF) forwarded from a mailbox provider's feedback loop.
+// The message is posted and authenticated like a bounce, see InboundBounceHandler.
+// Messages that are not abuse feedback reports are acknowledged and ignored, so the sender does not retry them.
+// POST /api/webhooks/complaints
+func InboundComplaintHandler(complaintService service.ComplaintServiceInterface, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validWebhookSecret(r, secret) {
+			commonHandler.JSONError(w, "Invalid webhook secret", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBounceMessageSize)
+		message, err := readRawMessage(r)
+		if err != nil {
+			commonHandler.JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := complaintService.ProcessComplaintMessage(r.Context(), bytes.NewReader(message))
+		if errors.Is(err, service.ErrNotFeedbackReport) {
+			commonHandler.JSONResponse(w, ComplaintResponse{Ignored: true}, http.StatusOK)
+			return
+		}
+		if err != nil {
+			commonHandler.JSONErrorSecure(w, err, "process complaint")
+			return
+		}
+
+		commonHandler.JSONResponse(w, ComplaintResponse{ComplaintResult: *result}, http.StatusOK)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/bounce/create.sql
+var createBounceQuery string
+
+//go:embed queries/bounce/count_hard_by_email.sql
+var countHardBouncesByEmailQuery string
+
+// BounceRepository defines the interface for the log of bounced deliveries.
+type BounceRepository interface {
+	// CreateBounce records a bounce and fills in its ID and ReceivedAt.
+	CreateBounce(ctx context.Context, bounce *models.Bounce) error
+	// CountHardBounces returns the number of hard bounces recorded for the address.
+	CountHardBounces(ctx context.Context, email string) (int, error)
+}
+
+type postgresBounceRepository struct {
+	db *sql.DB
+}
+
+// NewBounceRepository creates a new instance of postgresBounceRepository.
+func NewBounceRepository(db *sql.DB) BounceRepository {
+	return &postgresBounceRepository{db: db}
+}
+
+func (r *postgresBounceRepository) CreateBounce(ctx context.Context, bounce *models.Bounce) error {
+	err := r.db.QueryRowContext(ctx, createBounceQuery,
+		bounce.Email, string(bounce.Type), bounce.Action, bounce.Status, bounce.DiagnosticCode, bounce.ReportingMTA,
+	).Scan(&bounce.ID, &bounce.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("bounce repo: CreateBounce: scan: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresBounceRepository) CountHardBounces(ctx context.Context, email string) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, countHardBouncesByEmailQuery, email).Scan(&count); err != nil {
+		return 0, fmt.Errorf("bounce repo: CountHardBounces: query: %w", err)
+	}
+	return count, nil
+}
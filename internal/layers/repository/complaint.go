@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/complaint/create.sql
+var createComplaintQuery string
+
+//go:embed queries/complaint/count_by_newsletter_id_since.sql
+var countComplaintsByNewsletterIDSinceQuery string
+
+// ComplaintRepository defines the interface for the log of spam complaints.
+type ComplaintRepository interface {
+	// CreateComplaint records a complaint and fills in its ID and ReceivedAt.
+	CreateComplaint(ctx context.Context, complaint *models.Complaint) error
+	// CountComplaintsSince returns the number of complaints about the newsletter received at or after since.
+	CountComplaintsSince(ctx context.Context, newsletterID string, since time.Time) (int, error)
+}
+
+type postgresComplaintRepository struct {
+	db *sql.DB
+}
+
+// NewComplaintRepository creates a new instance of postgresComplaintRepository.
+func NewComplaintRepository(db *sql.DB) ComplaintRepository {
+	return &postgresComplaintRepository{db: db}
+}
+
+func (r *postgresComplaintRepository) CreateComplaint(ctx context.Context, complaint *models.Complaint) error {
+	err := r.db.QueryRowContext(ctx, createComplaintQuery,
+		complaint.NewsletterID, complaint.SubscriberID, complaint.Email, complaint.FeedbackType, complaint.UserAgent,
+	).Scan(&complaint.ID, &complaint.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("complaint repo: CreateComplaint: scan: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresComplaintRepository) CountComplaintsSince(ctx context.Context, newsletterID string, since time.Time) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, countComplaintsByNewsletterIDSinceQuery, newsletterID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("complaint repo: CountComplaintsSince: query: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/delivery/enqueue.sql
+var enqueueDeliveriesQuery string
+
+//go:embed queries/delivery/claim.sql
+var claimDeliveriesQuery string
+
+//go:embed queries/delivery/mark_sent.sql
+var markDeliverySentQuery string
+
+//go:embed queries/delivery/mark_failed.sql
+var markDeliveryFailedQuery string
+
+//go:embed queries/delivery/release.sql
+var releaseDeliveryQuery string
+
+//go:embed queries/delivery/defer.sql
+var deferDeliveryQuery string
+
+//go:embed queries/delivery/cancel.sql
+var cancelDeliveryQuery string
+
+//go:embed queries/delivery/count_sent_since.sql
+var countDeliveriesSentSinceQuery string
+
+//go:embed queries/delivery/count_sent_by_newsletter_id_since.sql
+var countNewsletterDeliveriesSentSinceQuery string
+
+//go:embed queries/delivery/count_by_subscriber_since.sql
+var countSubscriberDeliveriesSinceQuery string
+
+//go:embed queries/delivery/list_by_post_id.sql
+var listDeliveriesByPostIDQuery string
+
+//go:embed queries/delivery/count_by_post_id.sql
+var countDeliveriesByPostIDQuery string
+
+//go:embed queries/delivery/count_by_status.sql
+var countDeliveriesByStatusQuery string
+
+//go:embed queries/delivery/cancel_pending_by_post_id.sql
+var cancelPendingDeliveriesQuery string
+
+// dbDelivery is an internal struct used for scanning database rows.
+// It maps directly to the 'deliveries' table schema.
+type dbDelivery struct {
+	ID                   string            `db:"id"`
+	PostID               string            `db:"post_id"`
+	SubscriberID         string            `db:"subscriber_id"`
+	Email                string            `db:"email"`
+	SubscriberName       string            `db:"subscriber_name"`
+	SubscriberAttributes map[string]string `db:"subscriber_attributes"` // JSONB
+	UnsubscribeToken     string            `db:"unsubscribe_token"`
+	Status               string            `db:"status"`
+	Attempts             int               `db:"attempts"`
+	LastError            sql.NullString    `db:"last_error"`
+	NextAttemptAt        time.Time         `db:"next_attempt_at"`
+	LockedAt             *time.Time        `db:"locked_at"`
+	SentAt               *time.Time        `db:"sent_at"`
+	CreatedAt            time.Time         `db:"created_at"`
+	UpdatedAt            time.Time         `db:"updated_at"`
+}
+
+// scanDest returns the scan destinations in the column order used by delivery queries.
+func (dbD *dbDelivery) scanDest() []interface{} {
+	return []interface{}{
+		&dbD.ID, &dbD.PostID, &dbD.SubscriberID, &dbD.Email, &dbD.SubscriberName, jsonColumn{&dbD.SubscriberAttributes},
+		&dbD.UnsubscribeToken, &dbD.Status, &dbD.Attempts,
+		&dbD.LastError, &dbD.NextAttemptAt, &dbD.LockedAt, &dbD.SentAt, &dbD.CreatedAt, &dbD.UpdatedAt,
+	}
+}
+
+// toModel converts a dbDelivery to a models.Delivery domain object.
+func (dbD *dbDelivery) toModel() models.Delivery {
+	return models.Delivery{
+		ID:                   dbD.ID,
+		PostID:               dbD.PostID,
+		SubscriberID:         dbD.SubscriberID,
+		Email:                dbD.Email,
+		SubscriberName:       dbD.SubscriberName,
+		SubscriberAttributes: dbD.SubscriberAttributes,
+		UnsubscribeToken:     dbD.UnsubscribeToken,
+		Status:               models.DeliveryStatus(dbD.Status),
+		Attempts:             dbD.Attempts,
+		LastError:            dbD.LastError.String,
+		NextAttemptAt:        dbD.NextAttemptAt,
+		LockedAt:             dbD.LockedAt,
+		SentAt:               dbD.SentAt,
+		CreatedAt:            dbD.CreatedAt,
+		UpdatedAt:            dbD.UpdatedAt,
+	}
+}
+
+// DeliveryRepository defines the interface for the per-recipient delivery log, which doubles as the send queue.
+type DeliveryRepository interface {
+	// EnqueueDeliveries inserts one queued delivery per entry. Deliveries that already exist for the
+	// same post and subscriber are skipped, so enqueueing is safe to retry; cancelled ones are queued again.
+	EnqueueDeliveries(ctx context.Context, postID string, deliveries []models.Delivery) (int, error)
+	// ClaimDeliveries atomically marks up to limit due deliveries as processing and returns them.
+	// Deliveries stuck in processing for longer than lockTimeout (e.g. after a crash) are reclaimed.
+	ClaimDeliveries(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.Delivery, error)
+	MarkDeliverySent(ctx context.Context, deliveryID string) error
+	// MarkDeliveryFailed records a failed attempt. A nil retryAt marks the delivery as permanently failed.
+	MarkDeliveryFailed(ctx context.Context, deliveryID string, lastError string, retryAt *time.Time) error
+	// ReleaseDelivery returns a claimed delivery to the queue without counting it as an attempt.
+	ReleaseDelivery(ctx context.Context, deliveryID string) error
+	// DeferDelivery returns a claimed delivery to the queue without counting it as an attempt
+	// and keeps it from being claimed again before until.
+	DeferDelivery(ctx context.Context, deliveryID string, until time.Time) error
+	// CancelDelivery cancels a claimed delivery that must no longer be sent and records the reason as its last error.
+	CancelDelivery(ctx context.Context, deliveryID string, reason string) error
+	// CountDeliveriesSentSince returns the number of messages sent at or after since, counting each digest once.
+	CountDeliveriesSentSince(ctx context.Context, since time.Time) (int, error)
+	// CountNewsletterDeliveriesSentSince returns the number of messages of the newsletter sent at or after since,
+	// counting each digest once.
+	CountNewsletterDeliveriesSentSince(ctx context.Context, newsletterID string, since time.Time) (int, error)
+	// CountSubscriberDeliveriesSince returns, per subscriber ID, how many of the newsletter's posts reached the final
+	// status at or after since, sent on their own or in a digest. Subscribers without any are left out.
+	CountSubscriberDeliveriesSince(ctx context.Context, newsletterID string, status models.DeliveryStatus, since time.Time) (map[string]int, error)
+	// ListDeliveriesByPostID returns a page of deliveries for a post, optionally filtered by status (empty for all).
+	ListDeliveriesByPostID(ctx context.Context, postID string, status models.DeliveryStatus, limit int, offset int) ([]models.Delivery, int, error)
+	// CountDeliveriesByStatus returns the number of deliveries for a post grouped by status.
+	CountDeliveriesByStatus(ctx context.Context, postID string) (map[models.DeliveryStatus]int, error)
+	// CancelPendingDeliveries cancels the deliveries of a post that have not been sent yet and returns how many.
+	// Deliveries a worker is sending right now are not affected.
+	CancelPendingDeliveries(ctx context.Context, postID string) (int, error)
+}
+
+type postgresDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewDeliveryRepository creates a new instance of postgresDeliveryRepository.
+func NewDeliveryRepository(db *sql.DB) DeliveryRepository {
+	return &postgresDeliveryRepository{db: db}
+}
+
+func (r *postgresDeliveryRepository) EnqueueDeliveries(ctx context.Context, postID string, deliveries []models.Delivery) (int, error) {
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	subscriberIDs := make([]string, 0, len(deliveries))
+	emails := make([]string, 0, len(deliveries))
+	tokens := make([]string, 0, len(deliveries))
+	names := make([]string, 0, len(deliveries))
+	attributes := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		subscriberIDs = append(subscriberIDs, d.SubscriberID)
+		emails = append(emails, d.Email)
+		tokens = append(tokens, d.UnsubscribeToken)
+		names = append(names, d.SubscriberName)
+		attributesJSON := []byte("{}")
+		if len(d.SubscriberAttributes) > 0 {
+			encoded, err := json.Marshal(d.SubscriberAttributes)
+			if err != nil {
+				return 0, fmt.Errorf("delivery repo: EnqueueDeliveries: marshal attributes: %w", err)
+			}
+			attributesJSON = encoded
+		}
+		attributes = append(attributes, string(attributesJSON))
+	}
+
+	result, err := r.db.ExecContext(ctx, enqueueDeliveriesQuery,
+		postID, pq.Array(subscriberIDs), pq.Array(emails), pq.Array(tokens), pq.Array(names), pq.Array(attributes),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delivery repo: EnqueueDeliveries: exec: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delivery repo: EnqueueDeliveries: checking rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+func (r *postgresDeliveryRepository) ClaimDeliveries(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, claimDeliveriesQuery, limit, lockTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("delivery repo: ClaimDeliveries: query: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.Delivery
+	for rows.Next() {
+		var d dbDelivery
+		if errScan := rows.Scan(d.scanDest()...); errScan != nil {
+			return nil, fmt.Errorf("delivery repo: ClaimDeliveries: scan: %w", errScan)
+		}
+		deliveries = append(deliveries, d.toModel())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("delivery repo: ClaimDeliveries: rows error: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *postgresDeliveryRepository) MarkDeliverySent(ctx context.Context, deliveryID string) error {
+	return r.execForDelivery(ctx, "MarkDeliverySent", markDeliverySentQuery, deliveryID)
+}
+
+func (r *postgresDeliveryRepository) MarkDeliveryFailed(ctx context.Context, deliveryID string, lastError string, retryAt *time.Time) error {
+	status := models.DeliveryStatusFailed
+	if retryAt == nil {
+		status = models.DeliveryStatusPermanentlyFailed
+	}
+	return r.execForDelivery(ctx, "MarkDeliveryFailed", markDeliveryFailedQuery, deliveryID, string(status), lastError, retryAt)
+}
+
+func (r *postgresDeliveryRepository) ReleaseDelivery(ctx context.Context, deliveryID string) error {
+	_, err := r.db.ExecContext(ctx, releaseDeliveryQuery, deliveryID)
+	if err != nil {
+		return fmt.Errorf("delivery repo: ReleaseDelivery: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDeliveryRepository) DeferDelivery(ctx context.Context, deliveryID string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, deferDeliveryQuery, deliveryID, until)
+	if err != nil {
+		return fmt.Errorf("delivery repo: DeferDelivery: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDeliveryRepository) CancelDelivery(ctx context.Context, deliveryID string, reason string) error {
+	_, err := r.db.ExecContext(ctx, cancelDeliveryQuery, deliveryID, reason)
+	if err != nil {
+		return fmt.Errorf("delivery repo: CancelDelivery: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDeliveryRepository) CountDeliveriesSentSince(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, countDeliveriesSentSinceQuery, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("delivery repo: CountDeliveriesSentSince: query: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresDeliveryRepository) CountNewsletterDeliveriesSentSince(ctx context.Context, newsletterID string, since time.Time) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, countNewsletterDeliveriesSentSinceQuery, newsletterID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("delivery repo: CountNewsletterDeliveriesSentSince: query: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresDeliveryRepository) CountSubscriberDeliveriesSince(ctx context.Context, newsletterID string, status models.DeliveryStatus, since time.Time) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, countSubscriberDeliveriesSinceQuery, newsletterID, string(status), since)
+	if err != nil {
+		return nil, fmt.Errorf("delivery repo: CountSubscriberDeliveriesSince: query: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var subscriberID string
+		var count int
+		if errScan := rows.Scan(&subscriberID, &count); errScan != nil {
+			return nil, fmt.Errorf("delivery repo: CountSubscriberDeliveriesSince: scan: %w", errScan)
+		}
+		counts[subscriberID] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("delivery repo: CountSubscriberDeliveriesSince: rows error: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *postgresDeliveryRepository) ListDeliveriesByPostID(ctx context.Context, postID string, status models.DeliveryStatus, limit int, offset int) ([]models.Delivery, int, error) {
+	rows, err := r.db.QueryContext(ctx, listDeliveriesByPostIDQuery, postID, string(status), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("delivery repo: ListDeliveriesByPostID: query: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.Delivery, 0)
+	for rows.Next() {
+		var d dbDelivery
+		if errScan := rows.Scan(d.scanDest()...); errScan != nil {
+			return nil, 0, fmt.Errorf("delivery repo: ListDeliveriesByPostID: scan: %w", errScan)
+		}
+		deliveries = append(deliveries, d.toModel())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("delivery repo: ListDeliveriesByPostID: rows error: %w", err)
+	}
+
+	var totalCount int
+	err = r.db.QueryRowContext(ctx, countDeliveriesByPostIDQuery, postID, string(status)).Scan(&totalCount)
+	if err != nil {
+		return deliveries, 0, fmt.Errorf("delivery repo: ListDeliveriesByPostID: count query: %w", err)
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (r *postgresDeliveryRepository) CountDeliveriesByStatus(ctx context.Context, postID string) (map[models.DeliveryStatus]int, error) {
+	rows, err := r.db.QueryContext(ctx, countDeliveriesByStatusQuery, postID)
+	if err != nil {
+		return nil, fmt.Errorf("delivery repo: CountDeliveriesByStatus: query: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.DeliveryStatus]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if errScan := rows.Scan(&status, &count); errScan != nil {
+			return nil, fmt.Errorf("delivery repo: CountDeliveriesByStatus: scan: %w", errScan)
+		}
+		counts[models.DeliveryStatus(status)] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("delivery repo: CountDeliveriesByStatus: rows error: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *postgresDeliveryRepository) CancelPendingDeliveries(ctx context.Context, postID string) (int, error) {
+	result, err := r.db.ExecContext(ctx, cancelPendingDeliveriesQuery, postID)
+	if err != nil {
+		return 0, fmt.Errorf("delivery repo: CancelPendingDeliveries: exec: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delivery repo: CancelPendingDeliveries: checking rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// execForDelivery runs a single-row update against a delivery and reports a missing row as not found.
+func (r *postgresDeliveryRepository) execForDelivery(ctx context.Context, operation, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delivery repo: %s: exec: %w", operation, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delivery repo: %s: checking rows affected: %w", operation, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("delivery repo: %s: %w", operation, apperrors.ErrDeliveryNotFound)
+	}
+	return nil
+}
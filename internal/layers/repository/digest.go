@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/digest/enqueue.sql
+var enqueueDigestItemsQuery string
+
+//go:embed queries/digest/claim.sql
+var claimDigestItemsQuery string
+
+//go:embed queries/digest/mark_sent.sql
+var markDigestItemsSentQuery string
+
+//go:embed queries/digest/mark_failed.sql
+var markDigestItemsFailedQuery string
+
+//go:embed queries/digest/release.sql
+var releaseDigestItemsQuery string
+
+//go:embed queries/digest/defer.sql
+var deferDigestItemsQuery string
+
+//go:embed queries/digest/cancel.sql
+var cancelDigestItemsQuery string
+
+//go:embed queries/digest/cancel_pending_by_post_id.sql
+var cancelPendingDigestItemsQuery string
+
+// dbDigestItem is an internal struct used for scanning database rows.
+// It maps directly to the 'digest_items' table schema.
+type dbDigestItem struct {
+	ID               string         `db:"id"`
+	PostID           string         `db:"post_id"`
+	SubscriberID     string         `db:"subscriber_id"`
+	Email            string         `db:"email"`
+	UnsubscribeToken string         `db:"unsubscribe_token"`
+	Status           string         `db:"status"`
+	Attempts         int            `db:"attempts"`
+	LastError        sql.NullString `db:"last_error"`
+	DueAt            time.Time      `db:"due_at"`
+	SentAt           *time.Time     `db:"sent_at"`
+	CreatedAt        time.Time      `db:"created_at"`
+}
+
+// scanDest returns the scan destinations in the column order used by digest queries.
+func (dbI *dbDigestItem) scanDest() []interface{} {
+	return []interface{}{
+		&dbI.ID, &dbI.PostID, &dbI.SubscriberID, &dbI.Email, &dbI.UnsubscribeToken, &dbI.Status, &dbI.Attempts,
+		&dbI.LastError, &dbI.DueAt, &dbI.SentAt, &dbI.CreatedAt,
+	}
+}
+
+// toModel converts a dbDigestItem to a models.DigestItem domain object.
+func (dbI *dbDigestItem) toModel() models.DigestItem {
+	return models.DigestItem{
+		ID:               dbI.ID,
+		PostID:           dbI.PostID,
+		SubscriberID:     dbI.SubscriberID,
+		Email:            dbI.Email,
+		UnsubscribeToken: dbI.UnsubscribeToken,
+		Status:           models.DeliveryStatus(dbI.Status),
+		Attempts:         dbI.Attempts,
+		LastError:        dbI.LastError.String,
+		DueAt:            dbI.DueAt,
+		SentAt:           dbI.SentAt,
+		CreatedAt:        dbI.CreatedAt,
+	}
+}
+
+// DigestRepository defines the interface for the queue of posts waiting to be sent in daily and weekly digests.
+// Every item of a subscriber is sent in the same email, so items are claimed and updated per digest.
+type DigestRepository interface {
+	// EnqueueDigestItems adds the post to the next digest of each subscriber. Items that already exist for the
+	// same post and subscriber are skipped, so enqueueing is safe to retry; cancelled ones are queued again.
+	EnqueueDigestItems(ctx context.Context, postID string, items []models.DigestItem) (int, error)
+	// ClaimDigestItems atomically marks every due item of up to limit subscribers as processing and returns them.
+	// Items stuck in processing for longer than lockTimeout (e.g. after a crash) are reclaimed.
+	ClaimDigestItems(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.DigestItem, error)
+	MarkDigestItemsSent(ctx context.Context, itemIDs []string) error
+	// MarkDigestItemsFailed records a failed attempt. A nil retryAt marks the items as permanently failed.
+	MarkDigestItemsFailed(ctx context.Context, itemIDs []string, lastError string, retryAt *time.Time) error
+	// ReleaseDigestItems returns claimed items to the queue without counting an attempt.
+	ReleaseDigestItems(ctx context.Context, itemIDs []string) error
+	// DeferDigestItems returns claimed items to the queue without counting an attempt
+	// and keeps them from being claimed again before until.
+	DeferDigestItems(ctx context.Context, itemIDs []string, until time.Time) error
+	// CancelDigestItems cancels claimed items that must not be sent, e.g. because the subscriber left.
+	CancelDigestItems(ctx context.Context, itemIDs []string) error
+	// CancelPendingDigestItems cancels the items of a post that have not been sent yet and returns how many.
+	CancelPendingDigestItems(ctx context.Context, postID string) (int, error)
+}
+
+type postgresDigestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository creates a new instance of postgresDigestRepository.
+func NewDigestRepository(db *sql.DB) DigestRepository {
+	return &postgresDigestRepository{db: db}
+}
+
+func (r *postgresDigestRepository) EnqueueDigestItems(ctx context.Context, postID string, items []models.DigestItem) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	subscriberIDs := make([]string, 0, len(items))
+	emails := make([]string, 0, len(items))
+	tokens := make([]string, 0, len(items))
+	dueAts := make([]string, 0, len(items))
+	for _, item := range items {
+		subscriberIDs = append(subscriberIDs, item.SubscriberID)
+		emails = append(emails, item.Email)
+		tokens = append(tokens, item.UnsubscribeToken)
+		dueAts = append(dueAts, item.DueAt.UTC().Format(time.RFC3339Nano))
+	}
+
+	result, err := r.db.ExecContext(ctx, enqueueDigestItemsQuery,
+		postID, pq.Array(subscriberIDs), pq.Array(emails), pq.Array(tokens), pq.Array(dueAts),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("digest repo: EnqueueDigestItems: exec: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("digest repo: EnqueueDigestItems: checking rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+func (r *postgresDigestRepository) ClaimDigestItems(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.DigestItem, error) {
+	rows, err := r.db.QueryContext(ctx, claimDigestItemsQuery, limit, lockTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("digest repo: ClaimDigestItems: query: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.DigestItem
+	for rows.Next() {
+		var item dbDigestItem
+		if errScan := rows.Scan(item.scanDest()...); errScan != nil {
+			return nil, fmt.Errorf("digest repo: ClaimDigestItems: scan: %w", errScan)
+		}
+		items = append(items, item.toModel())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("digest repo: ClaimDigestItems: rows error: %w", err)
+	}
+	return items, nil
+}
+
+func (r *postgresDigestRepository) MarkDigestItemsSent(ctx context.Context, itemIDs []string) error {
+	if _, err := r.db.ExecContext(ctx, markDigestItemsSentQuery, pq.Array(itemIDs)); err != nil {
+		return fmt.Errorf("digest repo: MarkDigestItemsSent: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDigestRepository) MarkDigestItemsFailed(ctx context.Context, itemIDs []string, lastError string, retryAt *time.Time) error {
+	status := models.DeliveryStatusFailed
+	if retryAt == nil {
+		status = models.DeliveryStatusPermanentlyFailed
+	}
+	if _, err := r.db.ExecContext(ctx, markDigestItemsFailedQuery, pq.Array(itemIDs), string(status), lastError, retryAt); err != nil {
+		return fmt.Errorf("digest repo: MarkDigestItemsFailed: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDigestRepository) ReleaseDigestItems(ctx context.Context, itemIDs []string) error {
+	if _, err := r.db.ExecContext(ctx, releaseDigestItemsQuery, pq.Array(itemIDs)); err != nil {
+		return fmt.Errorf("digest repo: ReleaseDigestItems: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDigestRepository) DeferDigestItems(ctx context.Context, itemIDs []string, until time.Time) error {
+	if _, err := r.db.ExecContext(ctx, deferDigestItemsQuery, pq.Array(itemIDs), until); err != nil {
+		return fmt.Errorf("digest repo: DeferDigestItems: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDigestRepository) CancelDigestItems(ctx context.Context, itemIDs []string) error {
+	if _, err := r.db.ExecContext(ctx, cancelDigestItemsQuery, pq.Array(itemIDs)); err != nil {
+		return fmt.Errorf("digest repo: CancelDigestItems: exec: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresDigestRepository) CancelPendingDigestItems(ctx context.Context, postID string) (int, error) {
+	result, err := r.db.ExecContext(ctx, cancelPendingDigestItemsQuery, postID)
+	if err != nil {
+		return 0, fmt.Errorf("digest repo: CancelPendingDigestItems: exec: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("digest repo: CancelPendingDigestItems: checking rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
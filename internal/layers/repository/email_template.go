@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/email_template/get.sql
+var getEmailTemplateQuery string
+
+//go:embed queries/email_template/list_by_newsletter_id.sql
+var listEmailTemplatesByNewsletterIDQuery string
+
+//go:embed queries/email_template/upsert.sql
+var upsertEmailTemplateQuery string
+
+//go:embed queries/email_template/delete.sql
+var deleteEmailTemplateQuery string
+
+// dbEmailTemplate maps directly to the 'newsletter_email_templates' table schema.
+type dbEmailTemplate struct {
+	NewsletterID string    `db:"newsletter_id"`
+	Name         string    `db:"name"`
+	Body         string    `db:"body"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// scanDest returns the scan destinations in the column order used by email template queries.
+func (dbT *dbEmailTemplate) scanDest() []interface{} {
+	return []interface{}{&dbT.NewsletterID, &dbT.Name, &dbT.Body, &dbT.CreatedAt, &dbT.UpdatedAt}
+}
+
+// toModel converts a dbEmailTemplate to a models.EmailTemplate domain object.
+func (dbT *dbEmailTemplate) toModel() models.EmailTemplate {
+	return models.EmailTemplate{
+		NewsletterID: dbT.NewsletterID,
+		Name:         dbT.Name,
+		Body:         dbT.Body,
+		CreatedAt:    dbT.CreatedAt,
+		UpdatedAt:    dbT.UpdatedAt,
+	}
+}
+
+// EmailTemplateRepository stores per-newsletter overrides of the built-in email templates.
+type EmailTemplateRepository interface {
+	// GetEmailTemplate returns the override of the named template, or ErrEmailTemplateNotFound if there is none.
+	GetEmailTemplate(ctx context.Context, newsletterID string, name string) (*models.EmailTemplate, error)
+	ListEmailTemplatesByNewsletterID(ctx context.Context, newsletterID string) ([]models.EmailTemplate, error)
+	// UpsertEmailTemplate creates or replaces the override of the named template.
+	UpsertEmailTemplate(ctx context.Context, newsletterID string, name string, body string) (*models.EmailTemplate, error)
+	DeleteEmailTemplate(ctx context.Context, newsletterID string, name string) error
+}
+
+type postgresEmailTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewEmailTemplateRepository creates a new instance of postgresEmailTemplateRepository.
+func NewEmailTemplateRepository(db *sql.DB) EmailTemplateRepository {
+	return &postgresEmailTemplateRepository{db: db}
+}
+
+func (r *postgresEmailTemplateRepository) GetEmailTemplate(ctx context.Context, newsletterID string, name string) (*models.EmailTemplate, error) {
+	var t dbEmailTemplate
+	err := r.db.QueryRowContext(ctx, getEmailTemplateQuery, newsletterID, name).Scan(t.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("email template repo: GetEmailTemplate: %w", apperrors.ErrEmailTemplateNotFound)
+		}
+		return nil, fmt.Errorf("email template repo: GetEmailTemplate: scan: %w", err)
+	}
+	model := t.toModel()
+	return &model, nil
+}
+
+func (r *postgresEmailTemplateRepository) ListEmailTemplatesByNewsletterID(ctx context.Context, newsletterID string) ([]models.EmailTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, listEmailTemplatesByNewsletterIDQuery, newsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("email template repo: ListEmailTemplatesByNewsletterID: query: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]models.EmailTemplate, 0)
+	for rows.Next() {
+		var t dbEmailTemplate
+		if errScan := rows.Scan(t.scanDest()...); errScan != nil {
+			return nil, fmt.Errorf("email template repo: ListEmailTemplatesByNewsletterID: scan: %w", errScan)
+		}
+		templates = append(templates, t.toModel())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("email template repo: ListEmailTemplatesByNewsletterID: rows error: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *postgresEmailTemplateRepository) UpsertEmailTemplate(ctx context.Context, newsletterID string, name string, body string) (*models.EmailTemplate, error) {
+	var t dbEmailTemplate
+	if err := r.db.QueryRowContext(ctx, upsertEmailTemplateQuery, newsletterID, name, body).Scan(t.scanDest()...); err != nil {
+		return nil, fmt.Errorf("email template repo: UpsertEmailTemplate: scan: %w", err)
+	}
+	model := t.toModel()
+	return &model, nil
+}
+
+func (r *postgresEmailTemplateRepository) DeleteEmailTemplate(ctx context.Context, newsletterID string, name string) error {
+	result, err := r.db.ExecContext(ctx, deleteEmailTemplateQuery, newsletterID, name)
+	if err != nil {
+		return fmt.Errorf("email template repo: DeleteEmailTemplate: exec: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("email template repo: DeleteEmailTemplate: checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("email template repo: DeleteEmailTemplate: %w", apperrors.ErrEmailTemplateNotFound)
+	}
+	return nil
+}
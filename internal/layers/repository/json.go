@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonColumn scans a JSON or JSONB column into the value dest points to. NULL leaves it unchanged.
+type jsonColumn struct {
+	dest interface{}
+}
+
+// Scan implements sql.Scanner.
+func (c jsonColumn) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(value, c.dest)
+	case string:
+		return json.Unmarshal([]byte(value), c.dest)
+	default:
+		return fmt.Errorf("unsupported type %T for a JSON column", src)
+	}
+}
@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	_ "embed" // Required for //go:embed
-	"errors"  // For errors.Is
+	"encoding/json"
+	"errors" // For errors.Is
 	"fmt"
 	"time"
 
@@ -40,14 +41,37 @@ var updateNewsletterQuery string
 //go:embed queries/newsletter/delete.sql
 var deleteNewsletterQuery string
 
+//go:embed queries/newsletter/update_branding.sql
+var updateNewsletterBrandingQuery string
+
+//go:embed queries/newsletter/update_subscriber_fields.sql
+var updateNewsletterSubscriberFieldsQuery string
+
 // dbNewsletter represents the database structure for a newsletter.
 type dbNewsletter struct {
-	ID          string    `db:"id"`
-	EditorID    string    `db:"editor_id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID               string                  `db:"id"`
+	EditorID         string                  `db:"editor_id"`
+	Name             string                  `db:"name"`
+	Description      string                  `db:"description"`
+	LogoURL          string                  `db:"logo_url"`
+	AccentColor      string                  `db:"accent_color"`
+	FooterText       string                  `db:"footer_text"`
+	MailingAddress   string                  `db:"mailing_address"`
+	HeaderHTML       string                  `db:"header_html"`
+	FooterHTML       string                  `db:"footer_html"`
+	OptInMode        string                  `db:"opt_in_mode"`
+	SubscriberFields models.SubscriberFields `db:"subscriber_fields"` // JSONB
+	CreatedAt        time.Time               `db:"created_at"`
+	UpdatedAt        time.Time               `db:"updated_at"`
+}
+
+// scanDest returns the scan destinations in the column order used by newsletter queries.
+func (dbNl *dbNewsletter) scanDest() []interface{} {
+	return []interface{}{
+		&dbNl.ID, &dbNl.EditorID, &dbNl.Name, &dbNl.Description, &dbNl.LogoURL, &dbNl.AccentColor,
+		&dbNl.FooterText, &dbNl.MailingAddress, &dbNl.HeaderHTML, &dbNl.FooterHTML, &dbNl.OptInMode,
+		jsonColumn{&dbNl.SubscriberFields}, &dbNl.CreatedAt, &dbNl.UpdatedAt,
+	}
 }
 
 // toModel converts a dbNewsletter to a models.Newsletter domain object.
@@ -57,8 +81,18 @@ func (dbNl *dbNewsletter) toModel() models.Newsletter {
 		EditorID:    dbNl.EditorID,
 		Name:        dbNl.Name,
 		Description: dbNl.Description,
-		CreatedAt:   dbNl.CreatedAt,
-		UpdatedAt:   dbNl.UpdatedAt,
+		Branding: models.NewsletterBranding{
+			LogoURL:        dbNl.LogoURL,
+			AccentColor:    dbNl.AccentColor,
+			FooterText:     dbNl.FooterText,
+			MailingAddress: dbNl.MailingAddress,
+			HeaderHTML:     dbNl.HeaderHTML,
+			FooterHTML:     dbNl.FooterHTML,
+		},
+		OptInMode:        models.OptInMode(dbNl.OptInMode),
+		SubscriberFields: dbNl.SubscriberFields,
+		CreatedAt:        dbNl.CreatedAt,
+		UpdatedAt:        dbNl.UpdatedAt,
 	}
 }
 
@@ -67,7 +101,12 @@ type NewsletterRepository interface {
 	ListNewslettersByEditorID(ctx context.Context, editorID string, limit int, offset int) ([]models.Newsletter, int, error)
 	CreateNewsletter(ctx context.Context, editorID, name, description string) (*models.Newsletter, error)
 	GetNewsletterByIDAndEditorID(ctx context.Context, newsletterID string, editorID string) (*models.Newsletter, error)
-	UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string) (*models.Newsletter, error)
+	// UpdateNewsletter updates the provided fields; nil fields are left unchanged.
+	UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string, optInMode *models.OptInMode) (*models.Newsletter, error)
+	// UpdateNewsletterBranding replaces the newsletter's branding as a whole.
+	UpdateNewsletterBranding(ctx context.Context, newsletterID string, editorID string, branding models.NewsletterBranding) (*models.Newsletter, error)
+	// UpdateNewsletterSubscriberFields replaces the schema of the custom attributes of the newsletter's subscribers.
+	UpdateNewsletterSubscriberFields(ctx context.Context, newsletterID string, editorID string, fields models.SubscriberFields) (*models.Newsletter, error)
 	DeleteNewsletter(ctx context.Context, newsletterID string, editorID string) error
 	GetNewsletterByNameAndEditorID(ctx context.Context, name string, editorID string) (*models.Newsletter, error)
 	GetNewsletterByID(ctx context.Context, newsletterID string) (*models.Newsletter, error)
@@ -94,7 +133,7 @@ func (r *PostgresNewsletterRepo) ListNewslettersByEditorID(ctx context.Context,
 	var dbNewsletters []dbNewsletter
 	for rows.Next() {
 		var nl dbNewsletter
-		if errScan := rows.Scan(&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt); errScan != nil {
+		if errScan := rows.Scan(nl.scanDest()...); errScan != nil {
 			return nil, 0, fmt.Errorf("newsletter repo: ListNewslettersByEditorID: scan: %w", errScan)
 		}
 		dbNewsletters = append(dbNewsletters, nl)
@@ -121,9 +160,7 @@ func (r *PostgresNewsletterRepo) ListNewslettersByEditorID(ctx context.Context,
 // CreateNewsletter creates a new newsletter.
 func (r *PostgresNewsletterRepo) CreateNewsletter(ctx context.Context, editorID, name, description string) (*models.Newsletter, error) {
 	var nl dbNewsletter
-	err := r.db.QueryRowContext(ctx, createNewsletterQuery, editorID, name, description).Scan(
-		&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, createNewsletterQuery, editorID, name, description).Scan(nl.scanDest()...)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // 23505 is unique_violation
@@ -138,9 +175,7 @@ func (r *PostgresNewsletterRepo) CreateNewsletter(ctx context.Context, editorID,
 // GetNewsletterByIDAndEditorID fetches a newsletter by its ID and verifies editor ownership.
 func (r *PostgresNewsletterRepo) GetNewsletterByIDAndEditorID(ctx context.Context, newsletterID string, editorID string) (*models.Newsletter, error) {
 	var nl dbNewsletter
-	err := r.db.QueryRowContext(ctx, getNewsletterByIDAndEditorIDQuery, newsletterID, editorID).Scan(
-		&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, getNewsletterByIDAndEditorIDQuery, newsletterID, editorID).Scan(nl.scanDest()...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("newsletter repo: GetNewsletterByIDAndEditorID: %w", apperrors.ErrNewsletterNotFound)
@@ -151,13 +186,16 @@ func (r *PostgresNewsletterRepo) GetNewsletterByIDAndEditorID(ctx context.Contex
 	return &model, nil
 }
 
-// UpdateNewsletter updates a newsletter's name and/or description atomically.
+// UpdateNewsletter updates a newsletter's name, description and/or opt-in mode atomically.
 // Uses COALESCE to only update provided fields, eliminating race conditions.
-func (r *PostgresNewsletterRepo) UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string) (*models.Newsletter, error) {
+func (r *PostgresNewsletterRepo) UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string, optInMode *models.OptInMode) (*models.Newsletter, error) {
+	var optInModeArg sql.NullString
+	if optInMode != nil {
+		optInModeArg = sql.NullString{String: string(*optInMode), Valid: true}
+	}
+
 	var nl dbNewsletter
-	err := r.db.QueryRowContext(ctx, updateNewsletterQuery, name, description, newsletterID, editorID).Scan(
-		&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, updateNewsletterQuery, name, description, optInModeArg, newsletterID, editorID).Scan(nl.scanDest()...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("newsletter repo: UpdateNewsletter: %w", apperrors.ErrNewsletterNotFound)
@@ -168,6 +206,45 @@ func (r *PostgresNewsletterRepo) UpdateNewsletter(ctx context.Context, newslette
 	return &model, nil
 }
 
+// UpdateNewsletterBranding replaces the branding of a newsletter, ensuring it belongs to the editor.
+func (r *PostgresNewsletterRepo) UpdateNewsletterBranding(ctx context.Context, newsletterID string, editorID string, branding models.NewsletterBranding) (*models.Newsletter, error) {
+	var nl dbNewsletter
+	err := r.db.QueryRowContext(ctx, updateNewsletterBrandingQuery,
+		branding.LogoURL, branding.AccentColor, branding.FooterText, branding.MailingAddress, branding.HeaderHTML, branding.FooterHTML,
+		newsletterID, editorID,
+	).Scan(nl.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("newsletter repo: UpdateNewsletterBranding: %w", apperrors.ErrNewsletterNotFound)
+		}
+		return nil, fmt.Errorf("newsletter repo: UpdateNewsletterBranding: scan: %w", err)
+	}
+	model := nl.toModel()
+	return &model, nil
+}
+
+// UpdateNewsletterSubscriberFields replaces the subscriber field schema of a newsletter, ensuring it belongs to the editor.
+func (r *PostgresNewsletterRepo) UpdateNewsletterSubscriberFields(ctx context.Context, newsletterID string, editorID string, fields models.SubscriberFields) (*models.Newsletter, error) {
+	if fields == nil {
+		fields = models.SubscriberFields{}
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("newsletter repo: UpdateNewsletterSubscriberFields: marshal: %w", err)
+	}
+
+	var nl dbNewsletter
+	err = r.db.QueryRowContext(ctx, updateNewsletterSubscriberFieldsQuery, string(fieldsJSON), newsletterID, editorID).Scan(nl.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("newsletter repo: UpdateNewsletterSubscriberFields: %w", apperrors.ErrNewsletterNotFound)
+		}
+		return nil, fmt.Errorf("newsletter repo: UpdateNewsletterSubscriberFields: scan: %w", err)
+	}
+	model := nl.toModel()
+	return &model, nil
+}
+
 // DeleteNewsletter removes a newsletter by its ID, ensuring it belongs to the editor.
 func (r *PostgresNewsletterRepo) DeleteNewsletter(ctx context.Context, newsletterID string, editorID string) error {
 	cmdTag, err := r.db.ExecContext(ctx, deleteNewsletterQuery, newsletterID, editorID)
@@ -189,9 +266,7 @@ func (r *PostgresNewsletterRepo) DeleteNewsletter(ctx context.Context, newslette
 // GetNewsletterByNameAndEditorID fetches a newsletter by its name and editor ID.
 func (r *PostgresNewsletterRepo) GetNewsletterByNameAndEditorID(ctx context.Context, name string, editorID string) (*models.Newsletter, error) {
 	var nl dbNewsletter
-	err := r.db.QueryRowContext(ctx, getNewsletterByNameAndEditorIDQuery, name, editorID).Scan(
-		&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, getNewsletterByNameAndEditorIDQuery, name, editorID).Scan(nl.scanDest()...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("newsletter repo: GetNewsletterByNameAndEditorID: %w", apperrors.ErrNewsletterNotFound)
@@ -205,9 +280,7 @@ func (r *PostgresNewsletterRepo) GetNewsletterByNameAndEditorID(ctx context.Cont
 // GetNewsletterByID fetches a newsletter by its ID.
 func (r *PostgresNewsletterRepo) GetNewsletterByID(ctx context.Context, newsletterID string) (*models.Newsletter, error) {
 	var nl dbNewsletter
-	err := r.db.QueryRowContext(ctx, getNewsletterByIDQuery, newsletterID).Scan(
-		&nl.ID, &nl.EditorID, &nl.Name, &nl.Description, &nl.CreatedAt, &nl.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, getNewsletterByIDQuery, newsletterID).Scan(nl.scanDest()...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("newsletter repo: GetNewsletterByID: %w", apperrors.ErrNewsletterNotFound)
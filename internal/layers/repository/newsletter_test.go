@@ -19,20 +19,34 @@ func TestDbNewsletter_ToModel(t *testing.T) {
 		{
 			name: "complete newsletter mapping",
 			dbNewsletter: dbNewsletter{
-				ID:          "newsletter_123",
-				EditorID:    "editor_456",
-				Name:        "Tech Weekly",
-				Description: "A weekly tech newsletter",
-				CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
-				UpdatedAt:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+				ID:             "newsletter_123",
+				EditorID:       "editor_456",
+				Name:           "Tech Weekly",
+				Description:    "A weekly tech newsletter",
+				LogoURL:        "https://example.com/logo.png",
+				AccentColor:    "#1a73e8",
+				FooterText:     "Thanks for reading",
+				MailingAddress: "1 Main Street, Springfield",
+				HeaderHTML:     "<p>Header</p>",
+				FooterHTML:     "<p>Footer</p>",
+				CreatedAt:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:      time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 			},
 			expected: models.Newsletter{
 				ID:          "newsletter_123",
 				EditorID:    "editor_456",
 				Name:        "Tech Weekly",
 				Description: "A weekly tech newsletter",
-				CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
-				UpdatedAt:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+				Branding: models.NewsletterBranding{
+					LogoURL:        "https://example.com/logo.png",
+					AccentColor:    "#1a73e8",
+					FooterText:     "Thanks for reading",
+					MailingAddress: "1 Main Street, Springfield",
+					HeaderHTML:     "<p>Header</p>",
+					FooterHTML:     "<p>Footer</p>",
+				},
+				CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 			},
 		},
 		{
@@ -31,13 +31,42 @@ var countPostsByNewsletterIDQuery string
 //go:embed queries/post/delete.sql
 var deletePostQuery string
 
+//go:embed queries/post/list_due_scheduled.sql
+var listDueScheduledPostsQuery string
+
+//go:embed queries/post/clear_schedule.sql
+var clearPostScheduleQuery string
+
+//go:embed queries/post/mark_as_published.sql
+var markPostAsPublishedQuery string
+
+//go:embed queries/post/publish_scheduled.sql
+var publishScheduledPostQuery string
+
+//go:embed queries/post/restore_schedule.sql
+var restorePostScheduleQuery string
+
+//go:embed queries/post/restore_draft.sql
+var restorePostDraftQuery string
+
+//go:embed queries/post/transition_status.sql
+var transitionPostStatusQuery string
+
+//go:embed queries/post/complete_sending.sql
+var completePostSendingQuery string
+
+//go:embed queries/post/unpublish.sql
+var unpublishPostQuery string
+
 
 
 // PostUpdate defines the fields that can be updated for a post.
 // Only non-nil fields will be updated in the database.
 type PostUpdate struct {
-	Title   *string `json:"title,omitempty"`
-	Content *string `json:"content,omitempty"`
+	Title       *string            `json:"title,omitempty"`
+	Content     *string            `json:"content,omitempty"`
+	ScheduledAt *time.Time         `json:"scheduled_at,omitempty"`
+	Status      *models.PostStatus `json:"status,omitempty"`
 }
 
 // dbPost is an internal struct used for scanning database rows.
@@ -47,11 +76,23 @@ type dbPost struct {
 	NewsletterID string     `db:"newsletter_id"`
 	Title        string     `db:"title"`
 	Content      string     `db:"content"`
+	Status       string     `db:"status"`
 	PublishedAt  *time.Time `db:"published_at"` // Pointer to handle NULL
+	ScheduledAt  *time.Time `db:"scheduled_at"`
+	RecalledAt   *time.Time     `db:"recalled_at"`
+	RecalledBy   sql.NullString `db:"recalled_by"`
 	CreatedAt    time.Time  `db:"created_at"`
 	UpdatedAt    time.Time  `db:"updated_at"`
 }
 
+// scanDest returns the scan destinations in the column order used by post queries.
+func (dbP *dbPost) scanDest() []interface{} {
+	return []interface{}{
+		&dbP.ID, &dbP.NewsletterID, &dbP.Title, &dbP.Content, &dbP.Status, &dbP.PublishedAt, &dbP.ScheduledAt,
+		&dbP.RecalledAt, &dbP.RecalledBy, &dbP.CreatedAt, &dbP.UpdatedAt,
+	}
+}
+
 // toModel converts a dbPost to a models.Post domain object.
 func (dbP *dbPost) toModel() models.Post {
 	return models.Post{
@@ -59,7 +100,11 @@ func (dbP *dbPost) toModel() models.Post {
 		NewsletterID: dbP.NewsletterID,
 		Title:        dbP.Title,
 		Content:      dbP.Content,
+		Status:       models.PostStatus(dbP.Status),
 		PublishedAt:  dbP.PublishedAt,
+		ScheduledAt:  dbP.ScheduledAt,
+		RecalledAt:   dbP.RecalledAt,
+		RecalledBy:   dbP.RecalledBy.String,
 		CreatedAt:    dbP.CreatedAt,
 		UpdatedAt:    dbP.UpdatedAt,
 	}
@@ -71,11 +116,33 @@ func (dbP *dbPost) toModel() models.Post {
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *models.Post) (*models.Post, error)
 	GetPostByID(ctx context.Context, postID string) (*models.Post, error)
-	ListPostsByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Post, int, error)
+	// ListPostsByNewsletterID lists posts of a newsletter, optionally filtered by status (empty for all).
+	ListPostsByNewsletterID(ctx context.Context, newsletterID string, status models.PostStatus, limit int, offset int) ([]models.Post, int, error)
 	UpdatePost(ctx context.Context, postID string, updates PostUpdate) (*models.Post, error)
+	// SetPostPublished moves a draft or scheduled post to sending. It fails with ErrConflict if the post was
+	// published, recalled or archived in the meantime.
 	SetPostPublished(ctx context.Context, postID string, publishedAt time.Time) (*models.Post, error)
-	SetPostUnpublished(ctx context.Context, postID string) (*models.Post, error)
+	// SetScheduledPostPublished moves a post that is still scheduled and due at now to sending. It fails with
+	// ErrConflict if the post was cancelled, rescheduled or published in the meantime.
+	SetScheduledPostPublished(ctx context.Context, postID string, now time.Time) (*models.Post, error)
+	// RestorePostSchedule returns a sending post to scheduled at scheduledAt, undoing SetScheduledPostPublished.
+	RestorePostSchedule(ctx context.Context, postID string, scheduledAt time.Time) (*models.Post, error)
+	// RestorePostDraft returns a sending post to draft, undoing SetPostPublished.
+	RestorePostDraft(ctx context.Context, postID string) (*models.Post, error)
+	// SetPostUnpublished returns a published post to draft and records which editor recalled it and when.
+	// It fails with ErrConflict if the post is no longer sending, sent or failed, e.g. archived meanwhile.
+	SetPostUnpublished(ctx context.Context, postID string, recalledBy string, recalledAt time.Time) (*models.Post, error)
 	DeletePost(ctx context.Context, postID string) error
+	// ClearPostSchedule returns a scheduled post to draft. It fails with ErrConflict if the post is no longer scheduled.
+	ClearPostSchedule(ctx context.Context, postID string) (*models.Post, error)
+	// ListDueScheduledPosts returns unpublished posts whose scheduled time is at or before now.
+	ListDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]models.Post, error)
+	// TransitionPostStatus moves a post from one status to another. It fails with ErrConflict
+	// if the post is no longer in the from status, so concurrent transitions cannot both win.
+	TransitionPostStatus(ctx context.Context, postID string, from models.PostStatus, to models.PostStatus) (*models.Post, error)
+	// CompletePostSending marks a sending post as sent or failed once none of its deliveries are pending.
+	// It is a no-op while deliveries remain or when the post is not sending.
+	CompletePostSending(ctx context.Context, postID string) error
 }
 
 type postgresPostRepository struct {
@@ -98,14 +165,14 @@ func (r *postgresPostRepository) CreatePost(ctx context.Context, post *models.Po
 	if post.ID == "" {
 		post.ID = uuid.NewString()
 	}
+	if post.Status == "" {
+		post.Status = models.PostStatusDraft
+	}
 
 	var createdPostDB dbPost
 	err := r.db.QueryRowContext(ctx, createPostQuery,
-		post.ID, post.NewsletterID, post.Title, post.Content, post.PublishedAt, post.CreatedAt, post.UpdatedAt,
-	).Scan(
-		&createdPostDB.ID, &createdPostDB.NewsletterID, &createdPostDB.Title, &createdPostDB.Content,
-		&createdPostDB.PublishedAt, &createdPostDB.CreatedAt, &createdPostDB.UpdatedAt,
-	)
+		post.ID, post.NewsletterID, post.Title, post.Content, string(post.Status), post.PublishedAt, post.ScheduledAt, post.CreatedAt, post.UpdatedAt,
+	).Scan(createdPostDB.scanDest()...)
 
 	if err != nil {
 		var pqErr *pq.Error
@@ -125,9 +192,7 @@ func (r *postgresPostRepository) CreatePost(ctx context.Context, post *models.Po
 
 func (r *postgresPostRepository) GetPostByID(ctx context.Context, postID string) (*models.Post, error) {
 	var p dbPost
-	err := r.db.QueryRowContext(ctx, getPostByIDQuery, postID).Scan(
-		&p.ID, &p.NewsletterID, &p.Title, &p.Content, &p.PublishedAt, &p.CreatedAt, &p.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, getPostByIDQuery, postID).Scan(p.scanDest()...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("post repo: GetPostByID: %w", apperrors.ErrPostNotFound)
@@ -138,8 +203,8 @@ func (r *postgresPostRepository) GetPostByID(ctx context.Context, postID string)
 	return &model, nil
 }
 
-func (r *postgresPostRepository) ListPostsByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Post, int, error) {
-	rows, err := r.db.QueryContext(ctx, listPostsByNewsletterIDQuery, newsletterID, limit, offset)
+func (r *postgresPostRepository) ListPostsByNewsletterID(ctx context.Context, newsletterID string, status models.PostStatus, limit int, offset int) ([]models.Post, int, error) {
+	rows, err := r.db.QueryContext(ctx, listPostsByNewsletterIDQuery, newsletterID, string(status), limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("post repo: ListPostsByNewsletterID: query: %w", err)
 	}
@@ -148,7 +213,7 @@ func (r *postgresPostRepository) ListPostsByNewsletterID(ctx context.Context, ne
 	var dbPosts []dbPost
 	for rows.Next() {
 		var p dbPost
-		if errScan := rows.Scan(&p.ID, &p.NewsletterID, &p.Title, &p.Content, &p.PublishedAt, &p.CreatedAt, &p.UpdatedAt); errScan != nil {
+		if errScan := rows.Scan(p.scanDest()...); errScan != nil {
 			return nil, 0, fmt.Errorf("post repo: ListPostsByNewsletterID: scan: %w", errScan)
 		}
 		dbPosts = append(dbPosts, p)
@@ -163,7 +228,7 @@ func (r *postgresPostRepository) ListPostsByNewsletterID(ctx context.Context, ne
 	}
 
 	var totalCount int
-	err = r.db.QueryRowContext(ctx, countPostsByNewsletterIDQuery, newsletterID).Scan(&totalCount)
+	err = r.db.QueryRowContext(ctx, countPostsByNewsletterIDQuery, newsletterID, string(status)).Scan(&totalCount)
 	if err != nil {
 		return posts, 0, fmt.Errorf("post repo: ListPostsByNewsletterID: count query: %w", err)
 	}
@@ -190,6 +255,18 @@ func (r *postgresPostRepository) UpdatePost(ctx context.Context, postID string,
 		args = append(args, *updates.Content)
 		argIndex++
 	}
+
+	if updates.ScheduledAt != nil {
+		setParts = append(setParts, fmt.Sprintf("scheduled_at = $%d", argIndex))
+		args = append(args, *updates.ScheduledAt)
+		argIndex++
+	}
+
+	if updates.Status != nil {
+		setParts = append(setParts, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, string(*updates.Status))
+		argIndex++
+	}
 	
 
 	
@@ -210,14 +287,11 @@ func (r *postgresPostRepository) UpdatePost(ctx context.Context, postID string,
 		UPDATE posts 
 		SET %s 
 		WHERE id = $%d 
-		RETURNING id, newsletter_id, title, content, published_at, created_at, updated_at`,
+		RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at`,
 		strings.Join(setParts, ", "), argIndex)
 	
 	var updatedPostDB dbPost
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
-		&updatedPostDB.ID, &updatedPostDB.NewsletterID, &updatedPostDB.Title, &updatedPostDB.Content,
-		&updatedPostDB.PublishedAt, &updatedPostDB.CreatedAt, &updatedPostDB.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(updatedPostDB.scanDest()...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -242,20 +316,11 @@ func (r *postgresPostRepository) SetPostPublished(ctx context.Context, postID st
 	updatedAt := time.Now().UTC()
 
 	var updatedPostDB dbPost
-	err := r.db.QueryRowContext(ctx, `
-		UPDATE posts 
-		SET published_at = $1, updated_at = $2 
-		WHERE id = $3 
-		RETURNING id, newsletter_id, title, content, published_at, created_at, updated_at`,
-		publishedAt, updatedAt, postID,
-	).Scan(
-		&updatedPostDB.ID, &updatedPostDB.NewsletterID, &updatedPostDB.Title, &updatedPostDB.Content,
-		&updatedPostDB.PublishedAt, &updatedPostDB.CreatedAt, &updatedPostDB.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, markPostAsPublishedQuery, publishedAt, updatedAt, postID).Scan(updatedPostDB.scanDest()...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("post repo: SetPostPublished: %w", apperrors.ErrPostNotFound)
+			return nil, fmt.Errorf("post repo: SetPostPublished: post not found or no longer a draft or scheduled: %w", apperrors.ErrConflict)
 		}
 		return nil, fmt.Errorf("post repo: SetPostPublished: %w", err)
 	}
@@ -263,24 +328,52 @@ func (r *postgresPostRepository) SetPostPublished(ctx context.Context, postID st
 	return &model, nil
 }
 
-func (r *postgresPostRepository) SetPostUnpublished(ctx context.Context, postID string) (*models.Post, error) {
-	updatedAt := time.Now().UTC()
+func (r *postgresPostRepository) SetScheduledPostPublished(ctx context.Context, postID string, now time.Time) (*models.Post, error) {
+	var p dbPost
+	err := r.db.QueryRowContext(ctx, publishScheduledPostQuery, now, postID).Scan(p.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post repo: SetScheduledPostPublished: post not found or no longer scheduled and due: %w", apperrors.ErrConflict)
+		}
+		return nil, fmt.Errorf("post repo: SetScheduledPostPublished: %w", err)
+	}
+	model := p.toModel()
+	return &model, nil
+}
 
+func (r *postgresPostRepository) RestorePostSchedule(ctx context.Context, postID string, scheduledAt time.Time) (*models.Post, error) {
+	var p dbPost
+	err := r.db.QueryRowContext(ctx, restorePostScheduleQuery, postID, scheduledAt, time.Now().UTC()).Scan(p.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post repo: RestorePostSchedule: post not found or no longer sending: %w", apperrors.ErrConflict)
+		}
+		return nil, fmt.Errorf("post repo: RestorePostSchedule: %w", err)
+	}
+	model := p.toModel()
+	return &model, nil
+}
+
+func (r *postgresPostRepository) RestorePostDraft(ctx context.Context, postID string) (*models.Post, error) {
+	var p dbPost
+	err := r.db.QueryRowContext(ctx, restorePostDraftQuery, postID, time.Now().UTC()).Scan(p.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post repo: RestorePostDraft: post not found or no longer sending: %w", apperrors.ErrConflict)
+		}
+		return nil, fmt.Errorf("post repo: RestorePostDraft: %w", err)
+	}
+	model := p.toModel()
+	return &model, nil
+}
+
+func (r *postgresPostRepository) SetPostUnpublished(ctx context.Context, postID string, recalledBy string, recalledAt time.Time) (*models.Post, error) {
 	var updatedPostDB dbPost
-	err := r.db.QueryRowContext(ctx, `
-		UPDATE posts 
-		SET published_at = NULL, updated_at = $1 
-		WHERE id = $2 
-		RETURNING id, newsletter_id, title, content, published_at, created_at, updated_at`,
-		updatedAt, postID,
-	).Scan(
-		&updatedPostDB.ID, &updatedPostDB.NewsletterID, &updatedPostDB.Title, &updatedPostDB.Content,
-		&updatedPostDB.PublishedAt, &updatedPostDB.CreatedAt, &updatedPostDB.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, unpublishPostQuery, recalledAt, recalledBy, postID).Scan(updatedPostDB.scanDest()...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("post repo: SetPostUnpublished: %w", apperrors.ErrPostNotFound)
+			return nil, fmt.Errorf("post repo: SetPostUnpublished: post not found or no longer published: %w", apperrors.ErrConflict)
 		}
 		return nil, fmt.Errorf("post repo: SetPostUnpublished: %w", err)
 	}
@@ -302,3 +395,58 @@ func (r *postgresPostRepository) DeletePost(ctx context.Context, postID string)
 	}
 	return nil
 }
+
+func (r *postgresPostRepository) ClearPostSchedule(ctx context.Context, postID string) (*models.Post, error) {
+	var p dbPost
+	err := r.db.QueryRowContext(ctx, clearPostScheduleQuery, time.Now().UTC(), postID).Scan(p.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post repo: ClearPostSchedule: post not found or no longer scheduled: %w", apperrors.ErrConflict)
+		}
+		return nil, fmt.Errorf("post repo: ClearPostSchedule: %w", err)
+	}
+	model := p.toModel()
+	return &model, nil
+}
+
+func (r *postgresPostRepository) ListDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]models.Post, error) {
+	rows, err := r.db.QueryContext(ctx, listDueScheduledPostsQuery, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("post repo: ListDueScheduledPosts: query: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var p dbPost
+		if errScan := rows.Scan(p.scanDest()...); errScan != nil {
+			return nil, fmt.Errorf("post repo: ListDueScheduledPosts: scan: %w", errScan)
+		}
+		posts = append(posts, p.toModel())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("post repo: ListDueScheduledPosts: rows error: %w", err)
+	}
+	return posts, nil
+}
+
+func (r *postgresPostRepository) TransitionPostStatus(ctx context.Context, postID string, from models.PostStatus, to models.PostStatus) (*models.Post, error) {
+	var p dbPost
+	err := r.db.QueryRowContext(ctx, transitionPostStatusQuery, postID, string(from), string(to), time.Now().UTC()).Scan(p.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post repo: TransitionPostStatus: post not found or no longer %s: %w", from, apperrors.ErrConflict)
+		}
+		return nil, fmt.Errorf("post repo: TransitionPostStatus: %w", err)
+	}
+	model := p.toModel()
+	return &model, nil
+}
+
+func (r *postgresPostRepository) CompletePostSending(ctx context.Context, postID string) error {
+	_, err := r.db.ExecContext(ctx, completePostSendingQuery, postID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("post repo: CompletePostSending: exec: %w", err)
+	}
+	return nil
+}
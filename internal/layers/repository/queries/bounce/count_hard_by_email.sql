@@ -0,0 +1,4 @@
+-- internal/queries/bounce/count_hard_by_email.sql
+SELECT COUNT(*)
+FROM bounces
+WHERE email = $1 AND type = 'hard';
@@ -0,0 +1,4 @@
+-- internal/queries/bounce/create.sql
+INSERT INTO bounces (email, type, action, status, diagnostic_code, reporting_mta)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, received_at;
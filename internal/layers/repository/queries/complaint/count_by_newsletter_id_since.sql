@@ -0,0 +1,4 @@
+-- internal/queries/complaint/count_by_newsletter_id_since.sql
+SELECT COUNT(*)
+FROM complaints
+WHERE newsletter_id = $1 AND received_at >= $2;
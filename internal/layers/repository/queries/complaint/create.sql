@@ -0,0 +1,4 @@
+-- internal/queries/complaint/create.sql
+INSERT INTO complaints (newsletter_id, subscriber_id, email, feedback_type, user_agent)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, received_at;
@@ -0,0 +1,4 @@
+-- internal/queries/delivery/cancel.sql
+UPDATE deliveries
+SET status = 'cancelled', last_error = $2, locked_at = NULL
+WHERE id = $1 AND status = 'processing';
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- internal/queries/delivery/cancel_pending_by_post_id.sql
+UPDATE deliveries
+SET status = 'cancelled', locked_at = NULL
+WHERE post_id = $1
+  AND status IN ('queued', 'failed');
\ No newline at end of file
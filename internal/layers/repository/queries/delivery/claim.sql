@@ -0,0 +1,16 @@
+-- internal/queries/delivery/claim.sql
+UPDATE deliveries
+SET status = 'processing', locked_at = NOW()
+WHERE id IN (
+    SELECT id
+    FROM deliveries
+    WHERE ((status IN ('queued', 'failed') AND next_attempt_at <= NOW())
+       OR (status = 'processing' AND locked_at < NOW() - make_interval(secs => $2)))
+      -- Only send while the post is being published; recalled posts are skipped
+      AND EXISTS (SELECT 1 FROM posts p WHERE p.id = deliveries.post_id AND p.status = 'sending')
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, post_id, subscriber_id, email, subscriber_name, subscriber_attributes, unsubscribe_token, status, attempts, last_error,
+          next_attempt_at, locked_at, sent_at, created_at, updated_at;
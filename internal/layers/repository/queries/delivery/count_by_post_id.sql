@@ -0,0 +1,4 @@
+-- internal/queries/delivery/count_by_post_id.sql
+SELECT COUNT(*)
+FROM deliveries
+WHERE post_id = $1 AND ($2 = '' OR status = $2);
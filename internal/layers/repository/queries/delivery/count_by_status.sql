@@ -0,0 +1,5 @@
+-- internal/queries/delivery/count_by_status.sql
+SELECT status, COUNT(*)
+FROM deliveries
+WHERE post_id = $1
+GROUP BY status;
@@ -0,0 +1,20 @@
+-- internal/queries/delivery/count_by_subscriber_since.sql
+-- Counts, per subscriber, the newsletter's posts that reached the given final status at or after $3,
+-- whether they were sent on their own or in a digest.
+SELECT subscriber_id, COUNT(*)
+FROM (
+    SELECT d.subscriber_id
+    FROM deliveries d
+    JOIN posts p ON p.id = d.post_id
+    WHERE p.newsletter_id = $1
+      AND d.status = $2
+      AND COALESCE(d.sent_at, d.updated_at) >= $3
+    UNION ALL
+    SELECT i.subscriber_id
+    FROM digest_items i
+    JOIN posts p ON p.id = i.post_id
+    WHERE p.newsletter_id = $1
+      AND i.status = $2
+      AND COALESCE(i.sent_at, i.due_at) >= $3
+) AS counted
+GROUP BY subscriber_id;
\ No newline at end of file
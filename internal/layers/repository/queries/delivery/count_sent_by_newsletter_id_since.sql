@@ -0,0 +1,12 @@
+-- internal/queries/delivery/count_sent_by_newsletter_id_since.sql
+-- Counts the newsletter's messages sent at or after $2: single issues and digests. The items of a digest
+-- are sent in one message and marked sent together, so they share a subscriber and sent_at.
+SELECT
+    (SELECT COUNT(*)
+     FROM deliveries d
+     JOIN posts p ON p.id = d.post_id
+     WHERE p.newsletter_id = $1 AND d.status = 'sent' AND d.sent_at >= $2)
+  + (SELECT COUNT(DISTINCT (i.subscriber_id, i.sent_at))
+     FROM digest_items i
+     JOIN posts p ON p.id = i.post_id
+     WHERE p.newsletter_id = $1 AND i.status = 'sent' AND i.sent_at >= $2);
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- internal/queries/delivery/count_sent_since.sql
+-- Counts the messages sent at or after $1: single issues and digests. The items of a digest are sent
+-- in one message and marked sent together, so they share a subscriber and sent_at.
+SELECT
+    (SELECT COUNT(*) FROM deliveries WHERE status = 'sent' AND sent_at >= $1)
+  + (SELECT COUNT(DISTINCT (subscriber_id, sent_at)) FROM digest_items WHERE status = 'sent' AND sent_at >= $1);
\ No newline at end of file
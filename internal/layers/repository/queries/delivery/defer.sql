@@ -0,0 +1,4 @@
+-- internal/queries/delivery/defer.sql
+UPDATE deliveries
+SET status = CASE WHEN attempts = 0 THEN 'queued' ELSE 'failed' END, locked_at = NULL, next_attempt_at = $2
+WHERE id = $1 AND status = 'processing';
@@ -0,0 +1,10 @@
+-- internal/queries/delivery/enqueue.sql
+INSERT INTO deliveries (post_id, subscriber_id, email, unsubscribe_token, subscriber_name, subscriber_attributes)
+SELECT $1, d.subscriber_id, d.email, d.unsubscribe_token, d.subscriber_name, d.subscriber_attributes::jsonb
+FROM unnest($2::text[], $3::text[], $4::text[], $5::text[], $6::text[])
+    AS d(subscriber_id, email, unsubscribe_token, subscriber_name, subscriber_attributes)
+ON CONFLICT (post_id, subscriber_id) DO UPDATE
+    -- Deliveries cancelled by a recall are queued again when the post is republished
+    SET status = 'queued', attempts = 0, last_error = NULL, next_attempt_at = NOW(), locked_at = NULL,
+        subscriber_name = EXCLUDED.subscriber_name, subscriber_attributes = EXCLUDED.subscriber_attributes
+    WHERE deliveries.status = 'cancelled';
@@ -0,0 +1,7 @@
+-- internal/queries/delivery/list_by_post_id.sql
+SELECT id, post_id, subscriber_id, email, subscriber_name, subscriber_attributes, unsubscribe_token, status, attempts, last_error,
+       next_attempt_at, locked_at, sent_at, created_at, updated_at
+FROM deliveries
+WHERE post_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY created_at, id
+LIMIT $3 OFFSET $4;
@@ -0,0 +1,5 @@
+-- internal/queries/delivery/mark_failed.sql
+UPDATE deliveries
+SET status = $2, attempts = attempts + 1, last_error = $3, locked_at = NULL,
+    next_attempt_at = COALESCE($4, next_attempt_at)
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- internal/queries/delivery/mark_sent.sql
+UPDATE deliveries
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, locked_at = NULL, sent_at = NOW()
+WHERE id = $1;
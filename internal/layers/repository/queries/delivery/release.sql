@@ -0,0 +1,4 @@
+-- internal/queries/delivery/release.sql
+UPDATE deliveries
+SET status = CASE WHEN attempts = 0 THEN 'queued' ELSE 'failed' END, locked_at = NULL
+WHERE id = $1 AND status = 'processing';
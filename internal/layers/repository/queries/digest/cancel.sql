@@ -0,0 +1,4 @@
+-- internal/queries/digest/cancel.sql
+UPDATE digest_items
+SET status = 'cancelled', locked_at = NULL
+WHERE id = ANY($1::uuid[]);
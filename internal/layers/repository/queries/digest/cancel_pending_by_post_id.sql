@@ -0,0 +1,5 @@
+-- internal/queries/digest/cancel_pending_by_post_id.sql
+UPDATE digest_items
+SET status = 'cancelled', locked_at = NULL
+WHERE post_id = $1
+  AND status IN ('queued', 'failed');
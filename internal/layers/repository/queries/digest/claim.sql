@@ -0,0 +1,24 @@
+-- internal/queries/digest/claim.sql
+-- Claims every due item of up to $1 subscribers, so each digest is sent with all of its posts.
+WITH due AS (
+    SELECT id, subscriber_id, due_at
+    FROM digest_items
+    WHERE ((status IN ('queued', 'failed') AND due_at <= NOW())
+       OR (status = 'processing' AND locked_at < NOW() - make_interval(secs => $2)))
+      -- Recalled posts are left out of digests
+      AND EXISTS (SELECT 1 FROM posts p WHERE p.id = digest_items.post_id AND p.status IN ('sending', 'sent'))
+    FOR UPDATE SKIP LOCKED
+), subscribers AS (
+    SELECT subscriber_id
+    FROM due
+    GROUP BY subscriber_id
+    ORDER BY MIN(due_at)
+    LIMIT $1
+)
+UPDATE digest_items
+SET status = 'processing', locked_at = NOW()
+FROM due
+WHERE digest_items.id = due.id AND due.subscriber_id IN (SELECT subscriber_id FROM subscribers)
+RETURNING digest_items.id, digest_items.post_id, digest_items.subscriber_id, digest_items.email,
+          digest_items.unsubscribe_token, digest_items.status, digest_items.attempts, digest_items.last_error,
+          digest_items.due_at, digest_items.sent_at, digest_items.created_at;
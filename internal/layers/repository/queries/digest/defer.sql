@@ -0,0 +1,4 @@
+-- internal/queries/digest/defer.sql
+UPDATE digest_items
+SET status = CASE WHEN attempts = 0 THEN 'queued' ELSE 'failed' END, locked_at = NULL, due_at = $2
+WHERE id = ANY($1::uuid[]) AND status = 'processing';
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- internal/queries/digest/enqueue.sql
+INSERT INTO digest_items (post_id, subscriber_id, email, unsubscribe_token, due_at)
+SELECT $1, d.subscriber_id, d.email, d.unsubscribe_token, d.due_at
+FROM unnest($2::text[], $3::text[], $4::text[], $5::timestamptz[]) AS d(subscriber_id, email, unsubscribe_token, due_at)
+ON CONFLICT (post_id, subscriber_id) DO UPDATE
+    -- Items cancelled by a recall are queued again when the post is republished
+    SET status = 'queued', attempts = 0, last_error = NULL, due_at = EXCLUDED.due_at, locked_at = NULL
+    WHERE digest_items.status = 'cancelled';
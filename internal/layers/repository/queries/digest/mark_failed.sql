@@ -0,0 +1,5 @@
+-- internal/queries/digest/mark_failed.sql
+UPDATE digest_items
+SET status = $2, attempts = attempts + 1, last_error = $3, locked_at = NULL,
+    due_at = COALESCE($4, due_at)
+WHERE id = ANY($1::uuid[]);
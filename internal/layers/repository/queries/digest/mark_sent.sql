@@ -0,0 +1,4 @@
+-- internal/queries/digest/mark_sent.sql
+UPDATE digest_items
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, locked_at = NULL, sent_at = NOW()
+WHERE id = ANY($1::uuid[]);
@@ -0,0 +1,4 @@
+-- internal/queries/digest/release.sql
+UPDATE digest_items
+SET status = CASE WHEN attempts = 0 THEN 'queued' ELSE 'failed' END, locked_at = NULL
+WHERE id = ANY($1::uuid[]) AND status = 'processing';
@@ -0,0 +1,3 @@
+-- internal/queries/email_template/delete.sql
+DELETE FROM newsletter_email_templates
+WHERE newsletter_id = $1 AND name = $2;
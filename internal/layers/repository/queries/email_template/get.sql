@@ -0,0 +1,4 @@
+-- internal/queries/email_template/get.sql
+SELECT newsletter_id, name, body, created_at, updated_at
+FROM newsletter_email_templates
+WHERE newsletter_id = $1 AND name = $2;
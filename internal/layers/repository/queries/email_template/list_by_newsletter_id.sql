@@ -0,0 +1,5 @@
+-- internal/queries/email_template/list_by_newsletter_id.sql
+SELECT newsletter_id, name, body, created_at, updated_at
+FROM newsletter_email_templates
+WHERE newsletter_id = $1
+ORDER BY name;
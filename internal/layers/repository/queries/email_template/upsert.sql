@@ -0,0 +1,5 @@
+-- internal/queries/email_template/upsert.sql
+INSERT INTO newsletter_email_templates (newsletter_id, name, body)
+VALUES ($1, $2, $3)
+ON CONFLICT (newsletter_id, name) DO UPDATE SET body = EXCLUDED.body
+RETURNING newsletter_id, name, body, created_at, updated_at;
@@ -1,4 +1,4 @@
 -- internal/queries/newsletter/create.sql
 INSERT INTO newsletters (editor_id, name, description)
 VALUES ($1, $2, $3)
-RETURNING id, editor_id, name, description, created_at, updated_at; 
\ No newline at end of file
+RETURNING id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at; 
\ No newline at end of file
@@ -1,4 +1,4 @@
 -- internal/queries/newsletter/get_by_name_and_editor_id.sql
-SELECT id, editor_id, name, description, created_at, updated_at
+SELECT id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at
 FROM newsletters
 WHERE name = $1 AND editor_id = $2; 
\ No newline at end of file
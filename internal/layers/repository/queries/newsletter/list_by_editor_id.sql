@@ -1,5 +1,5 @@
 -- internal/queries/newsletter/list_by_editor_id.sql
-SELECT id, editor_id, name, description, created_at, updated_at
+SELECT id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at
 FROM newsletters
 WHERE editor_id = $1
 ORDER BY created_at DESC
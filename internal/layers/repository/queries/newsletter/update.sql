@@ -1,5 +1,5 @@
 -- internal/queries/newsletter/update.sql
 UPDATE newsletters
-SET name = COALESCE($1, name), description = COALESCE($2, description), updated_at = NOW()
-WHERE id = $3 AND editor_id = $4
-RETURNING id, editor_id, name, description, created_at, updated_at; 
\ No newline at end of file
+SET name = COALESCE($1, name), description = COALESCE($2, description), opt_in_mode = COALESCE($3, opt_in_mode), updated_at = NOW()
+WHERE id = $4 AND editor_id = $5
+RETURNING id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at; 
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- internal/queries/newsletter/update_branding.sql
+UPDATE newsletters
+SET logo_url = $1, accent_color = $2, footer_text = $3, mailing_address = $4, header_html = $5, footer_html = $6, updated_at = NOW()
+WHERE id = $7 AND editor_id = $8
+RETURNING id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- internal/queries/newsletter/update_subscriber_fields.sql
+UPDATE newsletters
+SET subscriber_fields = $1, updated_at = NOW()
+WHERE id = $2 AND editor_id = $3
+RETURNING id, editor_id, name, description, logo_url, accent_color, footer_text, mailing_address, header_html, footer_html, opt_in_mode, subscriber_fields, created_at, updated_at;
\ No newline at end of file
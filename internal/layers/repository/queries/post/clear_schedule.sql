@@ -0,0 +1,6 @@
+-- internal/queries/post/clear_schedule.sql
+UPDATE posts
+SET status = 'draft', scheduled_at = NULL, updated_at = $1
+WHERE id = $2
+  AND status = 'scheduled'
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- internal/queries/post/complete_sending.sql
+-- Moves a sending post to sent (or failed when nothing could be delivered) once no delivery is pending.
+UPDATE posts p
+SET status = CASE
+        WHEN EXISTS (SELECT 1 FROM deliveries d WHERE d.post_id = p.id AND d.status = 'permanently_failed')
+         AND NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.post_id = p.id AND d.status = 'sent')
+        THEN 'failed'
+        ELSE 'sent'
+    END,
+    updated_at = $2
+WHERE p.id = $1
+  AND p.status = 'sending'
+  AND NOT EXISTS (
+      SELECT 1 FROM deliveries d
+      WHERE d.post_id = p.id AND d.status IN ('queued', 'processing', 'failed')
+  );
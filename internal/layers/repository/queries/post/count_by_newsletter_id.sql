@@ -1,4 +1,5 @@
 -- internal/queries/post/count_by_newsletter_id.sql
 SELECT COUNT(*)
 FROM posts
-WHERE newsletter_id = $1; 
\ No newline at end of file
+WHERE newsletter_id = $1
+  AND ($2 = '' OR status = $2); 
\ No newline at end of file
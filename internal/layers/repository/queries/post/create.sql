@@ -1,4 +1,4 @@
 -- internal/queries/post/create.sql
-INSERT INTO posts (id, newsletter_id, title, content, published_at, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, newsletter_id, title, content, published_at, created_at, updated_at; 
\ No newline at end of file
+INSERT INTO posts (id, newsletter_id, title, content, status, published_at, scheduled_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at; 
\ No newline at end of file
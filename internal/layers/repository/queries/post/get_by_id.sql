@@ -1,4 +1,4 @@
 -- internal/queries/post/get_by_id.sql
-SELECT id, newsletter_id, title, content, published_at, created_at, updated_at
+SELECT id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at
 FROM posts
 WHERE id = $1; 
\ No newline at end of file
@@ -1,6 +1,7 @@
 -- internal/queries/post/list_by_newsletter_id.sql
-SELECT id, newsletter_id, title, content, published_at, created_at, updated_at
+SELECT id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at
 FROM posts
 WHERE newsletter_id = $1
+  AND ($2 = '' OR status = $2)
 ORDER BY created_at DESC
-LIMIT $2 OFFSET $3; 
\ No newline at end of file
+LIMIT $3 OFFSET $4; 
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- internal/queries/post/list_due_scheduled.sql
+SELECT id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at
+FROM posts
+WHERE status = 'scheduled'
+  AND scheduled_at <= $1
+ORDER BY scheduled_at
+LIMIT $2;
\ No newline at end of file
@@ -1,5 +1,5 @@
 -- internal/queries/post/mark_as_published.sql
 UPDATE posts
-SET published_at = $1, updated_at = $2
-WHERE id = $3
-RETURNING id, newsletter_id, title, content, published_at, created_at, updated_at; 
\ No newline at end of file
+SET status = 'sending', published_at = $1, scheduled_at = NULL, updated_at = $2
+WHERE id = $3 AND status IN ('draft', 'scheduled')
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at; 
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- internal/queries/post/publish_scheduled.sql
+-- Publishes a scheduled post only while it is still scheduled and due, so a post cancelled or rescheduled
+-- since the scheduler listed it is left alone.
+UPDATE posts
+SET status = 'sending', published_at = $1, scheduled_at = NULL, updated_at = $1
+WHERE id = $2
+  AND status = 'scheduled'
+  AND scheduled_at <= $1
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- internal/queries/post/restore_draft.sql
+-- Returns a post whose publishing could not be completed to draft, so the editor can publish it again.
+UPDATE posts
+SET status = 'draft', published_at = NULL, updated_at = $2
+WHERE id = $1
+  AND status = 'sending'
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
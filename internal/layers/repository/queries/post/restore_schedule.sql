@@ -0,0 +1,7 @@
+-- internal/queries/post/restore_schedule.sql
+-- Returns a post the scheduler started publishing to its schedule, so the next run retries it.
+UPDATE posts
+SET status = 'scheduled', published_at = NULL, scheduled_at = $2, updated_at = $3
+WHERE id = $1
+  AND status = 'sending'
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- internal/queries/post/transition_status.sql
+UPDATE posts
+SET status = $3, updated_at = $4
+WHERE id = $1
+  AND status = $2
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- internal/queries/post/unpublish.sql
+UPDATE posts
+SET status = 'draft', published_at = NULL, recalled_at = $1, recalled_by = $2, updated_at = $1
+WHERE id = $3 AND status IN ('sending', 'sent', 'failed')
+RETURNING id, newsletter_id, title, content, status, published_at, scheduled_at, recalled_at, recalled_by, created_at, updated_at;
\ No newline at end of file
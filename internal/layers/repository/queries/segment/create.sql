@@ -0,0 +1,4 @@
+-- internal/queries/segment/create.sql
+INSERT INTO segments (newsletter_id, name, description, match, rules)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, newsletter_id, name, description, match, rules, created_at, updated_at;
\ No newline at end of file
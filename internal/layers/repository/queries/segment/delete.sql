@@ -0,0 +1,3 @@
+-- internal/queries/segment/delete.sql
+DELETE FROM segments
+WHERE id = $1;
\ No newline at end of file
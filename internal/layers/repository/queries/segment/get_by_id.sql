@@ -0,0 +1,4 @@
+-- internal/queries/segment/get_by_id.sql
+SELECT id, newsletter_id, name, description, match, rules, created_at, updated_at
+FROM segments
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- internal/queries/segment/list_by_newsletter_id.sql
+SELECT id, newsletter_id, name, description, match, rules, created_at, updated_at
+FROM segments
+WHERE newsletter_id = $1
+ORDER BY name;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- internal/queries/segment/update.sql
+UPDATE segments
+SET name = $2,
+    description = $3,
+    match = $4,
+    rules = $5
+WHERE id = $1
+RETURNING id, newsletter_id, name, description, match, rules, created_at, updated_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- internal/queries/suppression/count.sql
+SELECT COUNT(*)
+FROM suppressions
+WHERE editor_id IS NOT DISTINCT FROM $1;
@@ -0,0 +1,4 @@
+-- internal/queries/suppression/create.sql
+INSERT INTO suppressions (editor_id, type, value, reason, source, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at;
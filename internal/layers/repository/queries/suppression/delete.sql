@@ -0,0 +1,3 @@
+-- internal/queries/suppression/delete.sql
+DELETE FROM suppressions
+WHERE id = $1;
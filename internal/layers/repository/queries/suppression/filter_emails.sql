@@ -0,0 +1,14 @@
+-- internal/queries/suppression/filter_emails.sql
+-- Returns the given lower-case addresses that a global entry or an entry of the newsletter's editor
+-- suppresses, either by address or by the address's domain or a parent domain.
+SELECT e.email
+FROM unnest($2::text[]) AS e(email)
+WHERE EXISTS (
+    SELECT 1
+    FROM suppressions s
+    WHERE (s.editor_id IS NULL OR s.editor_id = (SELECT n.editor_id FROM newsletters n WHERE n.id = $1))
+      AND (
+          (s.type = 'address' AND s.value = e.email)
+          OR (s.type = 'domain' AND (split_part(e.email, '@', 2) = s.value OR split_part(e.email, '@', 2) LIKE '%.' || s.value))
+      )
+);
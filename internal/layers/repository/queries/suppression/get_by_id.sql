@@ -0,0 +1,4 @@
+-- internal/queries/suppression/get_by_id.sql
+SELECT id, editor_id, type, value, reason, source, created_by, created_at
+FROM suppressions
+WHERE id = $1;
@@ -0,0 +1,7 @@
+-- internal/queries/suppression/list.sql
+-- A NULL editor ID lists the global entries.
+SELECT id, editor_id, type, value, reason, source, created_by, created_at
+FROM suppressions
+WHERE editor_id IS NOT DISTINCT FROM $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
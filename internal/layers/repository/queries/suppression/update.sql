@@ -0,0 +1,6 @@
+-- internal/queries/suppression/update.sql
+UPDATE suppressions
+SET reason = COALESCE($2, reason),
+    source = COALESCE($3, source)
+WHERE id = $1
+RETURNING id, editor_id, type, value, reason, source, created_by, created_at;
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/segment/create.sql
+var createSegmentQuery string
+
+//go:embed queries/segment/get_by_id.sql
+var getSegmentByIDQuery string
+
+//go:embed queries/segment/list_by_newsletter_id.sql
+var listSegmentsByNewsletterIDQuery string
+
+//go:embed queries/segment/update.sql
+var updateSegmentQuery string
+
+//go:embed queries/segment/delete.sql
+var deleteSegmentQuery string
+
+// dbSegment maps directly to the 'segments' table schema.
+type dbSegment struct {
+	ID           string               `db:"id"`
+	NewsletterID string               `db:"newsletter_id"`
+	Name         string               `db:"name"`
+	Description  string               `db:"description"`
+	Match        string               `db:"match"`
+	Rules        []models.SegmentRule `db:"rules"` // JSONB
+	CreatedAt    time.Time            `db:"created_at"`
+	UpdatedAt    time.Time            `db:"updated_at"`
+}
+
+// scanDest returns the scan destinations in the column order used by segment queries.
+func (dbS *dbSegment) scanDest() []interface{} {
+	return []interface{}{
+		&dbS.ID, &dbS.NewsletterID, &dbS.Name, &dbS.Description, &dbS.Match, jsonColumn{&dbS.Rules}, &dbS.CreatedAt, &dbS.UpdatedAt,
+	}
+}
+
+// toModel converts a dbSegment to a models.Segment domain object.
+func (dbS *dbSegment) toModel() models.Segment {
+	rules := dbS.Rules
+	if rules == nil {
+		rules = []models.SegmentRule{}
+	}
+	return models.Segment{
+		ID:           dbS.ID,
+		NewsletterID: dbS.NewsletterID,
+		Name:         dbS.Name,
+		Description:  dbS.Description,
+		Match:        models.SegmentMatch(dbS.Match),
+		Rules:        rules,
+		CreatedAt:    dbS.CreatedAt,
+		UpdatedAt:    dbS.UpdatedAt,
+	}
+}
+
+// SegmentRepository stores the saved segments of newsletters.
+type SegmentRepository interface {
+	// CreateSegment stores a segment of segment.NewsletterID. A name already used by the newsletter returns ErrConflict.
+	CreateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error)
+	GetSegmentByID(ctx context.Context, segmentID string) (*models.Segment, error)
+	ListSegmentsByNewsletterID(ctx context.Context, newsletterID string) ([]models.Segment, error)
+	// UpdateSegment replaces the name, description, match and rules of a segment.
+	UpdateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error)
+	DeleteSegment(ctx context.Context, segmentID string) error
+}
+
+type postgresSegmentRepository struct {
+	db *sql.DB
+}
+
+// NewSegmentRepository creates a new instance of postgresSegmentRepository.
+func NewSegmentRepository(db *sql.DB) SegmentRepository {
+	return &postgresSegmentRepository{db: db}
+}
+
+func (r *postgresSegmentRepository) CreateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error) {
+	rulesJSON, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("segment repo: CreateSegment: marshal rules: %w", err)
+	}
+
+	var dbS dbSegment
+	err = r.db.QueryRowContext(ctx, createSegmentQuery,
+		segment.NewsletterID, segment.Name, segment.Description, string(segment.Match), string(rulesJSON),
+	).Scan(dbS.scanDest()...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("segment repo: CreateSegment: %w: segment '%s' already exists", apperrors.ErrConflict, segment.Name)
+		}
+		return nil, fmt.Errorf("segment repo: CreateSegment: scan: %w", err)
+	}
+	model := dbS.toModel()
+	return &model, nil
+}
+
+func (r *postgresSegmentRepository) GetSegmentByID(ctx context.Context, segmentID string) (*models.Segment, error) {
+	var dbS dbSegment
+	err := r.db.QueryRowContext(ctx, getSegmentByIDQuery, segmentID).Scan(dbS.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("segment repo: GetSegmentByID: %w", apperrors.ErrSegmentNotFound)
+		}
+		return nil, fmt.Errorf("segment repo: GetSegmentByID: scan: %w", err)
+	}
+	model := dbS.toModel()
+	return &model, nil
+}
+
+func (r *postgresSegmentRepository) ListSegmentsByNewsletterID(ctx context.Context, newsletterID string) ([]models.Segment, error) {
+	rows, err := r.db.QueryContext(ctx, listSegmentsByNewsletterIDQuery, newsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("segment repo: ListSegmentsByNewsletterID: query: %w", err)
+	}
+	defer rows.Close()
+
+	segments := make([]models.Segment, 0)
+	for rows.Next() {
+		var dbS dbSegment
+		if err := rows.Scan(dbS.scanDest()...); err != nil {
+			return nil, fmt.Errorf("segment repo: ListSegmentsByNewsletterID: scan: %w", err)
+		}
+		segments = append(segments, dbS.toModel())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("segment repo: ListSegmentsByNewsletterID: rows error: %w", err)
+	}
+	return segments, nil
+}
+
+func (r *postgresSegmentRepository) UpdateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error) {
+	rulesJSON, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("segment repo: UpdateSegment: marshal rules: %w", err)
+	}
+
+	var dbS dbSegment
+	err = r.db.QueryRowContext(ctx, updateSegmentQuery,
+		segment.ID, segment.Name, segment.Description, string(segment.Match), string(rulesJSON),
+	).Scan(dbS.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("segment repo: UpdateSegment: %w", apperrors.ErrSegmentNotFound)
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("segment repo: UpdateSegment: %w: segment '%s' already exists", apperrors.ErrConflict, segment.Name)
+		}
+		return nil, fmt.Errorf("segment repo: UpdateSegment: scan: %w", err)
+	}
+	model := dbS.toModel()
+	return &model, nil
+}
+
+func (r *postgresSegmentRepository) DeleteSegment(ctx context.Context, segmentID string) error {
+	result, err := r.db.ExecContext(ctx, deleteSegmentQuery, segmentID)
+	if err != nil {
+		return fmt.Errorf("segment repo: DeleteSegment: exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("segment repo: DeleteSegment: rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("segment repo: DeleteSegment: %w", apperrors.ErrSegmentNotFound)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation (23505).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
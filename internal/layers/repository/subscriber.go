@@ -23,19 +23,31 @@ type dbSubscriber struct {
 	NewsletterID     string                 `firestore:"newsletter_id"`
 	SubscriptionDate time.Time              `firestore:"subscription_date"`
 	Status           models.SubscriberStatus `firestore:"status"`
-	UnsubscribeToken string                 `firestore:"unsubscribe_token,omitempty"`
+	Name             string                 `firestore:"name,omitempty"`
+	Frequency        string                 `firestore:"frequency,omitempty"` // Missing on subscribers created before digests
+	PausedUntil      *time.Time             `firestore:"paused_until,omitempty"`
+	Attributes       map[string]string      `firestore:"attributes,omitempty"`
+	Tags             []string               `firestore:"tags,omitempty"`
 	// ID is the Firestore document ID and is not stored as a field in the document.
 }
 
 // toDomain converts a dbSubscriber (and its Firestore document ID) to a models.Subscriber.
 func (dbS *dbSubscriber) toDomain(docID string) models.Subscriber {
+	frequency := models.DeliveryFrequency(dbS.Frequency)
+	if frequency == "" {
+		frequency = models.DeliveryFrequencyImmediate
+	}
 	return models.Subscriber{
 		ID:               docID,
 		Email:            dbS.Email,
 		NewsletterID:     dbS.NewsletterID,
 		SubscriptionDate: dbS.SubscriptionDate,
 		Status:           dbS.Status,
-		UnsubscribeToken: dbS.UnsubscribeToken,
+		Name:             dbS.Name,
+		Frequency:        frequency,
+		PausedUntil:      dbS.PausedUntil,
+		Attributes:       dbS.Attributes,
+		Tags:             dbS.Tags,
 	}
 }
 
@@ -47,21 +59,39 @@ func fromDomain(s models.Subscriber) map[string]interface{} {
 		"newsletter_id":     s.NewsletterID,
 		"subscription_date": s.SubscriptionDate,
 		"status":            s.Status,
-		"unsubscribe_token": s.UnsubscribeToken,
+		"name":              s.Name,
+		"frequency":         string(s.Frequency),
+		"paused_until":      s.PausedUntil,
+		"attributes":        s.Attributes,
+		"tags":              s.Tags,
 	}
 }
 
 // SubscriberRepository defines the interface for subscriber data persistence.
 type SubscriberRepository interface {
 	CreateSubscriber(ctx context.Context, subscriber models.Subscriber) (string, error)
+	GetSubscriberByID(ctx context.Context, subscriberID string) (*models.Subscriber, error)
 	GetSubscriberByEmailAndNewsletterID(ctx context.Context, email string, newsletterID string) (*models.Subscriber, error)
 	ListSubscribersByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Subscriber, int, error)
 	ListActiveSubscribersByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Subscriber, int, error)
 	GetAllActiveSubscribersByNewsletterID(ctx context.Context, newsletterID string) ([]models.Subscriber, error)
 	UpdateSubscriberStatus(ctx context.Context, subscriberID string, status models.SubscriberStatus) error
-	UpdateSubscriberUnsubscribeToken(ctx context.Context, subscriberID string, newToken string) error
+	// GetSubscriberByUnsubscribeToken finds the subscriber of an unsubscribe link sent before the links carried
+	// signed tokens, when a random token was stored with each subscriber.
 	GetSubscriberByUnsubscribeToken(ctx context.Context, token string) (*models.Subscriber, error)
 	DeleteAllSubscribersByNewsletterID(ctx context.Context, newsletterID string) error
+	// MarkSubscribersBouncedByEmail sets every active subscription of the address, across all newsletters,
+	// to bounced and returns how many were changed.
+	MarkSubscribersBouncedByEmail(ctx context.Context, email string) (int, error)
+	// ListSubscribersByEmail returns the subscriptions of the address to every newsletter, in any status.
+	ListSubscribersByEmail(ctx context.Context, email string) ([]models.Subscriber, error)
+	// UpdateSubscriberPreferences replaces the display name, delivery frequency and pause of a subscription.
+	// A nil pausedUntil resumes delivery.
+	UpdateSubscriberPreferences(ctx context.Context, subscriberID string, name string, frequency models.DeliveryFrequency, pausedUntil *time.Time) error
+	// UpdateSubscriberProfile replaces the name, custom attributes and tags of a subscription.
+	UpdateSubscriberProfile(ctx context.Context, subscriberID string, name string, attributes map[string]string, tags []string) error
+	// UpdateSubscriberTags replaces the tags of a subscription.
+	UpdateSubscriberTags(ctx context.Context, subscriberID string, tags []string) error
 }
 
 // firestoreSubscriberRepository implements SubscriberRepository using Firestore.
@@ -84,6 +114,23 @@ func (r *firestoreSubscriberRepository) CreateSubscriber(ctx context.Context, su
 	return docRef.ID, nil
 }
 
+func (r *firestoreSubscriberRepository) GetSubscriberByID(ctx context.Context, subscriberID string) (*models.Subscriber, error) {
+	doc, err := r.client.Collection(subscribersCollection).Doc(subscriberID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("subscriber repo: GetSubscriberByID: %w: id %s", apperrors.ErrSubscriberNotFound, subscriberID)
+		}
+		return nil, fmt.Errorf("subscriber repo: GetSubscriberByID: get: %w: %v", apperrors.ErrInternal, err)
+	}
+
+	var dbSub dbSubscriber
+	if errData := doc.DataTo(&dbSub); errData != nil {
+		return nil, fmt.Errorf("subscriber repo: GetSubscriberByID: decode: %w: %v", apperrors.ErrInternal, errData)
+	}
+	modelSub := dbSub.toDomain(doc.Ref.ID)
+	return &modelSub, nil
+}
+
 func (r *firestoreSubscriberRepository) GetSubscriberByEmailAndNewsletterID(ctx context.Context, email string, newsletterID string) (*models.Subscriber, error) {
 	iter := r.client.Collection(subscribersCollection).
 		Where("email", "==", email).
@@ -277,22 +324,6 @@ func (r *firestoreSubscriberRepository) UpdateSubscriberStatus(ctx context.Conte
 	return nil
 }
 
-func (r *firestoreSubscriberRepository) UpdateSubscriberUnsubscribeToken(ctx context.Context, subscriberID string, newToken string) error {
-	updates := []firestore.Update{
-		{Path: "unsubscribe_token", Value: newToken},
-		// {Path: "updated_at", Value: firestore.ServerTimestamp}, // If an updated_at field were present
-	}
-	_, err := r.client.Collection(subscribersCollection).Doc(subscriberID).Update(ctx, updates)
-	if err != nil {
-		st, _ := status.FromError(err)
-		if st.Code() == codes.NotFound {
-			return fmt.Errorf("subscriber repo: UpdateSubscriberUnsubscribeToken: %w: id %s", apperrors.ErrSubscriberNotFound, subscriberID)
-		}
-		return fmt.Errorf("subscriber repo: UpdateSubscriberUnsubscribeToken: %w: %v", apperrors.ErrInternal, err)
-	}
-	return nil
-}
-
 func (r *firestoreSubscriberRepository) GetSubscriberByUnsubscribeToken(ctx context.Context, token string) (*models.Subscriber, error) {
 	iter := r.client.Collection(subscribersCollection).
 		Where("unsubscribe_token", "==", token).
@@ -334,3 +365,95 @@ func (r *firestoreSubscriberRepository) DeleteAllSubscribersByNewsletterID(ctx c
 	}
 	return nil
 }
+
+func (r *firestoreSubscriberRepository) MarkSubscribersBouncedByEmail(ctx context.Context, email string) (int, error) {
+	iter := r.client.Collection(subscribersCollection).
+		Where("email", "==", email).
+		Where("status", "==", models.SubscriberStatusActive).
+		Documents(ctx)
+	defer iter.Stop()
+
+	updated := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return updated, fmt.Errorf("subscriber repo: MarkSubscribersBouncedByEmail: iterate: %w: %v", apperrors.ErrInternal, err)
+		}
+		if _, err := doc.Ref.Update(ctx, []firestore.Update{{Path: "status", Value: models.SubscriberStatusBounced}}); err != nil {
+			return updated, fmt.Errorf("subscriber repo: MarkSubscribersBouncedByEmail: update: %w: %v", apperrors.ErrInternal, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func (r *firestoreSubscriberRepository) ListSubscribersByEmail(ctx context.Context, email string) ([]models.Subscriber, error) {
+	iter := r.client.Collection(subscribersCollection).Where("email", "==", email).Documents(ctx)
+	defer iter.Stop()
+
+	var subscribers []models.Subscriber
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("subscriber repo: ListSubscribersByEmail: iterate: %w: %v", apperrors.ErrInternal, err)
+		}
+		var dbSub dbSubscriber
+		if errData := doc.DataTo(&dbSub); errData != nil {
+			return nil, fmt.Errorf("subscriber repo: ListSubscribersByEmail: decode: %w: %v", apperrors.ErrInternal, errData)
+		}
+		subscribers = append(subscribers, dbSub.toDomain(doc.Ref.ID))
+	}
+	return subscribers, nil
+}
+
+func (r *firestoreSubscriberRepository) UpdateSubscriberPreferences(ctx context.Context, subscriberID string, name string, frequency models.DeliveryFrequency, pausedUntil *time.Time) error {
+	updates := []firestore.Update{
+		{Path: "name", Value: name},
+		{Path: "frequency", Value: string(frequency)},
+		{Path: "paused_until", Value: pausedUntil},
+	}
+	_, err := r.client.Collection(subscribersCollection).Doc(subscriberID).Update(ctx, updates)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("subscriber repo: UpdateSubscriberPreferences: %w: id %s", apperrors.ErrSubscriberNotFound, subscriberID)
+		}
+		return fmt.Errorf("subscriber repo: UpdateSubscriberPreferences: %w: %v", apperrors.ErrInternal, err)
+	}
+	return nil
+}
+
+func (r *firestoreSubscriberRepository) UpdateSubscriberProfile(ctx context.Context, subscriberID string, name string, attributes map[string]string, tags []string) error {
+	updates := []firestore.Update{
+		{Path: "name", Value: name},
+		{Path: "attributes", Value: attributes},
+		{Path: "tags", Value: tags},
+	}
+	_, err := r.client.Collection(subscribersCollection).Doc(subscriberID).Update(ctx, updates)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("subscriber repo: UpdateSubscriberProfile: %w: id %s", apperrors.ErrSubscriberNotFound, subscriberID)
+		}
+		return fmt.Errorf("subscriber repo: UpdateSubscriberProfile: %w: %v", apperrors.ErrInternal, err)
+	}
+	return nil
+}
+
+func (r *firestoreSubscriberRepository) UpdateSubscriberTags(ctx context.Context, subscriberID string, tags []string) error {
+	updates := []firestore.Update{
+		{Path: "tags", Value: tags},
+	}
+	_, err := r.client.Collection(subscribersCollection).Doc(subscriberID).Update(ctx, updates)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("subscriber repo: UpdateSubscriberTags: %w: id %s", apperrors.ErrSubscriberNotFound, subscriberID)
+		}
+		return fmt.Errorf("subscriber repo: UpdateSubscriberTags: %w: %v", apperrors.ErrInternal, err)
+	}
+	return nil
+}
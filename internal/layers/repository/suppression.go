@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed queries/suppression/create.sql
+var createSuppressionQuery string
+
+//go:embed queries/suppression/get_by_id.sql
+var getSuppressionByIDQuery string
+
+//go:embed queries/suppression/list.sql
+var listSuppressionsQuery string
+
+//go:embed queries/suppression/count.sql
+var countSuppressionsQuery string
+
+//go:embed queries/suppression/update.sql
+var updateSuppressionQuery string
+
+//go:embed queries/suppression/delete.sql
+var deleteSuppressionQuery string
+
+//go:embed queries/suppression/filter_emails.sql
+var filterSuppressedEmailsQuery string
+
+// dbSuppression is an internal struct used for scanning database rows.
+type dbSuppression struct {
+	ID        string
+	EditorID  sql.NullString
+	Type      string
+	Value     string
+	Reason    string
+	Source    string
+	CreatedBy sql.NullString
+	CreatedAt time.Time
+}
+
+func (dbS *dbSuppression) scanDest() []interface{} {
+	return []interface{}{&dbS.ID, &dbS.EditorID, &dbS.Type, &dbS.Value, &dbS.Reason, &dbS.Source, &dbS.CreatedBy, &dbS.CreatedAt}
+}
+
+// toModel converts a dbSuppression to a models.Suppression domain object.
+func (dbS *dbSuppression) toModel() models.Suppression {
+	scope := models.SuppressionScopeGlobal
+	if dbS.EditorID.Valid {
+		scope = models.SuppressionScopeEditor
+	}
+	return models.Suppression{
+		ID:        dbS.ID,
+		Scope:     scope,
+		EditorID:  dbS.EditorID.String,
+		Type:      models.SuppressionType(dbS.Type),
+		Value:     dbS.Value,
+		Reason:    dbS.Reason,
+		Source:    models.SuppressionSource(dbS.Source),
+		CreatedBy: dbS.CreatedBy.String,
+		CreatedAt: dbS.CreatedAt,
+	}
+}
+
+// SuppressionRepository defines the interface for the suppression list.
+type SuppressionRepository interface {
+	// CreateSuppression adds an entry and fills in its ID and CreatedAt. An entry with an empty EditorID is global.
+	// Adding a value that is already on the same list returns ErrConflict.
+	CreateSuppression(ctx context.Context, suppression *models.Suppression) error
+	GetSuppressionByID(ctx context.Context, suppressionID string) (*models.Suppression, error)
+	// ListSuppressions returns a page of the editor's entries, or of the global entries for an empty editorID.
+	ListSuppressions(ctx context.Context, editorID string, limit int, offset int) ([]models.Suppression, int, error)
+	// UpdateSuppression changes the reason and source of an entry; nil fields are left unchanged.
+	UpdateSuppression(ctx context.Context, suppressionID string, reason *string, source *models.SuppressionSource) (*models.Suppression, error)
+	DeleteSuppression(ctx context.Context, suppressionID string) error
+	// FilterSuppressedEmails returns those of the lower-case addresses that must not receive the newsletter:
+	// addresses suppressed globally or by the newsletter's editor, directly or through their domain.
+	FilterSuppressedEmails(ctx context.Context, newsletterID string, emails []string) ([]string, error)
+}
+
+type postgresSuppressionRepository struct {
+	db *sql.DB
+}
+
+// NewSuppressionRepository creates a new instance of postgresSuppressionRepository.
+func NewSuppressionRepository(db *sql.DB) SuppressionRepository {
+	return &postgresSuppressionRepository{db: db}
+}
+
+// nullableID maps an empty ID to NULL.
+func nullableID(id string) sql.NullString {
+	return sql.NullString{String: id, Valid: id != ""}
+}
+
+func (r *postgresSuppressionRepository) CreateSuppression(ctx context.Context, suppression *models.Suppression) error {
+	err := r.db.QueryRowContext(ctx, createSuppressionQuery,
+		nullableID(suppression.EditorID), string(suppression.Type), suppression.Value, suppression.Reason,
+		string(suppression.Source), nullableID(suppression.CreatedBy),
+	).Scan(&suppression.ID, &suppression.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // 23505 is unique_violation
+			return fmt.Errorf("suppression repo: CreateSuppression: %w: %s is already suppressed", apperrors.ErrConflict, suppression.Value)
+		}
+		return fmt.Errorf("suppression repo: CreateSuppression: scan: %w", err)
+	}
+	suppression.Scope = models.SuppressionScopeGlobal
+	if suppression.EditorID != "" {
+		suppression.Scope = models.SuppressionScopeEditor
+	}
+	return nil
+}
+
+func (r *postgresSuppressionRepository) GetSuppressionByID(ctx context.Context, suppressionID string) (*models.Suppression, error) {
+	var dbS dbSuppression
+	err := r.db.QueryRowContext(ctx, getSuppressionByIDQuery, suppressionID).Scan(dbS.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("suppression repo: GetSuppressionByID: %w", apperrors.ErrSuppressionNotFound)
+		}
+		return nil, fmt.Errorf("suppression repo: GetSuppressionByID: scan: %w", err)
+	}
+	model := dbS.toModel()
+	return &model, nil
+}
+
+func (r *postgresSuppressionRepository) ListSuppressions(ctx context.Context, editorID string, limit int, offset int) ([]models.Suppression, int, error) {
+	rows, err := r.db.QueryContext(ctx, listSuppressionsQuery, nullableID(editorID), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("suppression repo: ListSuppressions: query: %w", err)
+	}
+	defer rows.Close()
+
+	suppressions := make([]models.Suppression, 0)
+	for rows.Next() {
+		var dbS dbSuppression
+		if err := rows.Scan(dbS.scanDest()...); err != nil {
+			return nil, 0, fmt.Errorf("suppression repo: ListSuppressions: scan: %w", err)
+		}
+		suppressions = append(suppressions, dbS.toModel())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("suppression repo: ListSuppressions: rows error: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countSuppressionsQuery, nullableID(editorID)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("suppression repo: ListSuppressions: count query: %w", err)
+	}
+	return suppressions, total, nil
+}
+
+func (r *postgresSuppressionRepository) UpdateSuppression(ctx context.Context, suppressionID string, reason *string, source *models.SuppressionSource) (*models.Suppression, error) {
+	var sourceArg sql.NullString
+	if source != nil {
+		sourceArg = sql.NullString{String: string(*source), Valid: true}
+	}
+
+	var dbS dbSuppression
+	err := r.db.QueryRowContext(ctx, updateSuppressionQuery, suppressionID, reason, sourceArg).Scan(dbS.scanDest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("suppression repo: UpdateSuppression: %w", apperrors.ErrSuppressionNotFound)
+		}
+		return nil, fmt.Errorf("suppression repo: UpdateSuppression: scan: %w", err)
+	}
+	model := dbS.toModel()
+	return &model, nil
+}
+
+func (r *postgresSuppressionRepository) DeleteSuppression(ctx context.Context, suppressionID string) error {
+	result, err := r.db.ExecContext(ctx, deleteSuppressionQuery, suppressionID)
+	if err != nil {
+		return fmt.Errorf("suppression repo: DeleteSuppression: exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("suppression repo: DeleteSuppression: rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("suppression repo: DeleteSuppression: %w", apperrors.ErrSuppressionNotFound)
+	}
+	return nil
+}
+
+func (r *postgresSuppressionRepository) FilterSuppressedEmails(ctx context.Context, newsletterID string, emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, filterSuppressedEmailsQuery, newsletterID, pq.Array(emails))
+	if err != nil {
+		return nil, fmt.Errorf("suppression repo: FilterSuppressedEmails: query: %w", err)
+	}
+	defer rows.Close()
+
+	var suppressed []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("suppression repo: FilterSuppressedEmails: scan: %w", err)
+		}
+		suppressed = append(suppressed, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("suppression repo: FilterSuppressedEmails: rows error: %w", err)
+	}
+	return suppressed, nil
+}
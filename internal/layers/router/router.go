@@ -10,12 +10,16 @@ import (
 	"database/sql"
 
 	editorHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/editor"
+	mailboxHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/mailbox"
 	newsletterHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/newsletter"
 	postHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/post"
 	subscriberHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/subscriber"
+	suppressionHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/suppression"
+	webhookHandler "github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/handler/webhook"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
 )
 
 // RouterDependencies holds only essential dependencies for the newsletter service.
@@ -25,11 +29,19 @@ type RouterDependencies struct {
 	NewsletterService service.NewsletterServiceInterface
 	SubscriberService service.SubscriberServiceInterface
 	PublishingService service.PublishingServiceInterface
+	EmailTemplateService service.EmailTemplateServiceInterface
+	SegmentService    service.SegmentServiceInterface
 	EditorService     service.EditorServiceInterface
 	PasswordResetSvc  service.PasswordResetService
 	EditorRepo        repository.EditorRepository
 	Logger            *zap.SugaredLogger
 	CORSAllowedOrigins []string
+	DevMailbox        *service.MemoryMailbox // Optional; serves /dev/mailbox when set, only in development
+	BounceService     service.BounceServiceInterface
+	ComplaintService  service.ComplaintServiceInterface
+	BounceWebhookSecret string // Optional; the bounce and complaint webhooks are only served when set
+	SuppressionService service.SuppressionServiceInterface
+	AdminEmails       []string // Editors allowed to manage the global suppression list
 }
 
 // NewRouter creates a simple Chi router for the newsletter service.
@@ -76,6 +88,15 @@ func NewRouter(deps RouterDependencies) *chi.Mux {
 		http.ServeFile(w, r, "static/swagger/index.html")
 	})
 
+	// Development mailbox viewer
+	if deps.DevMailbox != nil {
+		r.Route("/dev/mailbox", func(r chi.Router) {
+			r.Get("/", mailboxHandler.ListMessagesHandler(deps.DevMailbox))
+			r.Delete("/", mailboxHandler.ClearMessagesHandler(deps.DevMailbox))
+			r.Get("/{messageID}", mailboxHandler.GetMessageHandler(deps.DevMailbox))
+		})
+	}
+
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		// Public routes
@@ -84,6 +105,17 @@ func NewRouter(deps RouterDependencies) *chi.Mux {
 		r.Post("/editor/password-reset", editorHandler.PasswordResetRequestHandler(deps.PasswordResetSvc))
 		r.Post("/newsletters/{newsletterID}/subscribe", subscriberHandler.SubscribeHandler(deps.SubscriberService))
 		r.Get("/subscriptions/unsubscribe", subscriberHandler.UnsubscribeHandler(deps.SubscriberService))
+		r.Post("/subscriptions/unsubscribe", subscriberHandler.OneClickUnsubscribeHandler(deps.SubscriberService))
+		r.Get("/subscriptions/confirm", subscriberHandler.ConfirmPageHandler())
+		r.Post("/subscriptions/confirm", subscriberHandler.ConfirmSubscriptionHandler(deps.SubscriberService))
+		r.Get("/subscriptions/preferences", subscriberHandler.PreferencesHandler(deps.SubscriberService))
+		r.Post("/subscriptions/preferences", subscriberHandler.UpdatePreferencesHandler(deps.SubscriberService))
+
+		// Webhooks authenticated with a shared secret
+		if deps.BounceWebhookSecret != "" {
+			r.Post("/webhooks/bounces", webhookHandler.InboundBounceHandler(deps.BounceService, deps.BounceWebhookSecret))
+			r.Post("/webhooks/complaints", webhookHandler.InboundComplaintHandler(deps.ComplaintService, deps.BounceWebhookSecret))
+		}
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
@@ -96,7 +128,25 @@ func NewRouter(deps RouterDependencies) *chi.Mux {
 				r.Get("/{newsletterID}", newsletterHandler.GetByIDHandler(deps.NewsletterService))
 				r.Patch("/{newsletterID}", newsletterHandler.UpdateHandler(deps.NewsletterService))
 				r.Delete("/{newsletterID}", newsletterHandler.DeleteHandler(deps.NewsletterService))
+				r.Put("/{newsletterID}/branding", newsletterHandler.UpdateBrandingHandler(deps.NewsletterService))
+				r.Put("/{newsletterID}/subscriber-fields", newsletterHandler.UpdateSubscriberFieldsHandler(deps.NewsletterService))
 				r.Get("/{newsletterID}/subscribers", subscriberHandler.ListSubscribersHandler(deps.SubscriberService))
+				r.Post("/{newsletterID}/subscribers/import", subscriberHandler.ImportSubscribersHandler(deps.SubscriberService))
+				r.Put("/{newsletterID}/subscribers/{subscriberID}/tags", subscriberHandler.UpdateSubscriberTagsHandler(deps.SubscriberService))
+				r.Get("/{newsletterID}/complaints", newsletterHandler.ComplaintStatsHandler(deps.ComplaintService))
+
+				// Email templates
+				r.Get("/{newsletterID}/email-templates", newsletterHandler.ListEmailTemplatesHandler(deps.EmailTemplateService))
+				r.Put("/{newsletterID}/email-templates/{templateName}", newsletterHandler.SetEmailTemplateHandler(deps.EmailTemplateService))
+				r.Delete("/{newsletterID}/email-templates/{templateName}", newsletterHandler.ResetEmailTemplateHandler(deps.EmailTemplateService))
+
+				// Segments
+				r.Get("/{newsletterID}/segments", newsletterHandler.ListSegmentsHandler(deps.SegmentService))
+				r.Post("/{newsletterID}/segments", newsletterHandler.CreateSegmentHandler(deps.SegmentService))
+				r.Get("/{newsletterID}/segments/{segmentID}", newsletterHandler.GetSegmentHandler(deps.SegmentService))
+				r.Put("/{newsletterID}/segments/{segmentID}", newsletterHandler.UpdateSegmentHandler(deps.SegmentService))
+				r.Delete("/{newsletterID}/segments/{segmentID}", newsletterHandler.DeleteSegmentHandler(deps.SegmentService))
+				r.Get("/{newsletterID}/segments/{segmentID}/count", newsletterHandler.CountSegmentSubscribersHandler(deps.SegmentService))
 
 				// Posts
 				r.Post("/{newsletterID}/posts", postHandler.CreatePostHandler(deps.NewsletterService))
@@ -109,9 +159,38 @@ func NewRouter(deps RouterDependencies) *chi.Mux {
 				r.Put("/", postHandler.UpdatePostHandler(deps.NewsletterService))
 				r.Delete("/", postHandler.DeletePostHandler(deps.NewsletterService))
 				r.Post("/publish", postHandler.PublishPostHandler(deps.PublishingService))
+				r.Post("/unpublish", postHandler.UnpublishPostHandler(deps.PublishingService))
+				r.Post("/test-send", postHandler.TestSendPostHandler(deps.PublishingService))
+				r.Get("/preview", postHandler.PreviewPostHandler(deps.PublishingService))
+				r.Delete("/schedule", postHandler.CancelScheduleHandler(deps.NewsletterService))
+				r.Post("/archive", postHandler.ArchivePostHandler(deps.NewsletterService))
+				r.Get("/deliveries", postHandler.ListDeliveriesHandler(deps.PublishingService))
+				r.Get("/deliveries/summary", postHandler.DeliverySummaryHandler(deps.PublishingService))
+			})
+
+			// Suppression list of the editor's newsletters
+			r.Route("/suppressions", func(r chi.Router) {
+				mountSuppressionRoutes(r, deps.SuppressionService, models.SuppressionScopeEditor)
+			})
+
+			// Instance-wide administration
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(middleware.AdminMiddleware(deps.AdminEmails))
+				r.Route("/suppressions", func(r chi.Router) {
+					mountSuppressionRoutes(r, deps.SuppressionService, models.SuppressionScopeGlobal)
+				})
 			})
 		})
 	})
 
 	return r
 }
+
+// mountSuppressionRoutes serves the CRUD endpoints of one suppression list.
+func mountSuppressionRoutes(r chi.Router, svc service.SuppressionServiceInterface, scope models.SuppressionScope) {
+	r.Get("/", suppressionHandler.ListHandler(svc, scope))
+	r.Post("/", suppressionHandler.CreateHandler(svc, scope))
+	r.Get("/{suppressionID}", suppressionHandler.GetHandler(svc, scope))
+	r.Patch("/{suppressionID}", suppressionHandler.UpdateHandler(svc, scope))
+	r.Delete("/{suppressionID}", suppressionHandler.DeleteHandler(svc, scope))
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// ErrNotFeedbackReport is returned for messages that carry no abuse feedback report.
+var ErrNotFeedbackReport = fmt.Errorf("%w: message is not an abuse feedback report", apperrors.ErrValidation)
+
+// FeedbackReport is an abuse report in the Abuse Reporting Format (RFC 5965), sent by a mailbox provider's
+// feedback loop when a recipient marks a message as spam.
+type FeedbackReport struct {
+	FeedbackType   string // abuse, fraud, virus, other, not-spam or auth-failure
+	UserAgent      string // The feedback loop that generated the report
+	OriginalRcptTo string // Lower-case recipient of the reported message; often redacted by the provider
+	// OriginalHeaders are the headers of the reported message, from its message/rfc822 or text/rfc822-headers part.
+	// They are empty when the report does not include the message.
+	OriginalHeaders mail.Header
+}
+
+// IsComplaint reports whether the report is a recipient's complaint, as opposed to a not-spam report
+// or an authentication failure report that says nothing about the recipient.
+func (r *FeedbackReport) IsComplaint() bool {
+	switch r.FeedbackType {
+	case "not-spam", "auth-failure":
+		return false
+	}
+	return true
+}
+
+// ParseFeedbackReport reads an ARF report: a multipart/report message with a message/feedback-report part,
+// usually followed by the reported message. It returns ErrNotFeedbackReport for any other message.
+func ParseFeedbackReport(r io.Reader) (*FeedbackReport, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFeedbackReport, err)
+	}
+
+	parts, err := findReportParts(textproto.MIMEHeader(msg.Header), msg.Body,
+		"message/feedback-report", "message/rfc822", "text/rfc822-headers")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFeedbackReport, err)
+	}
+	fields, ok := parts["message/feedback-report"]
+	if !ok {
+		return nil, ErrNotFeedbackReport
+	}
+
+	content := bytes.ReplaceAll(fields, []byte("\r\n"), []byte("\n"))
+	header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(bytes.TrimLeft(content, "\n")))).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: malformed feedback report: %v", ErrNotFeedbackReport, err)
+	}
+	feedbackType := strings.ToLower(strings.TrimSpace(header.Get("Feedback-Type")))
+	if feedbackType == "" {
+		return nil, fmt.Errorf("%w: feedback report has no Feedback-Type", ErrNotFeedbackReport)
+	}
+
+	report := &FeedbackReport{
+		FeedbackType:    feedbackType,
+		UserAgent:       strings.TrimSpace(header.Get("User-Agent")),
+		OriginalRcptTo:  strings.ToLower(strings.Trim(strings.TrimSpace(header.Get("Original-Rcpt-To")), "<>")),
+		OriginalHeaders: mail.Header{},
+	}
+
+	original, ok := parts["message/rfc822"]
+	if !ok {
+		original = parts["text/rfc822-headers"]
+	}
+	if len(original) > 0 {
+		// text/rfc822-headers parts may end without the blank line that terminates a header block.
+		if originalMsg, err := mail.ReadMessage(bytes.NewReader(original)); err == nil {
+			report.OriginalHeaders = originalMsg.Header
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testARF is an abuse report as sent by a feedback loop, quoting the reported issue with its subscription header.
+const testARF = "From: Feedback Loop <fbl@mail.example.net>\r\n" +
+	"To: abuse@example.com\r\n" +
+	"Subject: FW: Issue 1\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=feedback-report; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"\r\n" +
+	"This is an email abuse report.\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/feedback-report\r\n" +
+	"\r\n" +
+	"Feedback-Type: abuse\r\n" +
+	"User-Agent: ExampleFBL/1.0\r\n" +
+	"Version: 1\r\n" +
+	"Original-Rcpt-To: <Reader@Example.net>\r\n" +
+	"Source-IP: 192.0.2.1\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"From: news@example.com\r\n" +
+	"To: reader@example.net\r\n" +
+	"Subject: Issue 1\r\n" +
+	"List-Unsubscribe: <https://example.com/api/subscriptions/unsubscribe?token=tok-1>\r\n" +
+	"X-Newsletter-Subscription: nl-1/sub-1\r\n" +
+	"\r\n" +
+	"Issue body\r\n" +
+	"--b1--\r\n"
+
+func TestParseFeedbackReport(t *testing.T) {
+	report, err := ParseFeedbackReport(strings.NewReader(testARF))
+	require.NoError(t, err)
+
+	assert.Equal(t, "abuse", report.FeedbackType)
+	assert.Equal(t, "ExampleFBL/1.0", report.UserAgent)
+	assert.Equal(t, "reader@example.net", report.OriginalRcptTo)
+	assert.Equal(t, "nl-1/sub-1", report.OriginalHeaders.Get(SubscriptionHeader))
+	assert.True(t, report.IsComplaint())
+}
+
+func TestParseFeedbackReport_HeadersOnly(t *testing.T) {
+	message := strings.Replace(testARF, "Content-Type: message/rfc822", "Content-Type: text/rfc822-headers", 1)
+	message = strings.Replace(message, "\r\nIssue body\r\n", "", 1)
+
+	report, err := ParseFeedbackReport(strings.NewReader(message))
+	require.NoError(t, err)
+	assert.Equal(t, "nl-1/sub-1", report.OriginalHeaders.Get(SubscriptionHeader))
+}
+
+func TestParseFeedbackReport_NotSpam(t *testing.T) {
+	report, err := ParseFeedbackReport(strings.NewReader(strings.Replace(testARF, "Feedback-Type: abuse", "Feedback-Type: not-spam", 1)))
+	require.NoError(t, err)
+	assert.False(t, report.IsComplaint())
+}
+
+func TestParseFeedbackReport_NotAReport(t *testing.T) {
+	tests := map[string]string{
+		"delivery status notification": testDSN,
+		"plain message":                "From: someone@example.org\r\nSubject: Hello\r\n\r\nHi\r\n",
+		"not a message":                "",
+	}
+	for name, message := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseFeedbackReport(strings.NewReader(message))
+			assert.ErrorIs(t, err, ErrNotFeedbackReport)
+		})
+	}
+}
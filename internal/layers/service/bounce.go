@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// DefaultHardBounceLimit is the number of hard bounces after which an address is suppressed.
+const DefaultHardBounceLimit = 1
+
+// BounceServiceInterface defines the operations for processing bounced deliveries.
+type BounceServiceInterface interface {
+	// ProcessBounceMessage parses a delivery status notification, records its bounces and suppresses
+	// addresses that reached the hard bounce limit. Messages that are not delivery status notifications
+	// return ErrNotDeliveryStatusReport.
+	ProcessBounceMessage(ctx context.Context, message io.Reader) (*BounceResult, error)
+}
+
+// BounceResult is the outcome of processing one delivery status notification.
+type BounceResult struct {
+	Bounces    []models.Bounce `json:"bounces"`
+	Suppressed []string        `json:"suppressed"` // Addresses marked as bounced by this notification
+}
+
+// BounceService records bounces and stops sending to addresses that keep hard-bouncing.
+type BounceService struct {
+	bounceRepo      repository.BounceRepository
+	subscriberRepo  repository.SubscriberRepository
+	hardBounceLimit int
+}
+
+// NewBounceService creates a new BounceService. A hardBounceLimit below one uses DefaultHardBounceLimit.
+func NewBounceService(
+	bounceRepo repository.BounceRepository,
+	subscriberRepo repository.SubscriberRepository,
+	hardBounceLimit int,
+) BounceServiceInterface {
+	if hardBounceLimit < 1 {
+		hardBounceLimit = DefaultHardBounceLimit
+	}
+	return &BounceService{
+		bounceRepo:      bounceRepo,
+		subscriberRepo:  subscriberRepo,
+		hardBounceLimit: hardBounceLimit,
+	}
+}
+
+func (s *BounceService) ProcessBounceMessage(ctx context.Context, message io.Reader) (*BounceResult, error) {
+	report, err := ParseDeliveryStatusReport(message)
+	if err != nil {
+		return nil, fmt.Errorf("service: ProcessBounceMessage: %w", err)
+	}
+
+	result := &BounceResult{Bounces: []models.Bounce{}, Suppressed: []string{}}
+	for _, recipient := range report.Recipients {
+		bounceType, ok := recipient.BounceType()
+		if !ok {
+			continue
+		}
+
+		bounce := models.Bounce{
+			Email:          recipient.FinalRecipient,
+			Type:           bounceType,
+			Action:         recipient.Action,
+			Status:         recipient.Status,
+			DiagnosticCode: recipient.DiagnosticCode,
+			ReportingMTA:   report.ReportingMTA,
+		}
+		if err := s.bounceRepo.CreateBounce(ctx, &bounce); err != nil {
+			return nil, fmt.Errorf("service: ProcessBounceMessage: recording bounce: %w", err)
+		}
+		result.Bounces = append(result.Bounces, bounce)
+
+		if bounceType != models.BounceTypeHard {
+			continue
+		}
+		hardBounces, err := s.bounceRepo.CountHardBounces(ctx, bounce.Email)
+		if err != nil {
+			return nil, fmt.Errorf("service: ProcessBounceMessage: counting hard bounces: %w", err)
+		}
+		if hardBounces < s.hardBounceLimit {
+			continue
+		}
+		suppressed, err := s.subscriberRepo.MarkSubscribersBouncedByEmail(ctx, bounce.Email)
+		if err != nil {
+			return nil, fmt.Errorf("service: ProcessBounceMessage: suppressing address: %w", err)
+		}
+		if suppressed > 0 {
+			result.Suppressed = append(result.Suppressed, bounce.Email)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockBounceRepository mocks the bounce repository
+type MockBounceRepository struct {
+	mock.Mock
+}
+
+func (m *MockBounceRepository) CreateBounce(ctx context.Context, bounce *models.Bounce) error {
+	args := m.Called(ctx, bounce)
+	return args.Error(0)
+}
+
+func (m *MockBounceRepository) CountHardBounces(ctx context.Context, email string) (int, error) {
+	args := m.Called(ctx, email)
+	return args.Int(0), args.Error(1)
+}
+
+// MockSubscriberRepository mocks the subscriber repository
+type MockSubscriberRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriberRepository) CreateSubscriber(ctx context.Context, subscriber models.Subscriber) (string, error) {
+	args := m.Called(ctx, subscriber)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) GetSubscriberByID(ctx context.Context, subscriberID string) (*models.Subscriber, error) {
+	args := m.Called(ctx, subscriberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) GetSubscriberByEmailAndNewsletterID(ctx context.Context, email string, newsletterID string) (*models.Subscriber, error) {
+	args := m.Called(ctx, email, newsletterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) ListSubscribersByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Subscriber, int, error) {
+	args := m.Called(ctx, newsletterID, limit, offset)
+	return args.Get(0).([]models.Subscriber), args.Int(1), args.Error(2)
+}
+
+func (m *MockSubscriberRepository) ListActiveSubscribersByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Subscriber, int, error) {
+	args := m.Called(ctx, newsletterID, limit, offset)
+	return args.Get(0).([]models.Subscriber), args.Int(1), args.Error(2)
+}
+
+func (m *MockSubscriberRepository) GetAllActiveSubscribersByNewsletterID(ctx context.Context, newsletterID string) ([]models.Subscriber, error) {
+	args := m.Called(ctx, newsletterID)
+	return args.Get(0).([]models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) UpdateSubscriberStatus(ctx context.Context, subscriberID string, status models.SubscriberStatus) error {
+	args := m.Called(ctx, subscriberID, status)
+	return args.Error(0)
+}
+
+func (m *MockSubscriberRepository) GetSubscriberByUnsubscribeToken(ctx context.Context, token string) (*models.Subscriber, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) DeleteAllSubscribersByNewsletterID(ctx context.Context, newsletterID string) error {
+	args := m.Called(ctx, newsletterID)
+	return args.Error(0)
+}
+
+func (m *MockSubscriberRepository) MarkSubscribersBouncedByEmail(ctx context.Context, email string) (int, error) {
+	args := m.Called(ctx, email)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) ListSubscribersByEmail(ctx context.Context, email string) ([]models.Subscriber, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).([]models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberRepository) UpdateSubscriberPreferences(ctx context.Context, subscriberID string, name string, frequency models.DeliveryFrequency, pausedUntil *time.Time) error {
+	args := m.Called(ctx, subscriberID, name, frequency, pausedUntil)
+	return args.Error(0)
+}
+
+func (m *MockSubscriberRepository) UpdateSubscriberProfile(ctx context.Context, subscriberID string, name string, attributes map[string]string, tags []string) error {
+	args := m.Called(ctx, subscriberID, name, attributes, tags)
+	return args.Error(0)
+}
+
+func (m *MockSubscriberRepository) UpdateSubscriberTags(ctx context.Context, subscriberID string, tags []string) error {
+	args := m.Called(ctx, subscriberID, tags)
+	return args.Error(0)
+}
+
+func TestBounceService_ProcessBounceMessage(t *testing.T) {
+	tests := []struct {
+		name               string
+		hardBounceLimit    int
+		hardBounces        int
+		expectSuppressed   []string
+		expectSuppressCall bool
+	}{
+		{name: "first hard bounce suppresses with the default limit", hardBounceLimit: 0, hardBounces: 1,
+			expectSuppressed: []string{"missing@example.org"}, expectSuppressCall: true},
+		{name: "below the limit only records", hardBounceLimit: 3, hardBounces: 2,
+			expectSuppressed: []string{}, expectSuppressCall: false},
+		{name: "limit reached suppresses", hardBounceLimit: 3, hardBounces: 3,
+			expectSuppressed: []string{"missing@example.org"}, expectSuppressCall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounceRepo := &MockBounceRepository{}
+			subscriberRepo := &MockSubscriberRepository{}
+
+			bounceRepo.On("CreateBounce", mock.Anything, mock.MatchedBy(func(b *models.Bounce) bool {
+				return b.Email == "missing@example.org" && b.Type == models.BounceTypeHard && b.Status == "5.1.1" &&
+					b.ReportingMTA == "googlemail.com"
+			})).Return(nil).Once()
+			bounceRepo.On("CreateBounce", mock.Anything, mock.MatchedBy(func(b *models.Bounce) bool {
+				return b.Email == "full@example.org" && b.Type == models.BounceTypeSoft
+			})).Return(nil).Once()
+			bounceRepo.On("CountHardBounces", mock.Anything, "missing@example.org").Return(tt.hardBounces, nil)
+			if tt.expectSuppressCall {
+				subscriberRepo.On("MarkSubscribersBouncedByEmail", mock.Anything, "missing@example.org").Return(2, nil)
+			}
+
+			svc := NewBounceService(bounceRepo, subscriberRepo, tt.hardBounceLimit)
+			result, err := svc.ProcessBounceMessage(context.Background(), strings.NewReader(testDSN))
+			require.NoError(t, err)
+
+			assert.Len(t, result.Bounces, 2, "the delivered recipient is not recorded")
+			assert.Equal(t, tt.expectSuppressed, result.Suppressed)
+			bounceRepo.AssertExpectations(t)
+			subscriberRepo.AssertExpectations(t)
+			bounceRepo.AssertNotCalled(t, "CountHardBounces", mock.Anything, "full@example.org")
+		})
+	}
+}
+
+func TestBounceService_ProcessBounceMessage_Errors(t *testing.T) {
+	t.Run("not a delivery status notification", func(t *testing.T) {
+		svc := NewBounceService(&MockBounceRepository{}, &MockSubscriberRepository{}, 1)
+		_, err := svc.ProcessBounceMessage(context.Background(), strings.NewReader("Subject: Out of office\r\n\r\nBack soon\r\n"))
+		assert.ErrorIs(t, err, ErrNotDeliveryStatusReport)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		bounceRepo := &MockBounceRepository{}
+		bounceRepo.On("CreateBounce", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+		svc := NewBounceService(bounceRepo, &MockSubscriberRepository{}, 1)
+		_, err := svc.ProcessBounceMessage(context.Background(), strings.NewReader(testDSN))
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotDeliveryStatusReport)
+	})
+}
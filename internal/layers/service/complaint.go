@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// ComplaintRateThreshold is the complaint rate above which mailbox providers start filtering a sender's mail as spam.
+const ComplaintRateThreshold = 0.001
+
+// ComplaintServiceInterface defines the operations for processing spam complaints.
+type ComplaintServiceInterface interface {
+	// ProcessComplaintMessage parses an ARF report, traces it back to the subscription it is about, records
+	// the complaint and marks the subscriber as complained. Messages that are not ARF reports return ErrNotFeedbackReport.
+	ProcessComplaintMessage(ctx context.Context, message io.Reader) (*ComplaintResult, error)
+	// GetComplaintStats returns the complaint rate of the editor's newsletter for issues sent since the given time.
+	GetComplaintStats(ctx context.Context, editorID string, newsletterID string, since time.Time) (*models.ComplaintStats, error)
+}
+
+// ComplaintResult is the outcome of processing one ARF report.
+type ComplaintResult struct {
+	Matched   bool              `json:"matched"`             // The report was traced back to a subscription
+	Complaint *models.Complaint `json:"complaint,omitempty"` // Nil when unmatched, not a complaint, or already recorded
+}
+
+type complaintService struct {
+	complaintRepo     repository.ComplaintRepository
+	deliveryRepo      repository.DeliveryRepository
+	subscriberRepo    repository.SubscriberRepository
+	newsletterService NewsletterServiceInterface // For newsletter ownership checks
+	tokenSigner       *SubscriptionTokenSigner   // To read the subscription from unsubscribe tokens
+}
+
+// NewComplaintService creates a new complaint service.
+func NewComplaintService(
+	complaintRepo repository.ComplaintRepository,
+	deliveryRepo repository.DeliveryRepository,
+	subscriberRepo repository.SubscriberRepository,
+	newsletterService NewsletterServiceInterface,
+	tokenSigner *SubscriptionTokenSigner,
+) ComplaintServiceInterface {
+	return &complaintService{
+		complaintRepo:     complaintRepo,
+		deliveryRepo:      deliveryRepo,
+		subscriberRepo:    subscriberRepo,
+		newsletterService: newsletterService,
+		tokenSigner:       tokenSigner,
+	}
+}
+
+func (s *complaintService) ProcessComplaintMessage(ctx context.Context, message io.Reader) (*ComplaintResult, error) {
+	report, err := ParseFeedbackReport(message)
+	if err != nil {
+		return nil, fmt.Errorf("service: ProcessComplaintMessage: %w", err)
+	}
+	if !report.IsComplaint() {
+		return &ComplaintResult{}, nil
+	}
+
+	subscriber, err := s.findComplainingSubscriber(ctx, report)
+	if err != nil {
+		return nil, fmt.Errorf("service: ProcessComplaintMessage: %w", err)
+	}
+	if subscriber == nil {
+		return &ComplaintResult{}, nil
+	}
+	// Feedback loops may report the same message more than once; count the subscriber only once.
+	if subscriber.Status == models.SubscriberStatusComplained {
+		return &ComplaintResult{Matched: true}, nil
+	}
+
+	complaint := &models.Complaint{
+		NewsletterID: subscriber.NewsletterID,
+		SubscriberID: subscriber.ID,
+		Email:        subscriber.Email,
+		FeedbackType: report.FeedbackType,
+		UserAgent:    report.UserAgent,
+	}
+	if err := s.complaintRepo.CreateComplaint(ctx, complaint); err != nil {
+		return nil, fmt.Errorf("service: ProcessComplaintMessage: recording complaint: %w", err)
+	}
+	if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, subscriber.ID, models.SubscriberStatusComplained); err != nil {
+		return nil, fmt.Errorf("service: ProcessComplaintMessage: suppressing subscriber: %w", err)
+	}
+	return &ComplaintResult{Matched: true, Complaint: complaint}, nil
+}
+
+// findComplainingSubscriber looks up the subscription a report is about through the SubscriptionHeader of the
+// reported message, falling back to the token in its List-Unsubscribe header for mail sent without it.
+// It returns nil when the report cannot be matched.
+func (s *complaintService) findComplainingSubscriber(ctx context.Context, report *FeedbackReport) (*models.Subscriber, error) {
+	if newsletterID, subscriberID, ok := ParseSubscriptionHeader(report.OriginalHeaders.Get(SubscriptionHeader)); ok {
+		if subscriber, err := s.subscriberOfNewsletter(ctx, subscriberID, newsletterID); subscriber != nil || err != nil {
+			return subscriber, err
+		}
+	}
+
+	token := unsubscribeTokenFromHeader(report.OriginalHeaders.Get("List-Unsubscribe"))
+	if token == "" {
+		return nil, nil
+	}
+	if isSignedSubscriptionToken(token) {
+		// Complaints may arrive long after the issue was sent, so expired tokens still identify the subscription.
+		claims, err := s.tokenSigner.Verify(token, TokenPurposeUnsubscribe, 0)
+		if err != nil {
+			return nil, nil
+		}
+		return s.subscriberOfNewsletter(ctx, claims.SubscriberID, claims.NewsletterID)
+	}
+	subscriber, err := s.subscriberRepo.GetSubscriberByUnsubscribeToken(ctx, token)
+	if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding subscriber by unsubscribe token: %w", err)
+	}
+	return subscriber, nil
+}
+
+// subscriberOfNewsletter returns the subscriber if it exists and belongs to the newsletter, otherwise nil.
+func (s *complaintService) subscriberOfNewsletter(ctx context.Context, subscriberID, newsletterID string) (*models.Subscriber, error) {
+	subscriber, err := s.subscriberRepo.GetSubscriberByID(ctx, subscriberID)
+	if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding subscriber: %w", err)
+	}
+	if subscriber.NewsletterID != newsletterID {
+		return nil, nil
+	}
+	return subscriber, nil
+}
+
+// unsubscribeTokenFromHeader extracts the token query parameter from a List-Unsubscribe header value such as
+// "<https://example.com/api/subscriptions/unsubscribe?token=abc>".
+func unsubscribeTokenFromHeader(value string) string {
+	for _, entry := range strings.Split(value, ",") {
+		link, err := url.Parse(strings.Trim(strings.TrimSpace(entry), "<>"))
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			continue
+		}
+		if token := link.Query().Get("token"); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+func (s *complaintService) GetComplaintStats(ctx context.Context, editorID string, newsletterID string, since time.Time) (*models.ComplaintStats, error) {
+	if _, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: GetComplaintStats: %w", err)
+	}
+
+	complaints, err := s.complaintRepo.CountComplaintsSince(ctx, newsletterID, since)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetComplaintStats: %w", err)
+	}
+	sent, err := s.deliveryRepo.CountNewsletterDeliveriesSentSince(ctx, newsletterID, since)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetComplaintStats: %w", err)
+	}
+
+	stats := &models.ComplaintStats{
+		NewsletterID: newsletterID,
+		Since:        since,
+		Sent:         sent,
+		Complaints:   complaints,
+	}
+	if sent > 0 {
+		stats.ComplaintRate = float64(complaints) / float64(sent)
+		stats.AboveThreshold = stats.ComplaintRate > ComplaintRateThreshold
+	}
+	return stats, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockComplaintRepository mocks the complaint repository
+type MockComplaintRepository struct {
+	mock.Mock
+}
+
+func (m *MockComplaintRepository) CreateComplaint(ctx context.Context, complaint *models.Complaint) error {
+	args := m.Called(ctx, complaint)
+	return args.Error(0)
+}
+
+func (m *MockComplaintRepository) CountComplaintsSince(ctx context.Context, newsletterID string, since time.Time) (int, error) {
+	args := m.Called(ctx, newsletterID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func TestComplaintService_ProcessComplaintMessage(t *testing.T) {
+	subscriber := &models.Subscriber{ID: "sub-1", Email: "reader@example.net", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}
+
+	complaintRepo := &MockComplaintRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(subscriber, nil)
+	complaintRepo.On("CreateComplaint", mock.Anything, mock.MatchedBy(func(c *models.Complaint) bool {
+		return c.NewsletterID == "nl-1" && c.SubscriberID == "sub-1" && c.Email == "reader@example.net" &&
+			c.FeedbackType == "abuse" && c.UserAgent == "ExampleFBL/1.0"
+	})).Return(nil).Once()
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-1", models.SubscriberStatusComplained).Return(nil).Once()
+
+	svc := NewComplaintService(complaintRepo, nil, subscriberRepo, nil, nil)
+	result, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(testARF))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	require.NotNil(t, result.Complaint)
+	assert.Equal(t, "sub-1", result.Complaint.SubscriberID)
+	complaintRepo.AssertExpectations(t)
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestComplaintService_ProcessComplaintMessage_AlreadyComplained(t *testing.T) {
+	subscriber := &models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusComplained}
+
+	complaintRepo := &MockComplaintRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(subscriber, nil)
+
+	svc := NewComplaintService(complaintRepo, nil, subscriberRepo, nil, nil)
+	result, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(testARF))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	assert.Nil(t, result.Complaint, "a repeated report is not counted again")
+	complaintRepo.AssertNotCalled(t, "CreateComplaint", mock.Anything, mock.Anything)
+}
+
+func TestComplaintService_ProcessComplaintMessage_FallsBackToUnsubscribeToken(t *testing.T) {
+	// The subscription header names a subscriber of another newsletter, so it is not trusted.
+	other := &models.Subscriber{ID: "sub-1", NewsletterID: "nl-2", Status: models.SubscriberStatusActive}
+	subscriber := &models.Subscriber{ID: "sub-9", Email: "reader@example.net", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}
+
+	complaintRepo := &MockComplaintRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(other, nil)
+	subscriberRepo.On("GetSubscriberByUnsubscribeToken", mock.Anything, "tok-1").Return(subscriber, nil)
+	complaintRepo.On("CreateComplaint", mock.Anything, mock.MatchedBy(func(c *models.Complaint) bool {
+		return c.SubscriberID == "sub-9"
+	})).Return(nil).Once()
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-9", models.SubscriberStatusComplained).Return(nil).Once()
+
+	svc := NewComplaintService(complaintRepo, nil, subscriberRepo, nil, nil)
+	result, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(testARF))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	complaintRepo.AssertExpectations(t)
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestComplaintService_ProcessComplaintMessage_FallsBackToSignedUnsubscribeToken(t *testing.T) {
+	signer, err := NewSubscriptionTokenSigner("k1", map[string]string{"k1": "secret"})
+	require.NoError(t, err)
+	message := strings.Replace(testARF, "token=tok-1", "token="+signer.Sign(TokenPurposeUnsubscribe, "sub-9", "nl-1"), 1)
+	subscriber := &models.Subscriber{ID: "sub-9", Email: "reader@example.net", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}
+
+	complaintRepo := &MockComplaintRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(nil, apperrors.ErrSubscriberNotFound)
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-9").Return(subscriber, nil)
+	complaintRepo.On("CreateComplaint", mock.Anything, mock.MatchedBy(func(c *models.Complaint) bool {
+		return c.SubscriberID == "sub-9"
+	})).Return(nil).Once()
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-9", models.SubscriberStatusComplained).Return(nil).Once()
+
+	svc := NewComplaintService(complaintRepo, nil, subscriberRepo, nil, signer)
+	result, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(message))
+	require.NoError(t, err)
+	assert.True(t, result.Matched)
+	subscriberRepo.AssertNotCalled(t, "GetSubscriberByUnsubscribeToken", mock.Anything, mock.Anything)
+	complaintRepo.AssertExpectations(t)
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestComplaintService_ProcessComplaintMessage_Unmatched(t *testing.T) {
+	complaintRepo := &MockComplaintRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(nil, apperrors.ErrSubscriberNotFound)
+	subscriberRepo.On("GetSubscriberByUnsubscribeToken", mock.Anything, "tok-1").Return(nil, apperrors.ErrSubscriberNotFound)
+
+	svc := NewComplaintService(complaintRepo, nil, subscriberRepo, nil, nil)
+	result, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(testARF))
+	require.NoError(t, err)
+	assert.False(t, result.Matched)
+	assert.Nil(t, result.Complaint)
+	subscriberRepo.AssertNotCalled(t, "UpdateSubscriberStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestComplaintService_ProcessComplaintMessage_NotAReport(t *testing.T) {
+	svc := NewComplaintService(&MockComplaintRepository{}, nil, &MockSubscriberRepository{}, nil, nil)
+	_, err := svc.ProcessComplaintMessage(context.Background(), strings.NewReader(testDSN))
+	assert.ErrorIs(t, err, ErrNotFeedbackReport)
+}
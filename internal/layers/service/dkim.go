@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dkimSignedHeaders are the headers covered by the signature, when present in the message.
+var dkimSignedHeaders = []string{
+	"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type",
+	"List-Unsubscribe", "List-Unsubscribe-Post",
+}
+
+// DKIMSigner adds an RFC 6376 DKIM-Signature header to outgoing messages, using relaxed/relaxed
+// canonicalization. RSA keys sign with rsa-sha256 and Ed25519 keys with ed25519-sha256 (RFC 8463).
+type DKIMSigner struct {
+	domain    string
+	selector  string
+	key       crypto.Signer
+	algorithm string
+}
+
+// NewDKIMSigner creates a signer from a PEM encoded PKCS#1 or PKCS#8 private key.
+func NewDKIMSigner(domain, selector string, privateKeyPEM []byte) (*DKIMSigner, error) {
+	if domain == "" {
+		return nil, fmt.Errorf("DKIM domain is required")
+	}
+	if selector == "" {
+		return nil, fmt.Errorf("DKIM selector is required")
+	}
+
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("DKIM private key is not PEM encoded")
+	}
+
+	var key any
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+
+	signer := &DKIMSigner{domain: domain, selector: selector}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signer.key, signer.algorithm = k, "rsa-sha256"
+	case ed25519.PrivateKey:
+		signer.key, signer.algorithm = k, "ed25519-sha256"
+	default:
+		return nil, fmt.Errorf("unsupported DKIM private key type %T", key)
+	}
+	return signer, nil
+}
+
+// Sign returns the message with a DKIM-Signature header prepended.
+// The message must use CRLF line endings, as produced by EmailMessage.Bytes.
+func (s *DKIMSigner) Sign(message []byte) ([]byte, error) {
+	headerEnd := bytes.Index(message, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return nil, errors.New("dkim: message has no header/body separator")
+	}
+	headers := splitDKIMHeaders(string(message[:headerEnd+2]))
+	body := message[headerEnd+4:]
+
+	bodyHash := sha256.Sum256(dkimRelaxedBody(body))
+
+	var signedNames []string
+	var signedHeaders strings.Builder
+	for _, name := range dkimSignedHeaders {
+		if field, ok := lastDKIMHeader(headers, name); ok {
+			signedNames = append(signedNames, strings.ToLower(name))
+			signedHeaders.WriteString(dkimRelaxedHeader(field))
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algorithm, s.domain, s.selector, time.Now().Unix(),
+		strings.Join(signedNames, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+
+	// The signature header itself is signed with an empty b= tag and without its trailing CRLF.
+	signedHeaders.WriteString(strings.TrimSuffix(dkimRelaxedHeader("DKIM-Signature: "+value+"\r\n"), "\r\n"))
+	digest := sha256.Sum256([]byte(signedHeaders.String()))
+
+	var signature []byte
+	var err error
+	if s.algorithm == "rsa-sha256" {
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	} else {
+		// Ed25519 signs the SHA-256 digest as its message (RFC 8463)
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dkim: failed to sign message: %w", err)
+	}
+
+	signed := make([]byte, 0, len(message)+len(value)+512)
+	signed = append(signed, "DKIM-Signature: "+value+base64.StdEncoding.EncodeToString(signature)+"\r\n"...)
+	signed = append(signed, message...)
+	return signed, nil
+}
+
+// splitDKIMHeaders splits a header block into fields, keeping folded continuation lines with their field.
+func splitDKIMHeaders(block string) []string {
+	var fields []string
+	for _, line := range strings.SplitAfter(block, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1] += line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields
+}
+
+// lastDKIMHeader returns the bottom-most field with the given name, which is the one verifiers pick first.
+func lastDKIMHeader(fields []string, name string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		colon := strings.IndexByte(fields[i], ':')
+		if colon > 0 && strings.EqualFold(strings.TrimRight(fields[i][:colon], " \t"), name) {
+			return fields[i], true
+		}
+	}
+	return "", false
+}
+
+// dkimRelaxedHeader applies the relaxed header canonicalization of RFC 6376 section 3.4.2.
+func dkimRelaxedHeader(field string) string {
+	colon := strings.IndexByte(field, ':')
+	name := strings.ToLower(strings.TrimRight(field[:colon], " \t"))
+	value := strings.ReplaceAll(field[colon+1:], "\r\n", "")
+	value = strings.Join(strings.FieldsFunc(value, isDKIMWhitespace), " ")
+	return name + ":" + value + "\r\n"
+}
+
+// dkimRelaxedBody applies the relaxed body canonicalization of RFC 6376 section 3.4.4.
+func dkimRelaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		// Collapse runs of whitespace and drop whitespace at the end of the line
+		var b strings.Builder
+		space := false
+		for _, r := range line {
+			if isDKIMWhitespace(r) {
+				space = true
+				continue
+			}
+			if space {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		}
+		lines[i] = b.String()
+	}
+
+	// Ignore empty lines at the end of the body
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func isDKIMWhitespace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// ErrNotDeliveryStatusReport is returned for messages that carry no delivery status notification,
+// such as auto-replies that end up in the bounce mailbox.
+var ErrNotDeliveryStatusReport = fmt.Errorf("%w: message is not a delivery status notification", apperrors.ErrValidation)
+
+// DeliveryStatusReport is a delivery status notification (RFC 3464) sent back by a mail server.
+type DeliveryStatusReport struct {
+	ReportingMTA string
+	Recipients   []RecipientStatus
+}
+
+// RecipientStatus holds the per-recipient fields of a delivery status notification.
+type RecipientStatus struct {
+	FinalRecipient    string // Lower-case address the report is about
+	OriginalRecipient string // Address as originally given, if reported
+	Action            string // failed, delayed, delivered, relayed or expanded
+	Status            string // Enhanced status code, e.g. 5.1.1
+	DiagnosticCode    string // The remote server's reply, e.g. "smtp; 550 5.1.1 No such user"
+}
+
+// BounceType classifies the recipient status. Failures with a 5.x.x status (or none) are hard bounces,
+// delays and failures with a 4.x.x status are soft. It returns false for successful deliveries.
+func (s RecipientStatus) BounceType() (models.BounceType, bool) {
+	switch s.Action {
+	case "failed":
+		if strings.HasPrefix(s.Status, "4.") {
+			return models.BounceTypeSoft, true
+		}
+		return models.BounceTypeHard, true
+	case "delayed":
+		return models.BounceTypeSoft, true
+	}
+	return "", false
+}
+
+// ParseDeliveryStatusReport reads a delivery status notification: a multipart/report message with a
+// message/delivery-status part. It returns ErrNotDeliveryStatusReport for any other message.
+func ParseDeliveryStatusReport(r io.Reader) (*DeliveryStatusReport, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotDeliveryStatusReport, err)
+	}
+
+	parts, err := findReportParts(textproto.MIMEHeader(msg.Header), msg.Body, "message/delivery-status", "message/global-delivery-status")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotDeliveryStatusReport, err)
+	}
+	fields := parts["message/delivery-status"]
+	if fields == nil {
+		fields = parts["message/global-delivery-status"]
+	}
+	if fields == nil {
+		return nil, ErrNotDeliveryStatusReport
+	}
+	return parseDeliveryStatusFields(fields)
+}
+
+// findReportParts returns the decoded bodies of the first part of each of the given media types,
+// searching nested multiparts. Media types that are not found are missing from the map.
+// Parts of the wanted types are not searched, so a quoted original message is returned whole.
+func findReportParts(header textproto.MIMEHeader, body io.Reader, mediaTypes ...string) (map[string][]byte, error) {
+	parts := make(map[string][]byte, len(mediaTypes))
+	err := collectReportParts(header, body, mediaTypes, parts)
+	return parts, err
+}
+
+// collectReportParts adds the wanted parts of one MIME entity to parts and stops once all are found.
+func collectReportParts(header textproto.MIMEHeader, body io.Reader, mediaTypes []string, parts map[string][]byte) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+
+	if slices.Contains(mediaTypes, mediaType) {
+		if _, found := parts[mediaType]; found {
+			return nil
+		}
+		switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+		case "base64":
+			body = base64.NewDecoder(base64.StdEncoding, body)
+		case "quoted-printable":
+			body = quotedprintable.NewReader(body)
+		}
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("reading %s part: %v", mediaType, err)
+		}
+		parts[mediaType] = content
+		return nil
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	for len(parts) < len(mediaTypes) {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading part: %v", err)
+		}
+		if err := collectReportParts(part.Header, part, mediaTypes, parts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDeliveryStatusFields parses the per-message field group followed by one group per recipient.
+// Groups are blocks of header-style fields separated by blank lines.
+func parseDeliveryStatusFields(content []byte) (*DeliveryStatusReport, error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(bytes.TrimLeft(content, "\n"))))
+
+	report := &DeliveryStatusReport{}
+	for group := 0; ; group++ {
+		fields, err := reader.ReadMIMEHeader()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: malformed delivery status: %v", ErrNotDeliveryStatusReport, err)
+		}
+
+		if group == 0 {
+			report.ReportingMTA = dsnFieldValue(fields.Get("Reporting-MTA"))
+		} else if recipient := dsnFieldValue(fields.Get("Final-Recipient")); recipient != "" {
+			status := strings.Fields(fields.Get("Status"))
+			rs := RecipientStatus{
+				FinalRecipient:    strings.ToLower(recipient),
+				OriginalRecipient: dsnFieldValue(fields.Get("Original-Recipient")),
+				Action:            strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+				DiagnosticCode:    strings.TrimSpace(fields.Get("Diagnostic-Code")),
+			}
+			if len(status) > 0 {
+				rs.Status = status[0]
+			}
+			report.Recipients = append(report.Recipients, rs)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if len(report.Recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipients reported", ErrNotDeliveryStatusReport)
+	}
+	return report, nil
+}
+
+// dsnFieldValue strips the type from a typed field such as "rfc822; user@example.com" or "dns; mx.example.com".
+func dsnFieldValue(value string) string {
+	if _, v, ok := strings.Cut(value, ";"); ok {
+		value = v
+	}
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
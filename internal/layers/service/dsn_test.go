@@ -0,0 +1,124 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// testDSN is a delivery status notification as sent by Gmail for an unknown recipient and a full mailbox.
+const testDSN = "From: Mail Delivery Subsystem <mailer-daemon@googlemail.com>\r\n" +
+	"To: news@example.com\r\n" +
+	"Subject: Delivery Status Notification (Failure)\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"\r\n" +
+	"Your message wasn't delivered.\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; googlemail.com\r\n" +
+	"Arrival-Date: Mon, 06 May 2024 10:00:00 -0700\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; Missing@Example.org\r\n" +
+	"Original-Recipient: rfc822;missing@example.org\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1 (bad destination mailbox address)\r\n" +
+	"Diagnostic-Code: smtp; 550-5.1.1 The email account that you tried to reach does\r\n" +
+	" not exist.\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; <full@example.org>\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; ok@example.org\r\n" +
+	"Action: delivered\r\n" +
+	"Status: 2.0.0\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"From: news@example.com\r\n" +
+	"Subject: Issue 1\r\n" +
+	"--b1--\r\n"
+
+func TestParseDeliveryStatusReport(t *testing.T) {
+	report, err := ParseDeliveryStatusReport(strings.NewReader(testDSN))
+	require.NoError(t, err)
+
+	assert.Equal(t, "googlemail.com", report.ReportingMTA)
+	require.Len(t, report.Recipients, 3)
+
+	failed := report.Recipients[0]
+	assert.Equal(t, "missing@example.org", failed.FinalRecipient)
+	assert.Equal(t, "missing@example.org", failed.OriginalRecipient)
+	assert.Equal(t, "failed", failed.Action)
+	assert.Equal(t, "5.1.1", failed.Status)
+	assert.Equal(t, "smtp; 550-5.1.1 The email account that you tried to reach does not exist.", failed.DiagnosticCode)
+	bounceType, ok := failed.BounceType()
+	assert.True(t, ok)
+	assert.Equal(t, models.BounceTypeHard, bounceType)
+
+	assert.Equal(t, "full@example.org", report.Recipients[1].FinalRecipient)
+	bounceType, ok = report.Recipients[1].BounceType()
+	assert.True(t, ok)
+	assert.Equal(t, models.BounceTypeSoft, bounceType)
+
+	_, ok = report.Recipients[2].BounceType()
+	assert.False(t, ok, "successful deliveries are not bounces")
+}
+
+func TestParseDeliveryStatusReport_NestedBase64(t *testing.T) {
+	// base64 of "Reporting-MTA: dns; mx.example.net\r\n\r\nFinal-Recipient: rfc822; gone@example.net\r\nAction: failed\r\n"
+	message := "Content-Type: multipart/mixed; boundary=outer\n" +
+		"\n" +
+		"--outer\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=inner\n" +
+		"\n" +
+		"--inner\n" +
+		"Content-Type: message/delivery-status\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"\n" +
+		"UmVwb3J0aW5nLU1UQTogZG5zOyBteC5leGFtcGxlLm5ldA0KDQpGaW5hbC1SZWNpcGllbnQ6IHJm\n" +
+		"YzgyMjsgZ29uZUBleGFtcGxlLm5ldA0KQWN0aW9uOiBmYWlsZWQNCg==\n" +
+		"--inner--\n" +
+		"--outer--\n"
+
+	report, err := ParseDeliveryStatusReport(strings.NewReader(message))
+	require.NoError(t, err)
+	require.Len(t, report.Recipients, 1)
+	assert.Equal(t, "mx.example.net", report.ReportingMTA)
+	assert.Equal(t, "gone@example.net", report.Recipients[0].FinalRecipient)
+
+	bounceType, ok := report.Recipients[0].BounceType()
+	assert.True(t, ok)
+	assert.Equal(t, models.BounceTypeHard, bounceType, "a failure without a status is permanent")
+}
+
+func TestParseDeliveryStatusReport_NotAReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "auto reply", message: "From: reader@example.org\r\nSubject: Out of office\r\n\r\nBack on Monday.\r\n"},
+		{name: "not a message", message: ""},
+		{
+			name: "report without recipients",
+			message: "Content-Type: multipart/report; boundary=b\r\n\r\n--b\r\n" +
+				"Content-Type: message/delivery-status\r\n\r\nReporting-MTA: dns; mx.example.net\r\n--b--\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDeliveryStatusReport(strings.NewReader(tt.message))
+			assert.ErrorIs(t, err, ErrNotDeliveryStatusReport)
+		})
+	}
+}
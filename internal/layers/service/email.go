@@ -2,130 +2,202 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"net/smtp"
+	"net/textproto"
 )
 
-// EmailService defines the interface for sending emails
+// Subjects of the emails whose subject is not taken from a post
+const (
+	OptInEmailSubject                    = "Please confirm your subscription"
+	SubscriptionConfirmationEmailSubject = "Subscription Confirmation"
+	PasswordResetEmailSubject            = "Reset your password"
+	DigestEmailSubjectFormat             = "Your %s digest" // Formatted with the newsletter name
+)
+
+// EmailService defines the interface for sending emails.
+// The HTML emails are rendered from the named templates of an EmailRenderer.
 type EmailService interface {
 	SendEmail(ctx context.Context, to, subject, body string) error
-	SendConfirmationEmailHTML(ctx context.Context, to, recipientName, unsubscribeLink string) error
-	SendNewsletterIssueHTML(ctx context.Context, to, recipientName, subject, body, unsubscribeLink string) error
+	SendOptInEmailHTML(ctx context.Context, to string, data EmailTemplateData) error
+	SendConfirmationEmailHTML(ctx context.Context, to string, data EmailTemplateData) error
+	SendWelcomeBackEmailHTML(ctx context.Context, to string, data EmailTemplateData) error
+	SendNewsletterIssueHTML(ctx context.Context, to string, data EmailTemplateData) error
+	SendDigestHTML(ctx context.Context, to string, data EmailTemplateData) error
+	SendPasswordResetEmailHTML(ctx context.Context, to string, data EmailTemplateData) error
+}
+
+// EmailProvider delivers a message through a mail transport, e.g. an SMTP relay or an HTTP API.
+// Providers report failures as *EmailDeliveryError so callers can tell transient from permanent ones.
+type EmailProvider interface {
+	// Name identifies the provider in logs and errors, e.g. "smtp".
+	Name() string
+	Send(ctx context.Context, msg EmailMessage) error
 }
 
-// GmailEmailServiceConfig holds configuration for Gmail SMTP service
-type GmailEmailServiceConfig struct {
+// EmailDeliveryError is a provider-agnostic send failure.
+type EmailDeliveryError struct {
+	Provider  string
+	Permanent bool // The message was rejected and retrying it cannot succeed
+	Err       error
+}
+
+func (e *EmailDeliveryError) Error() string {
+	kind := "transient"
+	if e.Permanent {
+		kind = "permanent"
+	}
+	return fmt.Sprintf("%s: %s delivery failure: %v", e.Provider, kind, e.Err)
+}
+
+func (e *EmailDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanentEmailError reports whether a send error is a permanent rejection that should not be retried.
+// A message that cannot be built is permanent as well, and SMTP 5xx replies are permanent whether or not a
+// provider classified them. Transient failures, network errors and timeouts are all considered retryable.
+func IsPermanentEmailError(err error) bool {
+	if errors.Is(err, ErrInvalidEmailMessage) {
+		return true
+	}
+	var deliveryErr *EmailDeliveryError
+	if errors.As(err, &deliveryErr) {
+		return deliveryErr.Permanent
+	}
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 500 && protoErr.Code < 600
+	}
+	return false
+}
+
+// ProviderEmailServiceConfig holds configuration for ProviderEmailService
+type ProviderEmailServiceConfig struct {
 	From     string
-	Password string
-	SMTPHost string
-	SMTPPort string
+	Renderer EmailRenderer
+	Provider EmailProvider
 }
 
-// GmailEmailService implements EmailService using Gmail SMTP
-type GmailEmailService struct {
-	config GmailEmailServiceConfig
+// ProviderEmailService implements EmailService by rendering emails and handing them to an EmailProvider
+type ProviderEmailService struct {
+	config ProviderEmailServiceConfig
 	logger *log.Logger
 }
 
-// NewGmailEmailService creates a new Gmail email service
-func NewGmailEmailService(config GmailEmailServiceConfig, logger *log.Logger) (*GmailEmailService, error) {
+// NewProviderEmailService creates a new email service sending through the given provider
+func NewProviderEmailService(config ProviderEmailServiceConfig, logger *log.Logger) (*ProviderEmailService, error) {
 	if config.From == "" {
 		return nil, fmt.Errorf("email from address is required")
 	}
-	if config.Password == "" {
-		return nil, fmt.Errorf("email password is required")
+	if config.Renderer == nil {
+		return nil, fmt.Errorf("email renderer is required")
 	}
-	if config.SMTPHost == "" {
-		return nil, fmt.Errorf("SMTP host is required")
-	}
-	if config.SMTPPort == "" {
-		return nil, fmt.Errorf("SMTP port is required")
+	if config.Provider == nil {
+		return nil, fmt.Errorf("email provider is required")
 	}
 
-	return &GmailEmailService{
+	return &ProviderEmailService{
 		config: config,
 		logger: logger,
 	}, nil
 }
 
-// SendEmail sends an email using Gmail SMTP
-func (s *GmailEmailService) SendEmail(ctx context.Context, to, subject, body string) error {
-	// Check context for cancellation
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
+// SendEmail sends a plain-text email
+func (s *ProviderEmailService) SendEmail(ctx context.Context, to, subject, body string) error {
+	return s.send(ctx, EmailMessage{From: s.config.From, To: to, Subject: subject, TextBody: body})
+}
+
+// SendOptInEmailHTML asks a double opt-in subscriber to confirm through Links.Confirm, rendered from the opt-in template
+func (s *ProviderEmailService) SendOptInEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	return s.sendTemplatedEmail(ctx, to, OptInEmailSubject, EmailTemplateOptIn, data)
+}
+
+// SendConfirmationEmailHTML sends a subscription confirmation email rendered from the confirmation template
+func (s *ProviderEmailService) SendConfirmationEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	return s.sendTemplatedEmail(ctx, to, SubscriptionConfirmationEmailSubject, EmailTemplateConfirmation, data)
+}
+
+// SendWelcomeBackEmailHTML sends an email to a returning subscriber rendered from the welcome back template
+func (s *ProviderEmailService) SendWelcomeBackEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	return s.sendTemplatedEmail(ctx, to, WelcomeBackEmailSubject, EmailTemplateWelcomeBack, data)
+}
+
+// SendNewsletterIssueHTML sends a newsletter issue rendered from the issue template. The post title is the subject.
+// The unsubscribe link is also advertised in the List-Unsubscribe headers, so mail clients can offer one-click unsubscribe.
+// Emails to a subscriber carry the SubscriptionHeader, so spam complaints can be traced back to the subscription.
+func (s *ProviderEmailService) SendNewsletterIssueHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	msg, err := s.renderEmail(ctx, to, data.Post.Title, EmailTemplateIssue, data)
+	if err != nil {
+		return err
 	}
-	
-	auth := smtp.PlainAuth("", s.config.From, s.config.Password, s.config.SMTPHost)
-	
-	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", to, subject, body)
-	
-	addr := fmt.Sprintf("%s:%s", s.config.SMTPHost, s.config.SMTPPort)
-	
-	if err := smtp.SendMail(addr, auth, s.config.From, []string{to}, []byte(message)); err != nil {
-		s.logger.Printf("Failed to send email to %s: %v", to, err)
-		return fmt.Errorf("failed to send email: %w", err)
+	msg.ListUnsubscribe = data.Links.Unsubscribe
+	if data.Newsletter.ID != "" && data.Subscriber.ID != "" {
+		msg.Subscription = FormatSubscriptionHeader(data.Newsletter.ID, data.Subscriber.ID)
 	}
-	
-	s.logger.Printf("Email sent successfully to %s", to)
-	return nil
+	return s.send(ctx, msg)
+}
+
+// SendDigestHTML sends the posts in data.Posts as one digest rendered from the digest template.
+// Like an issue, it carries the List-Unsubscribe and subscription headers.
+func (s *ProviderEmailService) SendDigestHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	msg, err := s.renderEmail(ctx, to, fmt.Sprintf(DigestEmailSubjectFormat, data.Newsletter.Name), EmailTemplateDigest, data)
+	if err != nil {
+		return err
+	}
+	msg.ListUnsubscribe = data.Links.Unsubscribe
+	if data.Newsletter.ID != "" && data.Subscriber.ID != "" {
+		msg.Subscription = FormatSubscriptionHeader(data.Newsletter.ID, data.Subscriber.ID)
+	}
+	return s.send(ctx, msg)
+}
+
+// SendPasswordResetEmailHTML sends an editor a password reset link rendered from the password reset template
+func (s *ProviderEmailService) SendPasswordResetEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	return s.sendTemplatedEmail(ctx, to, PasswordResetEmailSubject, EmailTemplatePasswordReset, data)
 }
 
-// SendConfirmationEmailHTML sends a subscription confirmation email with HTML content
-func (s *GmailEmailService) SendConfirmationEmailHTML(ctx context.Context, to, recipientName, unsubscribeLink string) error {
-	subject := "Subscription Confirmation"
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<body>
-	<h1>Welcome, %s!</h1>
-	<p>Thank you for subscribing to our newsletter.</p>
-	<p>You can unsubscribe at any time by clicking <a href="%s">here</a>.</p>
-</body>
-</html>`, recipientName, unsubscribeLink)
-	
-	return s.sendHTMLEmail(ctx, to, subject, htmlBody)
-}
-
-// SendNewsletterIssueHTML sends a newsletter issue with HTML content
-func (s *GmailEmailService) SendNewsletterIssueHTML(ctx context.Context, to, recipientName, subject, body, unsubscribeLink string) error {
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<body>
-	<h1>%s</h1>
-	<p>Dear %s,</p>
-	<div>%s</div>
-	<hr>
-	<p><small><a href="%s">Unsubscribe</a></small></p>
-</body>
-</html>`, subject, recipientName, body, unsubscribeLink)
-	
-	return s.sendHTMLEmail(ctx, to, subject, htmlBody)
-}
-
-// sendHTMLEmail sends an HTML email using Gmail SMTP
-func (s *GmailEmailService) sendHTMLEmail(ctx context.Context, to, subject, htmlBody string) error {
+// sendTemplatedEmail renders the named template and sends the result as an HTML email with a plain-text alternative.
+func (s *ProviderEmailService) sendTemplatedEmail(ctx context.Context, to, subject, templateName string, data EmailTemplateData) error {
+	msg, err := s.renderEmail(ctx, to, subject, templateName, data)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, msg)
+}
+
+// renderEmail renders the named template into a message.
+// The newsletter name, when there is one, is used as the sender's display name.
+func (s *ProviderEmailService) renderEmail(ctx context.Context, to, subject, templateName string, data EmailTemplateData) (EmailMessage, error) {
+	htmlBody, err := s.config.Renderer.Render(ctx, templateName, data)
+	if err != nil {
+		return EmailMessage{}, fmt.Errorf("failed to render %s email: %w", templateName, err)
+	}
+
+	return EmailMessage{
+		FromName: data.Newsletter.Name,
+		From:     s.config.From,
+		To:       to,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+// send hands the message to the provider
+func (s *ProviderEmailService) send(ctx context.Context, msg EmailMessage) error {
 	// Check context for cancellation
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
-	
-	auth := smtp.PlainAuth("", s.config.From, s.config.Password, s.config.SMTPHost)
-	
-	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s", to, subject, htmlBody)
-	
-	addr := fmt.Sprintf("%s:%s", s.config.SMTPHost, s.config.SMTPPort)
-	
-	if err := smtp.SendMail(addr, auth, s.config.From, []string{to}, []byte(message)); err != nil {
-		s.logger.Printf("Failed to send HTML email to %s: %v", to, err)
-		return fmt.Errorf("failed to send HTML email: %w", err)
+
+	if err := s.config.Provider.Send(ctx, msg); err != nil {
+		s.logger.Printf("Failed to send email to %s via %s: %v", msg.To, s.config.Provider.Name(), err)
+		return fmt.Errorf("failed to send email: %w", err)
 	}
-	
-	s.logger.Printf("HTML email sent successfully to %s", to)
+
+	s.logger.Printf("Email sent successfully to %s via %s", msg.To, s.config.Provider.Name())
 	return nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultHTTPEmailTimeout bounds a single API call when no HTTP client is configured.
+const defaultHTTPEmailTimeout = 30 * time.Second
+
+// HTTPEmailProviderConfig holds configuration for HTTPEmailProvider
+type HTTPEmailProviderConfig struct {
+	Endpoint string       // URL the messages are POSTed to
+	APIKey   string       // Optional; sent as a bearer token
+	Client   *http.Client // Optional; defaults to a client with a 30 second timeout
+}
+
+// HTTPEmailProvider implements EmailProvider for SES/SendGrid-style HTTP APIs that accept one JSON message
+// per POST. The endpoint can be pointed at a local stand-in during development.
+type HTTPEmailProvider struct {
+	config HTTPEmailProviderConfig
+}
+
+// httpEmailRequest is the JSON body of a send request.
+type httpEmailRequest struct {
+	From    httpEmailAddress   `json:"from"`
+	To      []httpEmailAddress `json:"to"`
+	Subject string             `json:"subject"`
+	Text    string             `json:"text"`
+	HTML    string             `json:"html,omitempty"`
+	Headers map[string]string  `json:"headers,omitempty"`
+}
+
+type httpEmailAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// NewHTTPEmailProvider creates a new HTTP API provider
+func NewHTTPEmailProvider(config HTTPEmailProviderConfig) (*HTTPEmailProvider, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("email API endpoint must be an absolute http(s) URL")
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: defaultHTTPEmailTimeout}
+	}
+	return &HTTPEmailProvider{config: config}, nil
+}
+
+// Name identifies the provider
+func (p *HTTPEmailProvider) Name() string {
+	return "http"
+}
+
+// Send POSTs the message as JSON. 4xx responses are permanent, except 408 and 429 which are retried
+// along with 5xx responses and network failures.
+func (p *HTTPEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	from, to, err := msg.Addresses()
+	if err != nil {
+		return err
+	}
+
+	payload := httpEmailRequest{
+		From:    httpEmailAddress{Email: from.Address, Name: from.Name},
+		To:      []httpEmailAddress{{Email: to.Address}},
+		Subject: msg.Subject,
+		Text:    msg.PlainText(),
+		HTML:    msg.HTMLBody,
+	}
+	if msg.ListUnsubscribe != "" || msg.Subscription != "" {
+		payload.Headers = map[string]string{}
+	}
+	if msg.ListUnsubscribe != "" {
+		payload.Headers["List-Unsubscribe"] = "<" + msg.ListUnsubscribe + ">"
+		payload.Headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	}
+	if msg.Subscription != "" {
+		payload.Headers[SubscriptionHeader] = msg.Subscription
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode email request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create email request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	resp, err := p.config.Client.Do(req)
+	if err != nil {
+		return &EmailDeliveryError{Provider: p.Name(), Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	permanent := resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests
+	return &EmailDeliveryError{
+		Provider:  p.Name(),
+		Permanent: permanent,
+		Err:       fmt.Errorf("API responded %d: %s", resp.StatusCode, strings.TrimSpace(string(detail))),
+	}
+}
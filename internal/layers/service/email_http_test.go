@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPEmailProvider_Send(t *testing.T) {
+	var received httpEmailRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPEmailProvider(HTTPEmailProviderConfig{Endpoint: server.URL, APIKey: "key"})
+	require.NoError(t, err)
+
+	err = provider.Send(context.Background(), EmailMessage{
+		FromName:        "Tech Weekly",
+		From:            "news@example.com",
+		To:              "reader@example.org",
+		Subject:         "Issue",
+		HTMLBody:        `<p>Read <a href="https://example.com/post">more</a></p>`,
+		ListUnsubscribe: "https://example.com/unsubscribe?token=abc",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer key", authorization)
+	assert.Equal(t, httpEmailAddress{Email: "news@example.com", Name: "Tech Weekly"}, received.From)
+	assert.Equal(t, []httpEmailAddress{{Email: "reader@example.org"}}, received.To)
+	assert.Equal(t, "Issue", received.Subject)
+	assert.Equal(t, "Read more [1]\n\nLinks:\n[1] https://example.com/post\n", received.Text)
+	assert.Contains(t, received.HTML, "https://example.com/post")
+	assert.Equal(t, "<https://example.com/unsubscribe?token=abc>", received.Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", received.Headers["List-Unsubscribe-Post"])
+}
+
+func TestHTTPEmailProvider_Send_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: http.StatusBadRequest, permanent: true},
+		{status: http.StatusUnprocessableEntity, permanent: true},
+		{status: http.StatusRequestTimeout, permanent: false},
+		{status: http.StatusTooManyRequests, permanent: false},
+		{status: http.StatusInternalServerError, permanent: false},
+		{status: http.StatusServiceUnavailable, permanent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", tt.status)
+			}))
+			defer server.Close()
+
+			provider, err := NewHTTPEmailProvider(HTTPEmailProviderConfig{Endpoint: server.URL})
+			require.NoError(t, err)
+
+			err = provider.Send(context.Background(), testSMTPMessage("reader@example.org"))
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, IsPermanentEmailError(err))
+			assert.ErrorContains(t, err, "nope")
+		})
+	}
+}
+
+func TestHTTPEmailProvider_Send_NetworkErrorIsTransient(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	provider, err := NewHTTPEmailProvider(HTTPEmailProviderConfig{Endpoint: endpoint})
+	require.NoError(t, err)
+
+	err = provider.Send(context.Background(), testSMTPMessage("reader@example.org"))
+	require.Error(t, err)
+	assert.False(t, IsPermanentEmailError(err))
+}
+
+func TestNewHTTPEmailProvider_InvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:8025", "ftp://example.com/send"} {
+		_, err := NewHTTPEmailProvider(HTTPEmailProviderConfig{Endpoint: endpoint})
+		assert.Error(t, err, endpoint)
+	}
+}
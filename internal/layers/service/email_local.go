@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Formats of FileEmailProvider
+const (
+	LocalEmailFormatFile    = "file"    // One .eml file per message
+	LocalEmailFormatMaildir = "maildir" // A Maildir, which most mail clients can open
+	LocalEmailFormatMbox    = "mbox"    // A single mbox file, appended to
+)
+
+// unsafeFileNameChars matches characters that are not kept when a recipient is used in a file name.
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+// FileEmailProvider implements EmailProvider by writing every message to a directory instead of sending it.
+// It is meant for local development, where no mail server or credentials are available.
+type FileEmailProvider struct {
+	dir     string
+	format  string
+	dkim    *DKIMSigner
+	mu      sync.Mutex // Serializes appends to the mbox file
+	counter atomic.Uint64
+}
+
+// NewFileEmailProvider creates the directory, and the Maildir subdirectories when needed, and returns the provider.
+func NewFileEmailProvider(dir, format string, dkim *DKIMSigner) (*FileEmailProvider, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("email directory is required")
+	}
+
+	subdirs := []string{""}
+	switch format {
+	case LocalEmailFormatFile, LocalEmailFormatMbox:
+	case LocalEmailFormatMaildir:
+		subdirs = []string{"tmp", "new", "cur"}
+	default:
+		return nil, fmt.Errorf("unsupported local email format %q, expected file, maildir or mbox", format)
+	}
+	for _, subdir := range subdirs {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create email directory: %w", err)
+		}
+	}
+
+	return &FileEmailProvider{dir: dir, format: format, dkim: dkim}, nil
+}
+
+// Name identifies the provider
+func (p *FileEmailProvider) Name() string {
+	return p.format
+}
+
+// Dir returns the directory the messages are written to
+func (p *FileEmailProvider) Dir() string {
+	return p.dir
+}
+
+// Send writes the encoded message in the configured format
+func (p *FileEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	from, to, err := msg.Addresses()
+	if err != nil {
+		return err
+	}
+	message, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if p.dkim != nil {
+		if message, err = p.dkim.Sign(message); err != nil {
+			return fmt.Errorf("failed to sign email: %w", err)
+		}
+	}
+
+	now := time.Now()
+	switch p.format {
+	case LocalEmailFormatMaildir:
+		err = p.writeMaildir(message, now)
+	case LocalEmailFormatMbox:
+		err = p.appendMbox(from.Address, message, now)
+	default:
+		name := fmt.Sprintf("%s-%d-%s.eml", now.UTC().Format("20060102T150405.000000000Z"), p.counter.Add(1),
+			unsafeFileNameChars.ReplaceAllString(to.Address, "_"))
+		err = writeFileAtomic(filepath.Join(p.dir, name), message)
+	}
+	if err != nil {
+		return &EmailDeliveryError{Provider: p.Name(), Err: err}
+	}
+	return nil
+}
+
+// writeMaildir delivers the message as described by the Maildir specification:
+// it is written to tmp/ under a unique name and then moved to new/.
+func (p *FileEmailProvider) writeMaildir(message []byte, now time.Time) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	name := fmt.Sprintf("%d.M%dP%dQ%d.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), p.counter.Add(1),
+		unsafeFileNameChars.ReplaceAllString(hostname, "_"))
+
+	tmpPath := filepath.Join(p.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, message, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(p.dir, "new", name))
+}
+
+// appendMbox appends the message to the "mbox" file of the directory, in the mboxrd variant:
+// lines starting with "From " (after any ">") are quoted with ">" so they are not read as separators.
+func (p *FileEmailProvider) appendMbox(sender string, message []byte, now time.Time) error {
+	var entry bytes.Buffer
+	fmt.Fprintf(&entry, "From %s %s\n", sender, now.UTC().Format(time.ANSIC))
+	for _, line := range bytes.Split(bytes.ReplaceAll(message, []byte("\r\n"), []byte("\n")), []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			entry.WriteByte('>')
+		}
+		entry.Write(line)
+		entry.WriteByte('\n')
+	}
+	entry.WriteByte('\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(p.dir, "mbox"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(entry.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFileAtomic writes the file under a temporary name first, so readers never see a partial message.
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEmailProvider_File(t *testing.T) {
+	dir := t.TempDir()
+	provider, err := NewFileEmailProvider(dir, LocalEmailFormatFile, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Send(context.Background(), testSMTPMessage("reader@example.org")))
+	require.NoError(t, provider.Send(context.Background(), testSMTPMessage("other@example.org")))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.True(t, strings.HasSuffix(files[0], "reader@example.org.eml") || strings.HasSuffix(files[1], "reader@example.org.eml"))
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Subject: Issue\r\n")
+	assert.Contains(t, string(content), "multipart/alternative")
+}
+
+func TestFileEmailProvider_Maildir(t *testing.T) {
+	dir := t.TempDir()
+	provider, err := NewFileEmailProvider(dir, LocalEmailFormatMaildir, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Send(context.Background(), testSMTPMessage("reader@example.org")))
+
+	for _, subdir := range []string{"tmp", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, subdir))
+		require.NoError(t, err)
+		assert.Empty(t, entries, subdir)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, "new", entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "To: <reader@example.org>\r\n")
+}
+
+func TestFileEmailProvider_Mbox(t *testing.T) {
+	dir := t.TempDir()
+	provider, err := NewFileEmailProvider(dir, LocalEmailFormatMbox, nil)
+	require.NoError(t, err)
+
+	msg := EmailMessage{From: "news@example.com", To: "reader@example.org", Subject: "Issue", TextBody: "Hello\nFrom the editor\n>From quoted"}
+	require.NoError(t, provider.Send(context.Background(), msg))
+	require.NoError(t, provider.Send(context.Background(), msg))
+
+	content, err := os.ReadFile(filepath.Join(dir, "mbox"))
+	require.NoError(t, err)
+	mbox := string(content)
+
+	separators := 0
+	for _, line := range strings.Split(mbox, "\n") {
+		if strings.HasPrefix(line, "From ") {
+			separators++
+			assert.True(t, strings.HasPrefix(line, "From news@example.com "), line)
+		}
+	}
+	assert.Equal(t, 2, separators)
+	assert.Contains(t, mbox, "\n>From the editor\n")
+	assert.Contains(t, mbox, "\n>>From quoted")
+	assert.NotContains(t, mbox, "\r\n")
+}
+
+func TestNewFileEmailProvider_InvalidFormat(t *testing.T) {
+	_, err := NewFileEmailProvider(t.TempDir(), "pst", nil)
+	assert.Error(t, err)
+}
+
+func TestMemoryMailbox(t *testing.T) {
+	mailbox := NewMemoryMailbox(2)
+
+	for _, to := range []string{"a@example.org", "b@example.org", "c@example.org"} {
+		msg := testSMTPMessage(to)
+		msg.ListUnsubscribe = "https://example.com/unsubscribe?token=" + to
+		require.NoError(t, mailbox.Send(context.Background(), msg))
+	}
+
+	messages := mailbox.Messages()
+	require.Len(t, messages, 2, "the oldest message is dropped")
+	assert.Equal(t, "c@example.org", messages[0].To)
+	assert.Equal(t, "b@example.org", messages[1].To)
+	assert.Equal(t, 3, messages[0].ID)
+	assert.Equal(t, "Hello\n", messages[0].TextBody)
+	assert.Contains(t, messages[0].Raw, "List-Unsubscribe: <https://example.com/unsubscribe?token=c@example.org>")
+
+	msg, ok := mailbox.Message(2)
+	require.True(t, ok)
+	assert.Equal(t, "b@example.org", msg.To)
+	_, ok = mailbox.Message(1)
+	assert.False(t, ok)
+
+	mailbox.Clear()
+	assert.Empty(t, mailbox.Messages())
+}
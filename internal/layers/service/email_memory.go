@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryMailboxSize is the number of messages a MemoryMailbox keeps before dropping the oldest.
+const DefaultMemoryMailboxSize = 500
+
+// CapturedEmail is a message held by a MemoryMailbox.
+type CapturedEmail struct {
+	ID              int       `json:"id"`
+	ReceivedAt      time.Time `json:"received_at"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	Subject         string    `json:"subject"`
+	HTMLBody        string    `json:"html_body,omitempty"`
+	TextBody        string    `json:"text_body"`
+	ListUnsubscribe string    `json:"list_unsubscribe,omitempty"`
+	Raw             string    `json:"-"` // The encoded message as it would have been sent
+}
+
+// MemoryMailbox implements EmailProvider by keeping messages in memory, so they can be inspected through
+// the development mailbox viewer. Nothing is ever delivered.
+type MemoryMailbox struct {
+	mu       sync.RWMutex
+	size     int
+	nextID   int
+	messages []CapturedEmail
+}
+
+// NewMemoryMailbox creates a mailbox that keeps at most size messages, or DefaultMemoryMailboxSize when size is not positive.
+func NewMemoryMailbox(size int) *MemoryMailbox {
+	if size <= 0 {
+		size = DefaultMemoryMailboxSize
+	}
+	return &MemoryMailbox{size: size}
+}
+
+// Name identifies the provider
+func (m *MemoryMailbox) Name() string {
+	return "memory"
+}
+
+// Send captures the message
+func (m *MemoryMailbox) Send(ctx context.Context, msg EmailMessage) error {
+	from, to, err := msg.Addresses()
+	if err != nil {
+		return err
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	m.messages = append(m.messages, CapturedEmail{
+		ID:              m.nextID,
+		ReceivedAt:      time.Now(),
+		From:            from.String(),
+		To:              to.Address,
+		Subject:         msg.Subject,
+		HTMLBody:        msg.HTMLBody,
+		TextBody:        msg.PlainText(),
+		ListUnsubscribe: msg.ListUnsubscribe,
+		Raw:             string(raw),
+	})
+	if len(m.messages) > m.size {
+		m.messages = append([]CapturedEmail(nil), m.messages[len(m.messages)-m.size:]...)
+	}
+	return nil
+}
+
+// Messages returns the captured messages, newest first
+func (m *MemoryMailbox) Messages() []CapturedEmail {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := make([]CapturedEmail, len(m.messages))
+	for i, msg := range m.messages {
+		messages[len(m.messages)-1-i] = msg
+	}
+	return messages
+}
+
+// Message returns the captured message with the given ID
+func (m *MemoryMailbox) Message(id int) (CapturedEmail, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return msg, true
+		}
+	}
+	return CapturedEmail{}, false
+}
+
+// Clear removes all captured messages
+func (m *MemoryMailbox) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
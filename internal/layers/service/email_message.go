@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEmailMessage is returned when a message cannot be built, e.g. because of a malformed address.
+// Retrying such a message cannot succeed, so it is treated as a permanent error.
+var ErrInvalidEmailMessage = errors.New("invalid email message")
+
+// SubscriptionHeader identifies the subscription an issue email was sent for. Feedback loops quote the
+// original message in spam complaints, so the header ties a complaint to the subscriber and newsletter
+// even when the provider redacts the recipient address.
+const SubscriptionHeader = "X-Newsletter-Subscription"
+
+// FormatSubscriptionHeader returns the SubscriptionHeader value for a subscriber of a newsletter.
+func FormatSubscriptionHeader(newsletterID, subscriberID string) string {
+	return newsletterID + "/" + subscriberID
+}
+
+// ParseSubscriptionHeader splits a SubscriptionHeader value into the newsletter and subscriber IDs.
+func ParseSubscriptionHeader(value string) (newsletterID, subscriberID string, ok bool) {
+	newsletterID, subscriberID, ok = strings.Cut(strings.TrimSpace(value), "/")
+	if !ok || newsletterID == "" || subscriberID == "" || strings.Contains(subscriberID, "/") {
+		return "", "", false
+	}
+	return newsletterID, subscriberID, true
+}
+
+// EmailMessage is an outgoing email before it is encoded for transport.
+type EmailMessage struct {
+	FromName string // Display name of the sender, e.g. the newsletter name. Optional.
+	From     string
+	To       string
+	Subject  string
+	HTMLBody string // Optional; without it the message is sent as plain text only
+	TextBody string // Derived from HTMLBody when empty
+	// ListUnsubscribe is the URL that unsubscribes the recipient with an RFC 8058 one-click POST.
+	// When set, the List-Unsubscribe and List-Unsubscribe-Post headers are added.
+	ListUnsubscribe string
+	// Subscription is the SubscriptionHeader value of an issue email, see FormatSubscriptionHeader. Optional.
+	Subscription string
+}
+
+// Bytes encodes the message as an RFC 5322 message with Date, Message-ID and From headers.
+// A message with an HTML body is sent as multipart/alternative with a plain-text part first,
+// so clients that cannot or will not show HTML still get a readable email. Both parts are
+// quoted-printable encoded and non-ASCII header values are RFC 2047 encoded.
+func (m EmailMessage) Bytes() ([]byte, error) {
+	from, to, err := m.Addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate message ID: %w", err)
+	}
+
+	textBody := m.PlainText()
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", to.String())
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
+	if m.ListUnsubscribe != "" {
+		if strings.ContainsAny(m.ListUnsubscribe, "\r\n<>") {
+			return nil, fmt.Errorf("%w: list unsubscribe URL %q", ErrInvalidEmailMessage, m.ListUnsubscribe)
+		}
+		writeHeader(&buf, "List-Unsubscribe", "<"+m.ListUnsubscribe+">")
+		writeHeader(&buf, "List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	if m.Subscription != "" {
+		if strings.ContainsAny(m.Subscription, "\r\n") {
+			return nil, fmt.Errorf("%w: subscription header %q", ErrInvalidEmailMessage, m.Subscription)
+		}
+		writeHeader(&buf, SubscriptionHeader, m.Subscription)
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if m.HTMLBody == "" {
+		writeHeader(&buf, "Content-Type", "text/plain; charset=UTF-8")
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, textBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	if err := writeAlternativePart(parts, "text/plain; charset=UTF-8", textBody); err != nil {
+		return nil, err
+	}
+	if err := writeAlternativePart(parts, "text/html; charset=UTF-8", m.HTMLBody); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart body: %w", err)
+	}
+
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()}))
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// Addresses parses the sender, with its display name, and the recipient.
+// A malformed address is reported as ErrInvalidEmailMessage.
+func (m EmailMessage) Addresses() (from *mail.Address, to *mail.Address, err error) {
+	from, err = mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: from address %q: %v", ErrInvalidEmailMessage, m.From, err)
+	}
+	to, err = mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: to address %q: %v", ErrInvalidEmailMessage, m.To, err)
+	}
+	if m.FromName != "" {
+		from.Name = m.FromName
+	}
+	return from, to, nil
+}
+
+// PlainText returns the plain-text body, derived from the HTML body when none was set.
+func (m EmailMessage) PlainText() string {
+	if m.TextBody == "" && m.HTMLBody != "" {
+		return HTMLToText(m.HTMLBody)
+	}
+	return m.TextBody
+}
+
+// writeHeader writes a single header line. Values are expected to be already encoded.
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+// writeAlternativePart adds a quoted-printable encoded part to a multipart/alternative body.
+func writeAlternativePart(parts *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := parts.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create %s part: %w", contentType, err)
+	}
+	return writeQuotedPrintable(part, content)
+}
+
+// writeQuotedPrintable encodes content as quoted-printable, using CRLF line endings.
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	return nil
+}
+
+// newMessageID generates a globally unique Message-ID in the sender's domain.
+func newMessageID(fromAddress string) (string, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(fromAddress, "@"); at >= 0 && at < len(fromAddress)-1 {
+		domain = fromAddress[at+1:]
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain), nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailMessage_Bytes_Multipart(t *testing.T) {
+	msg := EmailMessage{
+		FromName: "Café Weekly",
+		From:     "news@example.com",
+		To:       "reader@example.org",
+		Subject:  "Ünïcode subject",
+		HTMLBody: `<p>Hello <a href="https://example.com/post">there</a></p>`,
+	}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	from, err := parsed.Header.AddressList("From")
+	require.NoError(t, err)
+	require.Len(t, from, 1)
+	assert.Equal(t, "Café Weekly", from[0].Name)
+	assert.Equal(t, "news@example.com", from[0].Address)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Ünïcode subject", subject)
+	assert.NotEqual(t, "Ünïcode subject", parsed.Header.Get("Subject"), "non-ASCII subject should be encoded")
+
+	_, err = parsed.Header.Date()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^<[^@]+@example\.com>$`, parsed.Header.Get("Message-ID"))
+	assert.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var contentTypes, bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "quoted-printable", part.Header.Get("Content-Transfer-Encoding"))
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, contentTypes)
+	assert.Equal(t, "Hello there [1]\r\n\r\nLinks:\r\n[1] https://example.com/post\r\n", bodies[0])
+	assert.Equal(t, msg.HTMLBody, bodies[1])
+}
+
+func TestEmailMessage_Bytes_PlainText(t *testing.T) {
+	msg := EmailMessage{From: "news@example.com", To: "reader@example.org", Subject: "Hi", TextBody: "Just text"}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=UTF-8", parsed.Header.Get("Content-Type"))
+	assert.Equal(t, "Hi", parsed.Header.Get("Subject"))
+
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	require.NoError(t, err)
+	assert.Equal(t, "Just text", string(body))
+}
+
+func TestEmailMessage_Bytes_InvalidAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  EmailMessage
+	}{
+		{name: "invalid recipient", msg: EmailMessage{From: "news@example.com", To: "not-an-address", Subject: "Hi", TextBody: "x"}},
+		{name: "header injection", msg: EmailMessage{From: "news@example.com", To: "a@example.org\r\nBcc: b@example.org", Subject: "Hi", TextBody: "x"}},
+		{name: "invalid sender", msg: EmailMessage{From: "", To: "reader@example.org", Subject: "Hi", TextBody: "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.msg.Bytes()
+			assert.ErrorIs(t, err, ErrInvalidEmailMessage)
+			assert.True(t, IsPermanentEmailError(err))
+		})
+	}
+}
+
+func TestEmailMessage_Bytes_SubjectCannotInjectHeaders(t *testing.T) {
+	msg := EmailMessage{From: "news@example.com", To: "reader@example.org", Subject: "Hi\r\nBcc: b@example.org", TextBody: "x"}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+}
+
+func TestEmailMessage_Bytes_ListUnsubscribe(t *testing.T) {
+	msg := EmailMessage{
+		From:            "news@example.com",
+		To:              "reader@example.org",
+		Subject:         "Issue",
+		HTMLBody:        "<p>Issue</p>",
+		ListUnsubscribe: "https://example.com/api/subscriptions/unsubscribe?token=abc",
+	}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "<https://example.com/api/subscriptions/unsubscribe?token=abc>", parsed.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", parsed.Header.Get("List-Unsubscribe-Post"))
+
+	msg.ListUnsubscribe = ""
+	raw, err = msg.Bytes()
+	require.NoError(t, err)
+	parsed, err = mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Header.Get("List-Unsubscribe"))
+	assert.Empty(t, parsed.Header.Get("List-Unsubscribe-Post"))
+}
+
+func TestEmailMessage_Bytes_Subscription(t *testing.T) {
+	msg := EmailMessage{
+		From:         "news@example.com",
+		To:           "reader@example.org",
+		Subject:      "Issue",
+		TextBody:     "Issue",
+		Subscription: FormatSubscriptionHeader("nl-1", "sub-1"),
+	}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	newsletterID, subscriberID, ok := ParseSubscriptionHeader(parsed.Header.Get(SubscriptionHeader))
+	require.True(t, ok)
+	assert.Equal(t, "nl-1", newsletterID)
+	assert.Equal(t, "sub-1", subscriberID)
+
+	msg.Subscription = "nl-1/sub-1\r\nBcc: b@example.org"
+	_, err = msg.Bytes()
+	assert.ErrorIs(t, err, ErrInvalidEmailMessage)
+}
+
+func TestParseSubscriptionHeader_Invalid(t *testing.T) {
+	for _, value := range []string{"", "nl-1", "/sub-1", "nl-1/", "nl-1/sub/1"} {
+		_, _, ok := ParseSubscriptionHeader(value)
+		assert.False(t, ok, value)
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+//go:embed templates/email/*.html
+var defaultEmailTemplates embed.FS
+
+// Email template names. Each has an embedded default in templates/email.
+const (
+	EmailTemplateOptIn         = "opt_in"
+	EmailTemplateConfirmation  = "confirmation"
+	EmailTemplateWelcomeBack   = "welcome_back"
+	EmailTemplateIssue         = "issue"
+	EmailTemplateDigest        = "digest"
+	EmailTemplatePasswordReset = "password_reset"
+)
+
+// OverridableEmailTemplates lists the templates a newsletter may replace with its own.
+// Password reset emails are sent to editors, not on behalf of a newsletter, so they always use the default.
+var OverridableEmailTemplates = []string{EmailTemplateOptIn, EmailTemplateConfirmation, EmailTemplateWelcomeBack, EmailTemplateIssue, EmailTemplateDigest}
+
+// IsOverridableEmailTemplate reports whether a newsletter may override the named template.
+func IsOverridableEmailTemplate(name string) bool {
+	for _, overridable := range OverridableEmailTemplates {
+		if name == overridable {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailTemplateData is the data passed to every email template.
+// Its fields are part of the contract with editor-provided templates, so only add to it.
+type EmailTemplateData struct {
+	Newsletter EmailNewsletterData
+	Post       EmailPostData
+	Posts      []EmailPostData // The posts of a digest, oldest first
+	Subscriber EmailSubscriberData
+	Links      EmailLinksData
+}
+
+// DefaultEmailAccentColor is used when a newsletter has not configured its own accent color.
+const DefaultEmailAccentColor = "#1a73e8"
+
+// EmailNewsletterData describes the newsletter an email is sent on behalf of, including its branding.
+type EmailNewsletterData struct {
+	ID             string
+	Name           string
+	Description    string
+	LogoURL        string
+	AccentColor    string // Never empty, defaults to DefaultEmailAccentColor
+	FooterText     string
+	MailingAddress string
+	// HeaderHTML and FooterHTML are editor-authored HTML and are inserted without escaping.
+	HeaderHTML template.HTML
+	FooterHTML template.HTML
+}
+
+// EmailPostData describes the post of an issue email.
+type EmailPostData struct {
+	ID    string
+	Title string
+	// Content is the editor-authored HTML of the post and is inserted without escaping.
+	Content template.HTML
+}
+
+// EmailSubscriberData describes the recipient. For password reset emails this is the editor.
+type EmailSubscriberData struct {
+	ID    string // Empty for test sends, previews and password reset emails
+	Email string
+	Name  string // The recipient's name, or the local part of their address when it is unknown
+	// FirstName and LastName split the recipient's name. Both are empty when it is unknown, so merge tags
+	// can supply their own fallback, e.g. {{ .Subscriber.FirstName | default "friend" }}.
+	FirstName  string
+	LastName   string
+	Attributes map[string]string // Custom attributes, keyed by the newsletter's subscriber fields
+}
+
+// NewEmailSubscriberData describes a recipient with the given name, which may be empty, and custom attributes.
+func NewEmailSubscriberData(email string, name string, attributes map[string]string) EmailSubscriberData {
+	subscriber := EmailSubscriberData{Email: email, Name: strings.TrimSpace(name), Attributes: attributes}
+	if subscriber.Name == "" {
+		subscriber.Name = email
+		if atIndex := strings.Index(email, "@"); atIndex > 0 {
+			subscriber.Name = email[:atIndex]
+		}
+		return subscriber
+	}
+	first, last, _ := strings.Cut(subscriber.Name, " ")
+	subscriber.FirstName = first
+	subscriber.LastName = strings.TrimSpace(last)
+	return subscriber
+}
+
+// EmailLinksData holds the links an email may point to. Unused links are empty.
+type EmailLinksData struct {
+	Unsubscribe   string
+	Confirm       string // Double opt-in confirmation link
+	Preferences   string // Subscriber preference center
+	PasswordReset string
+}
+
+// NewEmailTemplateData builds the template data for an email to the given address.
+// The recipient's name is unknown; set Subscriber with NewEmailSubscriberData for recipients who gave one.
+func NewEmailTemplateData(newsletter *models.Newsletter, email string, unsubscribeLink string) EmailTemplateData {
+	data := EmailTemplateData{
+		Newsletter: EmailNewsletterData{AccentColor: DefaultEmailAccentColor},
+		Subscriber: NewEmailSubscriberData(email, "", nil),
+		Links:      EmailLinksData{Unsubscribe: unsubscribeLink},
+	}
+	if newsletter != nil {
+		branding := newsletter.Branding
+		data.Newsletter = EmailNewsletterData{
+			ID:             newsletter.ID,
+			Name:           newsletter.Name,
+			Description:    newsletter.Description,
+			LogoURL:        branding.LogoURL,
+			AccentColor:    branding.AccentColor,
+			FooterText:     branding.FooterText,
+			MailingAddress: branding.MailingAddress,
+			HeaderHTML:     template.HTML(branding.HeaderHTML),
+			FooterHTML:     template.HTML(branding.FooterHTML),
+		}
+		if data.Newsletter.AccentColor == "" {
+			data.Newsletter.AccentColor = DefaultEmailAccentColor
+		}
+	}
+	return data
+}
+
+// NewIssueEmailTemplateData builds the template data for an issue of the post sent to the given address.
+func NewIssueEmailTemplateData(newsletter *models.Newsletter, post *models.Post, email string, unsubscribeLink string) EmailTemplateData {
+	data := NewEmailTemplateData(newsletter, email, unsubscribeLink)
+	data.Post = EmailPostData{ID: post.ID, Title: post.Title, Content: template.HTML(post.Content)}
+	return data
+}
+
+// NewDigestEmailTemplateData builds the template data for a digest of the posts sent to the given address.
+func NewDigestEmailTemplateData(newsletter *models.Newsletter, posts []*models.Post, email string, unsubscribeLink string) EmailTemplateData {
+	data := NewEmailTemplateData(newsletter, email, unsubscribeLink)
+	for _, post := range posts {
+		data.Posts = append(data.Posts, EmailPostData{ID: post.ID, Title: post.Title, Content: template.HTML(post.Content)})
+	}
+	return data
+}
+
+// EmailRenderer renders the HTML body of outgoing emails from named templates.
+type EmailRenderer interface {
+	// Render renders the named template, using the newsletter's override when it has one.
+	Render(ctx context.Context, name string, data EmailTemplateData) (string, error)
+	// DefaultTemplate returns the source of the built-in template with the given name.
+	DefaultTemplate(name string) (string, bool)
+	// ValidateTemplate checks that body parses and renders as the named template.
+	ValidateTemplate(name string, body string) error
+}
+
+// TemplateEmailRenderer renders emails with html/template. Every template is rendered inside a shared
+// layout that applies the newsletter's branding and adds the footer with the mailing address and
+// unsubscribe link, so overrides cannot leave them out.
+type TemplateEmailRenderer struct {
+	layout    *template.Template
+	defaults  map[string]*template.Template
+	sources   map[string]string
+	overrides repository.EmailTemplateRepository
+}
+
+// NewTemplateEmailRenderer parses the embedded default templates.
+// overrides may be nil, in which case only the defaults are used.
+func NewTemplateEmailRenderer(overrides repository.EmailTemplateRepository) (*TemplateEmailRenderer, error) {
+	layoutSource, err := defaultEmailTemplates.ReadFile("templates/email/layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("reading email layout: %w", err)
+	}
+	layout, err := template.New("layout").Funcs(mergeTagFuncs).Parse(string(layoutSource))
+	if err != nil {
+		return nil, fmt.Errorf("parsing email layout: %w", err)
+	}
+
+	r := &TemplateEmailRenderer{
+		layout:    layout,
+		defaults:  make(map[string]*template.Template),
+		sources:   make(map[string]string),
+		overrides: overrides,
+	}
+	for _, name := range []string{EmailTemplateOptIn, EmailTemplateConfirmation, EmailTemplateWelcomeBack, EmailTemplateIssue, EmailTemplateDigest, EmailTemplatePasswordReset} {
+		source, err := defaultEmailTemplates.ReadFile("templates/email/" + name + ".html")
+		if err != nil {
+			return nil, fmt.Errorf("reading email template %s: %w", name, err)
+		}
+		tmpl, err := r.parse(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("parsing email template %s: %w", name, err)
+		}
+		r.defaults[name] = tmpl
+		r.sources[name] = string(source)
+	}
+	return r, nil
+}
+
+// Render renders the named template. An override that fails to render falls back to the default,
+// so a broken override never stops an email from going out. Merge tags in the posts are rendered first.
+func (r *TemplateEmailRenderer) Render(ctx context.Context, name string, data EmailTemplateData) (string, error) {
+	tmpl, ok := r.defaults[name]
+	if !ok {
+		return "", fmt.Errorf("unknown email template %q", name)
+	}
+	data = expandMergeTags(data)
+
+	if r.overrides != nil && data.Newsletter.ID != "" && IsOverridableEmailTemplate(name) {
+		override, err := r.overrides.GetEmailTemplate(ctx, data.Newsletter.ID, name)
+		switch {
+		case err == nil:
+			rendered, renderErr := r.renderSource(override.Body, data)
+			if renderErr == nil {
+				return rendered, nil
+			}
+			fmt.Printf("Warning: email template %s of newsletter %s failed to render, using default: %v\n", name, data.Newsletter.ID, renderErr)
+		case !errors.Is(err, apperrors.ErrEmailTemplateNotFound):
+			return "", fmt.Errorf("loading email template %s for newsletter %s: %w", name, data.Newsletter.ID, err)
+		}
+	}
+
+	return r.execute(tmpl, data)
+}
+
+func (r *TemplateEmailRenderer) DefaultTemplate(name string) (string, bool) {
+	source, ok := r.sources[name]
+	return source, ok
+}
+
+func (r *TemplateEmailRenderer) ValidateTemplate(name string, body string) error {
+	if _, ok := r.defaults[name]; !ok {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("unknown email template %q", name))
+	}
+	if _, err := r.renderSource(body, sampleEmailTemplateData()); err != nil {
+		return apperrors.WrapValidation(err, "invalid email template")
+	}
+	return nil
+}
+
+// parse parses source as the content of an email inside the shared layout.
+func (r *TemplateEmailRenderer) parse(source string) (*template.Template, error) {
+	tmpl, err := r.layout.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.New("content").Parse(source)
+}
+
+func (r *TemplateEmailRenderer) renderSource(source string, data EmailTemplateData) (string, error) {
+	tmpl, err := r.parse(source)
+	if err != nil {
+		return "", err
+	}
+	return r.execute(tmpl, data)
+}
+
+func (r *TemplateEmailRenderer) execute(tmpl *template.Template, data EmailTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// sampleEmailTemplateData is used to check that a template renders before it is saved.
+func sampleEmailTemplateData() EmailTemplateData {
+	return EmailTemplateData{
+		Newsletter: EmailNewsletterData{
+			ID: "newsletter", Name: "Newsletter", Description: "Description", LogoURL: "https://example.com/logo.png",
+			AccentColor: DefaultEmailAccentColor, FooterText: "Footer", MailingAddress: "1 Main Street",
+			HeaderHTML: "<p>Header</p>", FooterHTML: "<p>Footer</p>",
+		},
+		Post:  EmailPostData{ID: "post", Title: "Title", Content: "<p>Content</p>"},
+		Posts: []EmailPostData{{ID: "post", Title: "Title", Content: "<p>Content</p>"}},
+		Subscriber: EmailSubscriberData{
+			Email: "subscriber@example.com", Name: "Sam Subscriber", FirstName: "Sam", LastName: "Subscriber",
+			Attributes: map[string]string{"company": "Example Inc."},
+		},
+		Links: EmailLinksData{
+			Unsubscribe: "https://example.com/unsubscribe", Confirm: "https://example.com/confirm", Preferences: "https://example.com/preferences",
+			PasswordReset: "https://example.com/reset",
+		},
+	}
+}
+
+var (
+	// htmlHeadRegex matches the document head, whose contents are not shown to readers.
+	htmlHeadRegex = regexp.MustCompile(`(?is)<head.*?</head>`)
+	// htmlLinkRegex matches anchors, capturing the target and the label.
+	htmlLinkRegex = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*["']([^"']*)["'][^>]*>(.*?)</a>`)
+	// htmlLineBreakRegex matches markup that ends a line of text.
+	htmlLineBreakRegex = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|table|blockquote)>`)
+	// htmlTagRegex matches markup tags so they can be stripped from plain-text renderings.
+	htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+	// blankLinesRegex matches runs of blank lines left behind by stripped markup.
+	blankLinesRegex = regexp.MustCompile(`\n\s*\n\s*`)
+)
+
+// HTMLToText converts a rendered HTML email into a plain-text approximation.
+// Links are kept as numbered footnotes listed after the text, so they stay usable in text-only clients.
+func HTMLToText(htmlBody string) string {
+	text := htmlHeadRegex.ReplaceAllString(htmlBody, "")
+
+	var links []string
+	text = htmlLinkRegex.ReplaceAllStringFunc(text, func(anchor string) string {
+		match := htmlLinkRegex.FindStringSubmatch(anchor)
+		href := strings.TrimSpace(html.UnescapeString(match[1]))
+		label := match[2]
+		plainLabel := strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(label, "")))
+		if href == "" || strings.HasPrefix(href, "#") || plainLabel == href {
+			return label
+		}
+
+		number := 0
+		for i, link := range links {
+			if link == href {
+				number = i + 1
+			}
+		}
+		if number == 0 {
+			links = append(links, href)
+			number = len(links)
+		}
+		return fmt.Sprintf("%s [%d]", label, number)
+	})
+
+	text = htmlLineBreakRegex.ReplaceAllString(text, "$0\n")
+	text = html.UnescapeString(htmlTagRegex.ReplaceAllString(text, ""))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.TrimSpace(blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+
+	if len(links) > 0 {
+		var footnotes strings.Builder
+		footnotes.WriteString("\n\nLinks:\n")
+		for i, link := range links {
+			fmt.Fprintf(&footnotes, "[%d] %s\n", i+1, link)
+		}
+		return text + footnotes.String()
+	}
+	return text + "\n"
+}
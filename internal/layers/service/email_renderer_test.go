@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockEmailTemplateRepository mocks the email template repository
+type MockEmailTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailTemplateRepository) GetEmailTemplate(ctx context.Context, newsletterID string, name string) (*models.EmailTemplate, error) {
+	args := m.Called(ctx, newsletterID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateRepository) ListEmailTemplatesByNewsletterID(ctx context.Context, newsletterID string) ([]models.EmailTemplate, error) {
+	args := m.Called(ctx, newsletterID)
+	return args.Get(0).([]models.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateRepository) UpsertEmailTemplate(ctx context.Context, newsletterID string, name string, body string) (*models.EmailTemplate, error) {
+	args := m.Called(ctx, newsletterID, name, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateRepository) DeleteEmailTemplate(ctx context.Context, newsletterID string, name string) error {
+	args := m.Called(ctx, newsletterID, name)
+	return args.Error(0)
+}
+
+func TestTemplateEmailRenderer_Render(t *testing.T) {
+	newsletter := &models.Newsletter{ID: "newsletter_123", Name: "Weekly <News>"}
+	post := &models.Post{ID: "post_123", Title: "Tom & Jerry", Content: "<p>Hello <b>world</b></p>"}
+	data := NewIssueEmailTemplateData(newsletter, post, "reader@example.com", "https://example.com/unsubscribe?token=abc")
+
+	tests := []struct {
+		name          string
+		setupMock     func(*MockEmailTemplateRepository)
+		expected      []string
+		notExpected   []string
+		expectedError string
+	}{
+		{
+			name: "default template escapes data but not post content",
+			setupMock: func(m *MockEmailTemplateRepository) {
+				m.On("GetEmailTemplate", mock.Anything, "newsletter_123", EmailTemplateIssue).Return(nil, apperrors.ErrEmailTemplateNotFound)
+			},
+			expected: []string{
+				">Tom &amp; Jerry</h1>",
+				"Dear reader,",
+				"<p>Hello <b>world</b></p>",
+				"Weekly &lt;News&gt;",
+				`<a href="https://example.com/unsubscribe?token=abc"`,
+			},
+		},
+		{
+			name: "newsletter override replaces the content but keeps the footer",
+			setupMock: func(m *MockEmailTemplateRepository) {
+				m.On("GetEmailTemplate", mock.Anything, "newsletter_123", EmailTemplateIssue).
+					Return(&models.EmailTemplate{Body: "<h2>{{.Post.Title}}</h2>"}, nil)
+			},
+			expected:    []string{"<h2>Tom &amp; Jerry</h2>", "Unsubscribe"},
+			notExpected: []string{"Dear reader,"},
+		},
+		{
+			name: "broken override falls back to the default",
+			setupMock: func(m *MockEmailTemplateRepository) {
+				m.On("GetEmailTemplate", mock.Anything, "newsletter_123", EmailTemplateIssue).
+					Return(&models.EmailTemplate{Body: "{{.Post.Missing}}"}, nil)
+			},
+			expected: []string{"Dear reader,"},
+		},
+		{
+			name: "repository failure is returned",
+			setupMock: func(m *MockEmailTemplateRepository) {
+				m.On("GetEmailTemplate", mock.Anything, "newsletter_123", EmailTemplateIssue).Return(nil, assert.AnError)
+			},
+			expectedError: "loading email template issue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockEmailTemplateRepository{}
+			tt.setupMock(mockRepo)
+			renderer, err := NewTemplateEmailRenderer(mockRepo)
+			require.NoError(t, err)
+
+			rendered, err := renderer.Render(context.Background(), EmailTemplateIssue, data)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, rendered, expected)
+			}
+			for _, notExpected := range tt.notExpected {
+				assert.NotContains(t, rendered, notExpected)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateEmailRenderer_Render_Branding(t *testing.T) {
+	renderer, err := NewTemplateEmailRenderer(nil)
+	require.NoError(t, err)
+
+	newsletter := &models.Newsletter{
+		ID:   "newsletter_123",
+		Name: "Weekly",
+		Branding: models.NewsletterBranding{
+			LogoURL:        "https://example.com/logo.png",
+			AccentColor:    "#ff6600",
+			FooterText:     "Thanks for reading",
+			MailingAddress: "1 Main Street, Springfield",
+			HeaderHTML:     "<p class=\"banner\">Issue header</p>",
+		},
+	}
+	data := NewEmailTemplateData(newsletter, "reader@example.com", "https://example.com/unsubscribe?token=abc")
+
+	rendered, err := renderer.Render(context.Background(), EmailTemplateConfirmation, data)
+
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, `<img src="https://example.com/logo.png"`)
+	assert.Contains(t, rendered, "border-top: 4px solid #ff6600")
+	assert.Contains(t, rendered, `<p class="banner">Issue header</p>`)
+	assert.Contains(t, rendered, "Thanks for reading")
+	assert.Contains(t, rendered, "1 Main Street, Springfield")
+
+	// Without branding the default accent color is used and no logo is shown.
+	rendered, err = renderer.Render(context.Background(), EmailTemplateConfirmation, NewEmailTemplateData(&models.Newsletter{Name: "Plain"}, "reader@example.com", ""))
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, "border-top: 4px solid "+DefaultEmailAccentColor)
+	assert.NotContains(t, rendered, "<img")
+}
+
+func TestTemplateEmailRenderer_Render_OptIn(t *testing.T) {
+	renderer, err := NewTemplateEmailRenderer(nil)
+	require.NoError(t, err)
+
+	data := NewEmailTemplateData(&models.Newsletter{Name: "Weekly"}, "reader@example.com", "")
+	data.Links.Confirm = "https://example.com/api/subscriptions/confirm?token=abc"
+
+	rendered, err := renderer.Render(context.Background(), EmailTemplateOptIn, data)
+
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, `href="https://example.com/api/subscriptions/confirm?token=abc"`)
+	// Pending subscribers have nothing to unsubscribe from yet.
+	assert.NotContains(t, rendered, "Unsubscribe")
+}
+
+func TestTemplateEmailRenderer_ValidateTemplate(t *testing.T) {
+	renderer, err := NewTemplateEmailRenderer(nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, renderer.ValidateTemplate(EmailTemplateConfirmation, "<p>Hi {{.Subscriber.Name}}, welcome to {{.Newsletter.Name}}</p>"))
+	assert.True(t, apperrors.IsValidation(renderer.ValidateTemplate(EmailTemplateConfirmation, "{{.Subscriber.Age}}")))
+	assert.True(t, apperrors.IsValidation(renderer.ValidateTemplate(EmailTemplateConfirmation, "{{if}}")))
+	assert.True(t, apperrors.IsValidation(renderer.ValidateTemplate("unknown", "<p>Hi</p>")))
+}
+
+func TestTemplateEmailRenderer_Render_Digest(t *testing.T) {
+	renderer, err := NewTemplateEmailRenderer(nil)
+	require.NoError(t, err)
+
+	posts := []*models.Post{
+		{ID: "post_1", Title: "First", Content: "<p>One</p>"},
+		{ID: "post_2", Title: "Second", Content: "<p>Two</p>"},
+	}
+	data := NewDigestEmailTemplateData(&models.Newsletter{Name: "Weekly"}, posts, "reader@example.com", "https://example.com/unsubscribe?token=abc")
+	data.Links.Preferences = "https://example.com/api/subscriptions/preferences?token=abc"
+
+	rendered, err := renderer.Render(context.Background(), EmailTemplateDigest, data)
+
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, ">First</h2>")
+	assert.Contains(t, rendered, "<p>Two</p>")
+	assert.Less(t, strings.Index(rendered, "First"), strings.Index(rendered, "Second"))
+	assert.Contains(t, rendered, `href="https://example.com/api/subscriptions/preferences?token=abc"`)
+}
+
+func TestTemplateEmailRenderer_Render_MergeTags(t *testing.T) {
+	renderer, err := NewTemplateEmailRenderer(nil)
+	require.NoError(t, err)
+
+	post := &models.Post{ID: "post_123", Title: "Hello", Content: `<p>Hi {{ .Subscriber.FirstName | default "friend" }} from {{ .Subscriber.Attributes.company }}</p>`}
+	data := NewIssueEmailTemplateData(&models.Newsletter{ID: "newsletter_123", Name: "Weekly"}, post, "reader@example.com", "")
+
+	data.Subscriber = NewEmailSubscriberData("reader@example.com", "Ann <b>Reader</b>", map[string]string{"company": "Tom & Co"})
+	rendered, err := renderer.Render(context.Background(), EmailTemplateIssue, data)
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, "<p>Hi Ann from Tom &amp; Co</p>")
+
+	data.Subscriber = NewEmailSubscriberData("reader@example.com", "", nil)
+	rendered, err = renderer.Render(context.Background(), EmailTemplateIssue, data)
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, "<p>Hi friend from </p>")
+
+	// Content whose merge tags fail to render is sent as written.
+	data.Post.Content = "<p>Hi {{ .Subscriber.Age }}</p>"
+	rendered, err = renderer.Render(context.Background(), EmailTemplateIssue, data)
+	assert.NoError(t, err)
+	assert.Contains(t, rendered, "<p>Hi {{ .Subscriber.Age }}</p>")
+}
+
+func TestNewEmailSubscriberData(t *testing.T) {
+	subscriber := NewEmailSubscriberData("ann.reader@example.com", " Ann Marie Reader ", nil)
+	assert.Equal(t, "Ann Marie Reader", subscriber.Name)
+	assert.Equal(t, "Ann", subscriber.FirstName)
+	assert.Equal(t, "Marie Reader", subscriber.LastName)
+
+	subscriber = NewEmailSubscriberData("ann.reader@example.com", "", nil)
+	assert.Equal(t, "ann.reader", subscriber.Name)
+	assert.Empty(t, subscriber.FirstName)
+	assert.Empty(t, subscriber.LastName)
+}
+
+func TestValidateMergeTags(t *testing.T) {
+	assert.NoError(t, ValidateMergeTags("<p>No merge tags</p>"))
+	assert.NoError(t, ValidateMergeTags(`<p>Hi {{ .Subscriber.FirstName | default "friend" }}, {{ .Subscriber.Attributes.plan }}</p>`))
+	assert.True(t, apperrors.IsValidation(ValidateMergeTags("<p>Hi {{ .Subscriber.Age }}</p>")))
+	assert.True(t, apperrors.IsValidation(ValidateMergeTags("<p>Hi {{ .Subscriber.Name </p>")))
+
+	// Braces that are not merge tags, such as code samples and Handlebars snippets, are plain text.
+	assert.NoError(t, ValidateMergeTags("<pre>{{#each items}}{{name}}{{/each}}</pre>"))
+	assert.NoError(t, ValidateMergeTags(`<p>Hi {{ .Subscriber.Name }}, try <code>{{ if x }}</code></p>`))
+}
+
+func TestRenderMergeTags(t *testing.T) {
+	data := sampleEmailTemplateData()
+	data.Subscriber = NewEmailSubscriberData("reader@example.com", "Ann Reader", nil)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "content without merge tags is unchanged",
+			content:  "<p>Hello & welcome</p>",
+			expected: "<p>Hello & welcome</p>",
+		},
+		{
+			name:     "literal braces are unchanged",
+			content:  "<pre>{{#if ready}}{{ title }}{{/if}}</pre>",
+			expected: "<pre>{{#if ready}}{{ title }}{{/if}}</pre>",
+		},
+		{
+			name:     "merge tags next to literal braces",
+			content:  "<p> {{- .Subscriber.FirstName }} uses <code>{{ user.name }}</code></p>",
+			expected: "<p>Ann uses <code>{{ user.name }}</code></p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data.Post.Content = template.HTML(tt.content)
+			rendered, err := renderMergeTags(data.Post.Content, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(rendered))
+		})
+	}
+}
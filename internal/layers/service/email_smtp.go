@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTP authentication mechanisms
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+	SMTPAuthNone    = "none"
+)
+
+// SMTP transport security modes
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection, typically on port 587
+	SMTPTLSImplicit = "implicit" // TLS from the first byte, typically on port 465
+	SMTPTLSNone     = "none"     // Only for local relays; credentials are refused over plain remote connections
+)
+
+// Defaults of SMTPProviderConfig
+const (
+	defaultSMTPTimeout            = 30 * time.Second // Bounds a single message when the context has no deadline
+	defaultSMTPPoolSize           = 4
+	defaultSMTPMaxMessagesPerConn = 100
+	defaultSMTPIdleTimeout        = 30 * time.Second
+)
+
+// SMTPProviderConfig holds configuration for SMTPProvider
+type SMTPProviderConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Auth     string        // One of the SMTPAuth* mechanisms
+	TLS      string        // One of the SMTPTLS* modes
+	DKIM     *DKIMSigner   // Optional; signs messages before they are handed to the relay
+	Timeout  time.Duration // Optional; defaults to 30 seconds
+
+	// Connection pool settings, all optional
+	PoolSize                 int           // Maximum open connections; defaults to 4
+	MaxMessagesPerConnection int           // Messages sent before a connection is replaced; defaults to 100
+	IdleTimeout              time.Duration // Idle connections older than this are closed; defaults to 30 seconds
+}
+
+// SMTPProvider implements EmailProvider for any SMTP relay, including Gmail.
+// It keeps a small pool of authenticated connections open and sends many messages over each one,
+// resetting the session with RSET between them and pipelining the envelope when the server supports it.
+type SMTPProvider struct {
+	config SMTPProviderConfig
+	auth   smtp.Auth
+	slots  chan struct{} // Limits the number of open connections to PoolSize
+
+	mu     sync.Mutex
+	idle   []*smtpConn
+	closed bool
+}
+
+// smtpConn is an authenticated connection of the pool.
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	sent     int
+	lastUsed time.Time
+}
+
+// NewSMTPProvider creates a new SMTP provider
+func NewSMTPProvider(config SMTPProviderConfig) (*SMTPProvider, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if config.Port == "" {
+		return nil, fmt.Errorf("SMTP port is required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultSMTPTimeout
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = defaultSMTPPoolSize
+	}
+	if config.MaxMessagesPerConnection <= 0 {
+		config.MaxMessagesPerConnection = defaultSMTPMaxMessagesPerConn
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultSMTPIdleTimeout
+	}
+
+	provider := &SMTPProvider{config: config, slots: make(chan struct{}, config.PoolSize)}
+	switch config.Auth {
+	case SMTPAuthNone:
+	case SMTPAuthPlain:
+		provider.auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	case SMTPAuthLogin:
+		provider.auth = &loginAuth{username: config.Username, password: config.Password, host: config.Host}
+	case SMTPAuthCRAMMD5:
+		provider.auth = smtp.CRAMMD5Auth(config.Username, config.Password)
+	default:
+		return nil, fmt.Errorf("unsupported SMTP auth %q, expected plain, login, cram-md5 or none", config.Auth)
+	}
+	if provider.auth != nil && (config.Username == "" || config.Password == "") {
+		return nil, fmt.Errorf("SMTP username and password are required for %s authentication", config.Auth)
+	}
+
+	switch config.TLS {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode %q, expected starttls, implicit or none", config.TLS)
+	}
+
+	return provider, nil
+}
+
+// Name identifies the provider
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Send encodes the message, signs it when DKIM is configured and delivers it over a pooled connection.
+// 5xx replies are permanent; 4xx replies, authentication and network failures are transient.
+func (p *SMTPProvider) Send(ctx context.Context, msg EmailMessage) error {
+	from, to, err := msg.Addresses()
+	if err != nil {
+		return err
+	}
+	message, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if p.config.DKIM != nil {
+		if message, err = p.config.DKIM.Sign(message); err != nil {
+			return fmt.Errorf("failed to sign email: %w", err)
+		}
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	c, err := p.acquire(ctx)
+	if err != nil {
+		return classifySMTPError(err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.config.Timeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		c.close()
+		return classifySMTPError(err)
+	}
+
+	if err := p.transmit(c.client, from.Address, to.Address, message); err != nil {
+		// A rejected message leaves the connection usable; anything else may have left it mid-transaction
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && c.client.Reset() == nil {
+			p.release(c)
+		} else {
+			c.close()
+		}
+		return classifySMTPError(err)
+	}
+
+	c.sent++
+	p.release(c)
+	return nil
+}
+
+// Close closes the idle connections and makes the provider close connections as they are released.
+func (p *SMTPProvider) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle, p.closed = nil, true
+	p.mu.Unlock()
+
+	for _, c := range idle {
+		c.quit()
+	}
+	return nil
+}
+
+// acquire returns a pooled connection after checking with RSET that it is still alive, or dials a new one.
+// The caller must hold a slot.
+func (p *SMTPProvider) acquire(ctx context.Context) (*smtpConn, error) {
+	for {
+		p.mu.Lock()
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			return p.dial(ctx)
+		}
+		c := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		if time.Since(c.lastUsed) > p.config.IdleTimeout {
+			c.quit()
+			continue
+		}
+		if err := c.conn.SetDeadline(time.Now().Add(p.config.Timeout)); err != nil || c.client.Reset() != nil {
+			// The server dropped the connection; reconnect
+			c.close()
+			continue
+		}
+		return c, nil
+	}
+}
+
+// release returns a connection to the pool, or closes it once it reached the per-connection message limit.
+func (p *SMTPProvider) release(c *smtpConn) {
+	c.lastUsed = time.Now()
+	if c.sent >= p.config.MaxMessagesPerConnection {
+		c.quit()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		c.quit()
+		return
+	}
+	p.idle = append(p.idle, c)
+	p.mu.Unlock()
+}
+
+// dial opens, secures and authenticates a new connection.
+func (p *SMTPProvider) dial(ctx context.Context) (*smtpConn, error) {
+	dialer := net.Dialer{Timeout: p.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.config.Host, p.config.Port))
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(p.config.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{ServerName: p.config.Host, MinVersion: tls.VersionTLS12}
+	if p.config.TLS == SMTPTLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &smtpConn{conn: conn, client: client}
+
+	if p.config.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+
+	if p.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			c.close()
+			return nil, &EmailDeliveryError{Provider: p.Name(), Err: errors.New("SMTP server does not support AUTH")}
+		}
+		if err := client.Auth(p.auth); err != nil {
+			c.close()
+			// Bad credentials are a configuration problem; the message itself may be retried once it is fixed
+			return nil, &EmailDeliveryError{Provider: p.Name(), Err: fmt.Errorf("authentication failed: %w", err)}
+		}
+	}
+
+	return c, nil
+}
+
+// transmit sends one message over an established session. When the server supports PIPELINING (RFC 2920)
+// MAIL, RCPT and DATA are written in one go and their replies read afterwards, saving two round trips.
+func (p *SMTPProvider) transmit(client *smtp.Client, from, to string, message []byte) error {
+	if ok, _ := client.Extension("PIPELINING"); !ok {
+		if err := client.Mail(from); err != nil {
+			return err
+		}
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+		w, err := client.Data()
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(message); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	if strings.ContainsAny(from+to, "\r\n") {
+		return errors.New("smtp: address contains CR or LF")
+	}
+	text := client.Text
+	if err := text.PrintfLine("MAIL FROM:<%s>", from); err != nil {
+		return err
+	}
+	if err := text.PrintfLine("RCPT TO:<%s>", to); err != nil {
+		return err
+	}
+	if err := text.PrintfLine("DATA"); err != nil {
+		return err
+	}
+
+	_, _, mailErr := text.ReadResponse(250)
+	_, _, rcptErr := text.ReadResponse(25)
+	_, _, dataErr := text.ReadResponse(354)
+	if mailErr != nil || rcptErr != nil {
+		if dataErr == nil {
+			// The server should refuse DATA without a valid recipient; if it did not, end the empty message
+			text.PrintfLine(".")
+			text.ReadResponse(250)
+		}
+		if mailErr != nil {
+			return mailErr
+		}
+		return rcptErr
+	}
+	if dataErr != nil {
+		return dataErr
+	}
+
+	w := text.DotWriter()
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, _, err := text.ReadResponse(250)
+	return err
+}
+
+// quit ends the session politely.
+func (c *smtpConn) quit() {
+	if err := c.conn.SetDeadline(time.Now().Add(5 * time.Second)); err == nil {
+		c.client.Quit()
+	}
+	c.client.Close()
+}
+
+// close drops the connection without QUIT, for connections in an unknown state.
+func (c *smtpConn) close() {
+	c.client.Close()
+}
+
+// classifySMTPError wraps a session error as an EmailDeliveryError: 5xx replies are permanent, anything else is transient.
+func classifySMTPError(err error) error {
+	var deliveryErr *EmailDeliveryError
+	if errors.As(err, &deliveryErr) {
+		return err
+	}
+	var protoErr *textproto.Error
+	permanent := errors.As(err, &protoErr) && protoErr.Code >= 500 && protoErr.Code < 600
+	return &EmailDeliveryError{Provider: "smtp", Permanent: permanent, Err: err}
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp does not provide.
+// Like smtp.PlainAuth it only sends credentials over TLS or to localhost.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalSMTPHost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalSMTPHost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
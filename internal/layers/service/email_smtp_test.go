@@ -0,0 +1,372 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a minimal SMTP server that accepts PLAIN, LOGIN and CRAM-MD5 authentication and records
+// the messages it receives. Recipients listed in rejects are refused with the given reply.
+// With dropAfterMessage it closes every connection after accepting a message, as servers with short idle timeouts do.
+type fakeSMTPServer struct {
+	listener         net.Listener
+	username         string
+	password         string
+	rejects          map[string]string
+	dropAfterMessage bool
+
+	mu          sync.Mutex
+	messages    []fakeSMTPMessage
+	authUsed    []string
+	commands    []string
+	connections int
+	active      int
+	maxActive   int
+}
+
+type fakeSMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener, username: "user", password: "secret", rejects: map[string]string{}}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) port() string {
+	return fmt.Sprint(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *fakeSMTPServer) received() []fakeSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeSMTPMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) authentications() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.authUsed...)
+}
+
+// stats returns the number of connections accepted, the most that were open at once and the commands received.
+func (s *fakeSMTPServer) stats() (connections, maxActive int, commands []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, s.maxActive, append([]string(nil), s.commands...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	s.mu.Lock()
+	s.connections++
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+
+	reply("220 localhost ESMTP")
+	var current fakeSMTPMessage
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		command := strings.ToUpper(line)
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Fields(command + " ")[0])
+		s.mu.Unlock()
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250-PIPELINING")
+			reply("250 AUTH PLAIN LOGIN CRAM-MD5")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			s.authenticate(reply, "plain", string(decoded) == "\x00"+s.username+"\x00"+s.password)
+		case strings.HasPrefix(command, "AUTH LOGIN"):
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			user, _ := readLine()
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			pass, _ := readLine()
+			decodedUser, _ := base64.StdEncoding.DecodeString(user)
+			decodedPass, _ := base64.StdEncoding.DecodeString(pass)
+			s.authenticate(reply, "login", string(decodedUser) == s.username && string(decodedPass) == s.password)
+		case strings.HasPrefix(command, "AUTH CRAM-MD5"):
+			challenge := "<123.456@localhost>"
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte(challenge)))
+			response, _ := readLine()
+			decoded, _ := base64.StdEncoding.DecodeString(response)
+			mac := hmac.New(md5.New, []byte(s.password))
+			mac.Write([]byte(challenge))
+			s.authenticate(reply, "cram-md5", string(decoded) == s.username+" "+hex.EncodeToString(mac.Sum(nil)))
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = fakeSMTPMessage{From: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<> ")
+			if rejection, ok := s.rejects[to]; ok {
+				reply(rejection)
+				continue
+			}
+			current.To = append(current.To, to)
+			reply("250 OK")
+		case command == "DATA":
+			if len(current.To) == 0 {
+				reply("554 No valid recipients")
+				continue
+			}
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				dataLine, ok := readLine()
+				if !ok {
+					return
+				}
+				if dataLine == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, ".") + "\r\n")
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 Queued")
+			if s.dropAfterMessage {
+				return
+			}
+		case command == "RSET":
+			current = fakeSMTPMessage{}
+			reply("250 OK")
+		case command == "NOOP":
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) authenticate(reply func(string), mechanism string, valid bool) {
+	if !valid {
+		reply("535 Authentication failed")
+		return
+	}
+	s.mu.Lock()
+	s.authUsed = append(s.authUsed, mechanism)
+	s.mu.Unlock()
+	reply("235 Authenticated")
+}
+
+func testSMTPMessage(to string) EmailMessage {
+	return EmailMessage{From: "news@example.com", To: to, Subject: "Issue", HTMLBody: "<p>Hello</p>"}
+}
+
+func TestSMTPProvider_Send_AuthMechanisms(t *testing.T) {
+	for _, auth := range []string{SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5, SMTPAuthNone} {
+		t.Run(auth, func(t *testing.T) {
+			server := newFakeSMTPServer(t)
+			provider, err := NewSMTPProvider(SMTPProviderConfig{
+				Host: "127.0.0.1", Port: server.port(), Username: "user", Password: "secret", Auth: auth, TLS: SMTPTLSNone,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, provider.Send(context.Background(), testSMTPMessage("reader@example.org")))
+
+			messages := server.received()
+			require.Len(t, messages, 1)
+			assert.Equal(t, "news@example.com", messages[0].From)
+			assert.Equal(t, []string{"reader@example.org"}, messages[0].To)
+			assert.Contains(t, messages[0].Data, "Subject: Issue\r\n")
+			if auth == SMTPAuthNone {
+				assert.Empty(t, server.authentications())
+			} else {
+				assert.Equal(t, []string{auth}, server.authentications())
+			}
+		})
+	}
+}
+
+func TestSMTPProvider_Send_ErrorClassification(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejects["gone@example.org"] = "550 No such user"
+	server.rejects["busy@example.org"] = "451 Try again later"
+
+	provider, err := NewSMTPProvider(SMTPProviderConfig{Host: "127.0.0.1", Port: server.port(), Auth: SMTPAuthNone, TLS: SMTPTLSNone})
+	require.NoError(t, err)
+
+	err = provider.Send(context.Background(), testSMTPMessage("gone@example.org"))
+	require.Error(t, err)
+	assert.True(t, IsPermanentEmailError(err))
+
+	err = provider.Send(context.Background(), testSMTPMessage("busy@example.org"))
+	require.Error(t, err)
+	assert.False(t, IsPermanentEmailError(err))
+
+	// Bad credentials are transient, as the message can be sent once the configuration is fixed
+	badAuth, err := NewSMTPProvider(SMTPProviderConfig{
+		Host: "127.0.0.1", Port: server.port(), Username: "user", Password: "wrong", Auth: SMTPAuthPlain, TLS: SMTPTLSNone,
+	})
+	require.NoError(t, err)
+	err = badAuth.Send(context.Background(), testSMTPMessage("reader@example.org"))
+	require.Error(t, err)
+	assert.False(t, IsPermanentEmailError(err))
+
+	// STARTTLS is required unless disabled explicitly
+	startTLS, err := NewSMTPProvider(SMTPProviderConfig{Host: "127.0.0.1", Port: server.port(), Auth: SMTPAuthNone, TLS: SMTPTLSStartTLS})
+	require.NoError(t, err)
+	err = startTLS.Send(context.Background(), testSMTPMessage("reader@example.org"))
+	assert.ErrorContains(t, err, "STARTTLS")
+	assert.False(t, IsPermanentEmailError(err))
+
+	assert.Empty(t, server.received())
+}
+
+func TestSMTPProvider_Send_ReusesConnections(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	provider, err := NewSMTPProvider(SMTPProviderConfig{
+		Host: "127.0.0.1", Port: server.port(), Username: "user", Password: "secret", Auth: SMTPAuthPlain, TLS: SMTPTLSNone,
+		PoolSize: 1, MaxMessagesPerConnection: 2,
+	})
+	require.NoError(t, err)
+	defer provider.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, provider.Send(context.Background(), testSMTPMessage(fmt.Sprintf("reader%d@example.org", i))))
+	}
+
+	require.Len(t, server.received(), 5)
+	connections, _, commands := server.stats()
+	assert.Equal(t, 3, connections, "two messages per connection")
+	assert.Equal(t, []string{"plain", "plain", "plain"}, server.authentications(), "one AUTH per connection")
+	assert.Equal(t, 2, countCommands(commands, "RSET"), "RSET before each reused send")
+	assert.Equal(t, 2, countCommands(commands, "QUIT"), "connections at the limit are closed")
+}
+
+func TestSMTPProvider_Send_RejectionKeepsConnection(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejects["gone@example.org"] = "550 No such user"
+	provider, err := NewSMTPProvider(SMTPProviderConfig{Host: "127.0.0.1", Port: server.port(), Auth: SMTPAuthNone, TLS: SMTPTLSNone, PoolSize: 1})
+	require.NoError(t, err)
+	defer provider.Close()
+
+	require.Error(t, provider.Send(context.Background(), testSMTPMessage("gone@example.org")))
+	require.NoError(t, provider.Send(context.Background(), testSMTPMessage("reader@example.org")))
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"reader@example.org"}, messages[0].To)
+	connections, _, _ := server.stats()
+	assert.Equal(t, 1, connections)
+}
+
+func TestSMTPProvider_Send_ReconnectsDroppedConnections(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.dropAfterMessage = true
+	provider, err := NewSMTPProvider(SMTPProviderConfig{Host: "127.0.0.1", Port: server.port(), Auth: SMTPAuthNone, TLS: SMTPTLSNone, PoolSize: 1})
+	require.NoError(t, err)
+	defer provider.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, provider.Send(context.Background(), testSMTPMessage("reader@example.org")))
+	}
+
+	assert.Len(t, server.received(), 3)
+	connections, _, _ := server.stats()
+	assert.Equal(t, 3, connections)
+}
+
+func TestSMTPProvider_Send_LimitsOpenConnections(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	provider, err := NewSMTPProvider(SMTPProviderConfig{Host: "127.0.0.1", Port: server.port(), Auth: SMTPAuthNone, TLS: SMTPTLSNone, PoolSize: 2})
+	require.NoError(t, err)
+	defer provider.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, provider.Send(context.Background(), testSMTPMessage(fmt.Sprintf("reader%d@example.org", i))))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, server.received(), 20)
+	connections, maxActive, _ := server.stats()
+	assert.LessOrEqual(t, connections, 2)
+	assert.LessOrEqual(t, maxActive, 2)
+}
+
+func countCommands(commands []string, name string) int {
+	count := 0
+	for _, command := range commands {
+		if command == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNewSMTPProvider_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SMTPProviderConfig
+	}{
+		{name: "missing host", config: SMTPProviderConfig{Port: "25", Auth: SMTPAuthNone, TLS: SMTPTLSNone}},
+		{name: "unknown auth", config: SMTPProviderConfig{Host: "h", Port: "25", Auth: "xoauth2", TLS: SMTPTLSNone}},
+		{name: "unknown TLS mode", config: SMTPProviderConfig{Host: "h", Port: "25", Auth: SMTPAuthNone, TLS: "ssl"}},
+		{name: "missing credentials", config: SMTPProviderConfig{Host: "h", Port: "25", Auth: SMTPAuthLogin, TLS: SMTPTLSNone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSMTPProvider(tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
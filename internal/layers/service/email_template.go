@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+)
+
+// MaxEmailTemplateLength limits the size of a newsletter's template override.
+const MaxEmailTemplateLength = 100000
+
+// EmailTemplateServiceInterface defines the operations for managing a newsletter's email templates.
+type EmailTemplateServiceInterface interface {
+	// ListEmailTemplates returns every overridable template of the newsletter, with its override if it has one.
+	ListEmailTemplates(ctx context.Context, editorID string, newsletterID string) ([]EmailTemplateView, error)
+	// SetEmailTemplate validates and stores an override of the named template.
+	SetEmailTemplate(ctx context.Context, editorID string, newsletterID string, name string, body string) (*EmailTemplateView, error)
+	// ResetEmailTemplate removes the override so the default template is used again.
+	ResetEmailTemplate(ctx context.Context, editorID string, newsletterID string, name string) error
+}
+
+// EmailTemplateView is a template as used by a newsletter: its override, or the default when there is none.
+type EmailTemplateView struct {
+	Name       string     `json:"name"`
+	Body       string     `json:"body"`
+	Customized bool       `json:"customized"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type emailTemplateService struct {
+	newsletterService NewsletterServiceInterface // For newsletter ownership checks
+	templateRepo      repository.EmailTemplateRepository
+	renderer          EmailRenderer // For default templates and validation
+}
+
+// NewEmailTemplateService creates a new email template service.
+func NewEmailTemplateService(
+	newsletterService NewsletterServiceInterface,
+	templateRepo repository.EmailTemplateRepository,
+	renderer EmailRenderer,
+) EmailTemplateServiceInterface {
+	return &emailTemplateService{
+		newsletterService: newsletterService,
+		templateRepo:      templateRepo,
+		renderer:          renderer,
+	}
+}
+
+func (s *emailTemplateService) ListEmailTemplates(ctx context.Context, editorID string, newsletterID string) ([]EmailTemplateView, error) {
+	if _, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: ListEmailTemplates: %w", err)
+	}
+
+	overrides, err := s.templateRepo.ListEmailTemplatesByNewsletterID(ctx, newsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("service: ListEmailTemplates: %w", err)
+	}
+
+	views := make([]EmailTemplateView, 0, len(OverridableEmailTemplates))
+	for _, name := range OverridableEmailTemplates {
+		view := EmailTemplateView{Name: name}
+		view.Body, _ = s.renderer.DefaultTemplate(name)
+		for _, override := range overrides {
+			if override.Name == name {
+				updatedAt := override.UpdatedAt
+				view.Body = override.Body
+				view.Customized = true
+				view.UpdatedAt = &updatedAt
+			}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (s *emailTemplateService) SetEmailTemplate(ctx context.Context, editorID string, newsletterID string, name string, body string) (*EmailTemplateView, error) {
+	if !IsOverridableEmailTemplate(name) {
+		return nil, fmt.Errorf("service: SetEmailTemplate: %w", apperrors.ErrEmailTemplateNotFound)
+	}
+	if len(body) > MaxEmailTemplateLength {
+		return nil, fmt.Errorf("service: SetEmailTemplate: %w", apperrors.ErrContentTooLong)
+	}
+	if _, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: SetEmailTemplate: %w", err)
+	}
+	if err := s.renderer.ValidateTemplate(name, body); err != nil {
+		return nil, fmt.Errorf("service: SetEmailTemplate: %w", err)
+	}
+
+	saved, err := s.templateRepo.UpsertEmailTemplate(ctx, newsletterID, name, body)
+	if err != nil {
+		return nil, fmt.Errorf("service: SetEmailTemplate: %w", err)
+	}
+	return &EmailTemplateView{Name: saved.Name, Body: saved.Body, Customized: true, UpdatedAt: &saved.UpdatedAt}, nil
+}
+
+func (s *emailTemplateService) ResetEmailTemplate(ctx context.Context, editorID string, newsletterID string, name string) error {
+	if !IsOverridableEmailTemplate(name) {
+		return fmt.Errorf("service: ResetEmailTemplate: %w", apperrors.ErrEmailTemplateNotFound)
+	}
+	if _, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID); err != nil {
+		return fmt.Errorf("service: ResetEmailTemplate: %w", err)
+	}
+
+	// Resetting a template that was never customized is not an error.
+	if err := s.templateRepo.DeleteEmailTemplate(ctx, newsletterID, name); err != nil && !errors.Is(err, apperrors.ErrEmailTemplateNotFound) {
+		return fmt.Errorf("service: ResetEmailTemplate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// mergeTagFuncs are the functions available in merge tags and email templates, in addition to the
+// html/template builtins.
+var mergeTagFuncs = template.FuncMap{
+	// default returns fallback when value is empty, e.g. {{ .Subscriber.FirstName | default "friend" }}.
+	"default": func(fallback string, value string) string {
+		if strings.TrimSpace(value) == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// mergeTagStart matches the opening of a merge tag: an action on the subscriber or the newsletter, such as
+// {{ .Subscriber.FirstName }}. Other braces, like those of code samples or Handlebars snippets, are not merge tags
+// and are sent as written.
+var mergeTagStart = regexp.MustCompile(`\{\{-?\s*\.(Subscriber|Newsletter)\b`)
+
+// Merge tags are marked with these delimiters before the content is parsed, so the template engine only sees
+// the merge tags and leaves any other braces alone. Post content never contains NUL bytes.
+const (
+	mergeTagLeftDelim  = "\x00{{"
+	mergeTagRightDelim = "}}\x00"
+)
+
+// hasMergeTags reports whether post content contains merge tags. Content without any is sent as written.
+func hasMergeTags(content string) bool {
+	return mergeTagStart.MatchString(content)
+}
+
+// markMergeTags returns the content with its merge tags marked by the merge tag delimiters.
+func markMergeTags(content string) (string, error) {
+	var marked strings.Builder
+	for {
+		loc := mergeTagStart.FindStringIndex(content)
+		if loc == nil {
+			marked.WriteString(content)
+			return marked.String(), nil
+		}
+		end := strings.Index(content[loc[0]:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("merge tag %q is not closed", content[loc[0]:loc[1]])
+		}
+		end += loc[0]
+		marked.WriteString(content[:loc[0]])
+		marked.WriteString(mergeTagLeftDelim)
+		marked.WriteString(content[loc[0]+2 : end])
+		marked.WriteString(mergeTagRightDelim)
+		content = content[end+2:]
+	}
+}
+
+// renderMergeTags renders the merge tags in editor-authored post content for one recipient. The content is an
+// html/template, so values that come from subscribers, such as their name and attributes, are escaped.
+func renderMergeTags(content template.HTML, data EmailTemplateData) (template.HTML, error) {
+	if !hasMergeTags(string(content)) {
+		return content, nil
+	}
+	marked, err := markMergeTags(string(content))
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New("post").Delims(mergeTagLeftDelim, mergeTagRightDelim).Funcs(mergeTagFuncs).Parse(marked)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// ValidateMergeTags checks that the merge tags in post content render, so mistakes are reported when the post is
+// saved rather than when it is sent.
+func ValidateMergeTags(content string) error {
+	data := sampleEmailTemplateData()
+	data.Post.Content = template.HTML(content)
+	if _, err := renderMergeTags(data.Post.Content, data); err != nil {
+		return apperrors.WrapValidation(err, "invalid merge tags in content")
+	}
+	return nil
+}
+
+// expandMergeTags renders the merge tags in the posts of an email for its recipient. A post whose merge tags
+// fail to render is sent as written, so one broken tag never stops an issue from going out.
+func expandMergeTags(data EmailTemplateData) EmailTemplateData {
+	data.Post.Content = expandPostMergeTags(data.Post, data)
+	if len(data.Posts) > 0 {
+		posts := make([]EmailPostData, len(data.Posts))
+		for i, post := range data.Posts {
+			posts[i] = post
+			posts[i].Content = expandPostMergeTags(post, data)
+		}
+		data.Posts = posts
+	}
+	return data
+}
+
+func expandPostMergeTags(post EmailPostData, data EmailTemplateData) template.HTML {
+	rendered, err := renderMergeTags(post.Content, data)
+	if err != nil {
+		fmt.Printf("Warning: merge tags of post %s failed to render, sending it as written: %v\n", post.ID, err)
+		return post.Content
+	}
+	return rendered
+}
@@ -27,18 +27,22 @@ type NewsletterServiceInterface interface {
 	CreateNewsletter(ctx context.Context, editorID, name, description string) (*models.Newsletter, error)
 	GetNewsletterByID(ctx context.Context, newsletterID string) (*models.Newsletter, error) // For internal/service use, ownership checked by caller if needed
 	GetNewsletterForEditor(ctx context.Context, editorID, newsletterID string) (*models.Newsletter, error) // For editor-specific get with ownership
-	UpdateNewsletter(ctx context.Context, editorID string, newsletterID string, name *string, description *string) (*models.Newsletter, error)
+	UpdateNewsletter(ctx context.Context, editorID string, newsletterID string, name *string, description *string, optInMode *models.OptInMode) (*models.Newsletter, error)
+	UpdateNewsletterBranding(ctx context.Context, editorID string, newsletterID string, branding models.NewsletterBranding) (*models.Newsletter, error)
+	UpdateNewsletterSubscriberFields(ctx context.Context, editorID string, newsletterID string, fields models.SubscriberFields) (*models.Newsletter, error)
 	DeleteNewsletter(ctx context.Context, editorID string, newsletterID string) error
 
 	// Post methods
-	CreatePost(ctx context.Context, editorID string, newsletterID string, title string, content string) (*models.Post, error)
+	CreatePost(ctx context.Context, editorID string, newsletterID string, title string, content string, scheduledAt *time.Time) (*models.Post, error)
 	GetPostByID(ctx context.Context, postID string) (*models.Post, error) // General get, ownership might be checked by caller
 	GetPostForEditor(ctx context.Context, editorID string, postID string) (*models.Post, error) // For editor-specific get with ownership of post's newsletter
-	ListPostsByNewsletterID(ctx context.Context, editorID string, newsletterID string, limit int, offset int) ([]models.Post, int, error)
-	UpdatePost(ctx context.Context, editorID string, postID string, title *string, content *string) (*models.Post, error)
+	ListPostsByNewsletterID(ctx context.Context, editorID string, newsletterID string, status models.PostStatus, limit int, offset int) ([]models.Post, int, error)
+	UpdatePost(ctx context.Context, editorID string, postID string, title *string, content *string, scheduledAt *time.Time) (*models.Post, error)
 	DeletePost(ctx context.Context, editorID string, postID string) error
 	PublishPost(ctx context.Context, editorID string, postID string) (*models.Post, error)
 	UnpublishPost(ctx context.Context, editorID string, postID string) (*models.Post, error)
+	CancelPostSchedule(ctx context.Context, editorID string, postID string) (*models.Post, error)
+	ArchivePost(ctx context.Context, editorID string, postID string) (*models.Post, error)
 }
 
 type newsletterService struct {
@@ -183,7 +187,7 @@ func (s *newsletterService) GetNewsletterForEditor(ctx context.Context, editorID
 	return newsletter, nil
 }
 
-func (s *newsletterService) UpdateNewsletter(ctx context.Context, editorID string, newsletterID string, name *string, description *string) (*models.Newsletter, error) {
+func (s *newsletterService) UpdateNewsletter(ctx context.Context, editorID string, newsletterID string, name *string, description *string, optInMode *models.OptInMode) (*models.Newsletter, error) {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -225,8 +229,13 @@ func (s *newsletterService) UpdateNewsletter(ctx context.Context, editorID strin
 		descPtr = &trimmedDescription
 	}
 
+	// Switching the mode only affects new subscriptions; pending subscribers can still confirm.
+	if optInMode != nil && !optInMode.IsValid() {
+		return nil, fmt.Errorf("service: UpdateNewsletter: %w: opt-in mode must be single or double", apperrors.ErrValidation)
+	}
+
 	// Repository atomically handles authorization and update
-	updatedNewsletter, err := s.newsletterRepo.UpdateNewsletter(ctx, newsletterID, editor.ID, namePtr, descPtr)
+	updatedNewsletter, err := s.newsletterRepo.UpdateNewsletter(ctx, newsletterID, editor.ID, namePtr, descPtr, optInMode)
 	if err != nil {
 		// We already checked for not found, so this would be an unexpected state.
 		return nil, fmt.Errorf("service: UpdateNewsletter: updating repository: %w", err)
@@ -234,6 +243,59 @@ func (s *newsletterService) UpdateNewsletter(ctx context.Context, editorID strin
 	return updatedNewsletter, nil
 }
 
+// UpdateNewsletterBranding replaces the branding used in the newsletter's emails.
+func (s *newsletterService) UpdateNewsletterBranding(ctx context.Context, editorID string, newsletterID string, branding models.NewsletterBranding) (*models.Newsletter, error) {
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.verifyNewsletterOwnershipWithEditor(ctx, editor, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterBranding: %w", err)
+	}
+
+	branding.LogoURL = strings.TrimSpace(branding.LogoURL)
+	branding.AccentColor = strings.TrimSpace(branding.AccentColor)
+	branding.FooterText = strings.TrimSpace(branding.FooterText)
+	branding.MailingAddress = strings.TrimSpace(branding.MailingAddress)
+	if err := branding.Validate(); err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterBranding: %w", err)
+	}
+
+	updatedNewsletter, err := s.newsletterRepo.UpdateNewsletterBranding(ctx, newsletterID, editor.ID, branding)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterBranding: updating repository: %w", err)
+	}
+	return updatedNewsletter, nil
+}
+
+// UpdateNewsletterSubscriberFields replaces the schema of the custom attributes of the newsletter's subscribers.
+// Attributes of fields that are removed stay stored with the subscribers but can no longer be set.
+func (s *newsletterService) UpdateNewsletterSubscriberFields(ctx context.Context, editorID string, newsletterID string, fields models.SubscriberFields) (*models.Newsletter, error) {
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.verifyNewsletterOwnershipWithEditor(ctx, editor, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterSubscriberFields: %w", err)
+	}
+
+	for i := range fields {
+		fields[i].Key = strings.TrimSpace(fields[i].Key)
+		fields[i].Label = strings.TrimSpace(fields[i].Label)
+	}
+	if err := fields.Validate(); err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterSubscriberFields: %w", err)
+	}
+
+	updatedNewsletter, err := s.newsletterRepo.UpdateNewsletterSubscriberFields(ctx, newsletterID, editor.ID, fields)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateNewsletterSubscriberFields: updating repository: %w", err)
+	}
+	return updatedNewsletter, nil
+}
+
 func (s *newsletterService) DeleteNewsletter(ctx context.Context, editorID string, newsletterID string) error {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
@@ -272,7 +334,7 @@ func (s *newsletterService) DeleteNewsletter(ctx context.Context, editorID strin
 
 // --- Post Methods ---
 
-func (s *newsletterService) CreatePost(ctx context.Context, editorID string, newsletterID string, title string, content string) (*models.Post, error) {
+func (s *newsletterService) CreatePost(ctx context.Context, editorID string, newsletterID string, title string, content string, scheduledAt *time.Time) (*models.Post, error) {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("service: CreatePost: authorization failed: %w", err)
@@ -298,6 +360,12 @@ func (s *newsletterService) CreatePost(ctx context.Context, editorID string, new
 	if len(content) < MinPostContentLength {
 		 return nil, fmt.Errorf("service: CreatePost: %w: content must be at least %d characters", apperrors.ErrValidation, MinPostContentLength)
 	}
+	if err := ValidateMergeTags(content); err != nil {
+		return nil, fmt.Errorf("service: CreatePost: %w", err)
+	}
+	if err := validateScheduledAt(scheduledAt); err != nil {
+		return nil, fmt.Errorf("service: CreatePost: %w", err)
+	}
 
 
 	post := &models.Post{
@@ -305,10 +373,15 @@ func (s *newsletterService) CreatePost(ctx context.Context, editorID string, new
 		NewsletterID: newsletter.ID,    // Use the verified newsletter's ID
 		Title:        title,
 		Content:      content,
+		Status:       models.PostStatusDraft,
+		ScheduledAt:  scheduledAt,
 		// PublishedAt is nil by default (not published)
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
+	if scheduledAt != nil {
+		post.Status = models.PostStatusScheduled
+	}
 
 	createdPost, err := s.postRepo.CreatePost(ctx, post)
 	if err != nil {
@@ -347,7 +420,7 @@ func (s *newsletterService) GetPostForEditor(ctx context.Context, editorID strin
 }
 
 
-func (s *newsletterService) ListPostsByNewsletterID(ctx context.Context, editorID string, newsletterID string, limit int, offset int) ([]models.Post, int, error) {
+func (s *newsletterService) ListPostsByNewsletterID(ctx context.Context, editorID string, newsletterID string, status models.PostStatus, limit int, offset int) ([]models.Post, int, error) {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("service: ListPostsByNewsletterID: authorization failed: %w", err)
@@ -365,15 +438,18 @@ func (s *newsletterService) ListPostsByNewsletterID(ctx context.Context, editorI
 	if offset < 0 {
 		offset = 0 // Default offset
 	}
+	if status != "" && !status.IsValid() {
+		return nil, 0, fmt.Errorf("service: ListPostsByNewsletterID: %w: unknown post status %q", apperrors.ErrValidation, status)
+	}
 
-	posts, total, err := s.postRepo.ListPostsByNewsletterID(ctx, newsletterID, limit, offset)
+	posts, total, err := s.postRepo.ListPostsByNewsletterID(ctx, newsletterID, status, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("service: ListPostsByNewsletterID: %w", err)
 	}
 	return posts, total, nil
 }
 
-func (s *newsletterService) UpdatePost(ctx context.Context, editorID string, postID string, title *string, content *string) (*models.Post, error) {
+func (s *newsletterService) UpdatePost(ctx context.Context, editorID string, postID string, title *string, content *string, scheduledAt *time.Time) (*models.Post, error) {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -385,10 +461,14 @@ func (s *newsletterService) UpdatePost(ctx context.Context, editorID string, pos
 	}
 
 	// Check if any changes are requested
-	if title == nil && content == nil {
+	if title == nil && content == nil && scheduledAt == nil {
 		return post, nil // No changes requested
 	}
 
+	if !post.Status.IsEditable() {
+		return nil, fmt.Errorf("service: UpdatePost: %w: post is %s and cannot be edited", apperrors.ErrInvalidPostTransition, post.Status)
+	}
+
 	// Validate title if provided
 	if title != nil {
 		trimmedTitle := strings.TrimSpace(*title)
@@ -410,9 +490,22 @@ func (s *newsletterService) UpdatePost(ctx context.Context, editorID string, pos
 		if len(trimmedContent) < MinPostContentLength {
 			return nil, fmt.Errorf("service: UpdatePost: %w: content must be at least %d characters", apperrors.ErrValidation, MinPostContentLength)
 		}
+		if err := ValidateMergeTags(trimmedContent); err != nil {
+			return nil, fmt.Errorf("service: UpdatePost: %w", err)
+		}
 		*content = trimmedContent // Update the pointer value with trimmed version
 	}
 
+	// Validate schedule if provided; only drafts and scheduled posts can be (re)scheduled
+	if scheduledAt != nil {
+		if !post.Status.CanTransitionTo(models.PostStatusScheduled) {
+			return nil, fmt.Errorf("service: UpdatePost: %w: post is %s and cannot be scheduled", apperrors.ErrInvalidPostTransition, post.Status)
+		}
+		if err := validateScheduledAt(scheduledAt); err != nil {
+			return nil, fmt.Errorf("service: UpdatePost: %w", err)
+		}
+	}
+
 	// Check if there are actual changes to avoid unnecessary updates
 	if title != nil && post.Title == *title {
 		title = nil // No change needed
@@ -420,16 +513,24 @@ func (s *newsletterService) UpdatePost(ctx context.Context, editorID string, pos
 	if content != nil && post.Content == *content {
 		content = nil // No change needed
 	}
+	if scheduledAt != nil && post.ScheduledAt != nil && post.ScheduledAt.Equal(*scheduledAt) {
+		scheduledAt = nil // No change needed
+	}
 
 	// If no actual changes after validation, return current post
-	if title == nil && content == nil {
+	if title == nil && content == nil && scheduledAt == nil {
 		return post, nil
 	}
 
 	// Use the flexible repository method to update only the provided fields
 	updates := repository.PostUpdate{
-		Title:   title,
-		Content: content,
+		Title:       title,
+		Content:     content,
+		ScheduledAt: scheduledAt,
+	}
+	if scheduledAt != nil {
+		scheduled := models.PostStatusScheduled
+		updates.Status = &scheduled
 	}
 	
 	updatedPost, err := s.postRepo.UpdatePost(ctx, postID, updates)
@@ -449,11 +550,16 @@ func (s *newsletterService) DeletePost(ctx context.Context, editorID string, pos
 		return err
 	}
 	
-	_, err = s.verifyPostOwnershipWithEditor(ctx, editor, postID)
+	post, err := s.verifyPostOwnershipWithEditor(ctx, editor, postID)
 	if err != nil {
 		return err
 	}
 
+	// Deleting would cascade to deliveries the worker is still sending
+	if post.Status == models.PostStatusSending {
+		return fmt.Errorf("service: DeletePost: %w: post is still sending", apperrors.ErrInvalidPostTransition)
+	}
+
 	err = s.postRepo.DeletePost(ctx, postID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrPostNotFound) {
@@ -478,18 +584,28 @@ func (s *newsletterService) PublishPost(ctx context.Context, editorID string, po
 	if post.IsPublished() {
 		return post, nil // Already published, no action needed, return current state
 	}
+	if !post.Status.CanTransitionTo(models.PostStatusSending) {
+		return nil, fmt.Errorf("service: PublishPost: %w: post is %s and cannot be published", apperrors.ErrInvalidPostTransition, post.Status)
+	}
 
 	now := time.Now().UTC()
 	updatedPost, err := s.postRepo.SetPostPublished(ctx, postID, now)
 	if err != nil {
-		if errors.Is(err, apperrors.ErrPostNotFound) { // Should not happen
-			return nil, fmt.Errorf("service: PublishPost: %w", apperrors.ErrPostNotFound)
+		if errors.Is(err, apperrors.ErrConflict) {
+			// The post was published, recalled, archived or deleted since it was read above.
+			current, errGet := s.postRepo.GetPostByID(ctx, postID)
+			if errGet != nil {
+				return nil, fmt.Errorf("service: PublishPost: %w", errGet)
+			}
+			return nil, fmt.Errorf("service: PublishPost: %w: post is %s and can no longer be published", apperrors.ErrInvalidPostTransition, current.Status)
 		}
 		return nil, fmt.Errorf("service: PublishPost: %w", err)
 	}
 	return updatedPost, nil
 }
 
+// UnpublishPost recalls a published post back to draft and records the recalling editor.
+// Drafts are not listed as published anywhere, so the post also disappears from public views.
 func (s *newsletterService) UnpublishPost(ctx context.Context, editorID string, postID string) (*models.Post, error) {
 	editor, err := s.getEditorFromContext(ctx)
 	if err != nil {
@@ -504,13 +620,86 @@ func (s *newsletterService) UnpublishPost(ctx context.Context, editorID string,
 	if !post.IsPublished() {
 		return post, nil // Already unpublished, no action needed
 	}
+	if !post.Status.CanTransitionTo(models.PostStatusDraft) {
+		return nil, fmt.Errorf("service: UnpublishPost: %w: post is %s and cannot return to draft", apperrors.ErrInvalidPostTransition, post.Status)
+	}
 
-	updatedPost, err := s.postRepo.SetPostUnpublished(ctx, postID)
+	updatedPost, err := s.postRepo.SetPostUnpublished(ctx, postID, editor.ID, time.Now().UTC())
 	if err != nil {
-		if errors.Is(err, apperrors.ErrPostNotFound) {
-			return nil, fmt.Errorf("service: UnpublishPost: %w", apperrors.ErrPostNotFound)
+		if errors.Is(err, apperrors.ErrConflict) {
+			// The post was archived, recalled or deleted since it was read above.
+			current, errGet := s.postRepo.GetPostByID(ctx, postID)
+			if errGet != nil {
+				return nil, fmt.Errorf("service: UnpublishPost: %w", errGet)
+			}
+			return nil, fmt.Errorf("service: UnpublishPost: %w: post is %s and no longer published", apperrors.ErrInvalidPostTransition, current.Status)
 		}
 		return nil, fmt.Errorf("service: UnpublishPost: %w", err)
 	}
 	return updatedPost, nil
 }
+
+// CancelPostSchedule removes the scheduled publication time so the post stays a draft.
+func (s *newsletterService) CancelPostSchedule(ctx context.Context, editorID string, postID string) (*models.Post, error) {
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.verifyPostOwnershipWithEditor(ctx, editor, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !post.IsScheduled() {
+		return post, nil // Nothing scheduled, no action needed
+	}
+
+	updatedPost, err := s.postRepo.ClearPostSchedule(ctx, postID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			// The scheduler published the post, or it changed otherwise, since it was read above.
+			current, errGet := s.postRepo.GetPostByID(ctx, postID)
+			if errGet != nil {
+				return nil, fmt.Errorf("service: CancelPostSchedule: %w", errGet)
+			}
+			return nil, fmt.Errorf("service: CancelPostSchedule: %w: post is %s and no longer scheduled", apperrors.ErrInvalidPostTransition, current.Status)
+		}
+		return nil, fmt.Errorf("service: CancelPostSchedule: %w", err)
+	}
+	return updatedPost, nil
+}
+
+// ArchivePost retires a post. Drafts and posts whose sending has finished can be archived.
+func (s *newsletterService) ArchivePost(ctx context.Context, editorID string, postID string) (*models.Post, error) {
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.verifyPostOwnershipWithEditor(ctx, editor, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Status == models.PostStatusArchived {
+		return post, nil // Already archived, no action needed
+	}
+	if !post.Status.CanTransitionTo(models.PostStatusArchived) {
+		return nil, fmt.Errorf("service: ArchivePost: %w: post is %s and cannot be archived", apperrors.ErrInvalidPostTransition, post.Status)
+	}
+
+	updatedPost, err := s.postRepo.TransitionPostStatus(ctx, postID, post.Status, models.PostStatusArchived)
+	if err != nil {
+		return nil, fmt.Errorf("service: ArchivePost: %w", err)
+	}
+	return updatedPost, nil
+}
+
+// validateScheduledAt ensures a requested publication time lies in the future.
+func validateScheduledAt(scheduledAt *time.Time) error {
+	if scheduledAt != nil && !scheduledAt.After(time.Now()) {
+		return fmt.Errorf("%w: scheduled_at must be in the future", apperrors.ErrValidation)
+	}
+	return nil
+}
@@ -48,8 +48,24 @@ func (m *MockNewsletterRepository) GetNewsletterByIDAndEditorID(ctx context.Cont
 	return args.Get(0).(*models.Newsletter), args.Error(1)
 }
 
-func (m *MockNewsletterRepository) UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string) (*models.Newsletter, error) {
-	args := m.Called(ctx, newsletterID, editorID, name, description)
+func (m *MockNewsletterRepository) UpdateNewsletter(ctx context.Context, newsletterID string, editorID string, name *string, description *string, optInMode *models.OptInMode) (*models.Newsletter, error) {
+	args := m.Called(ctx, newsletterID, editorID, name, description, optInMode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Newsletter), args.Error(1)
+}
+
+func (m *MockNewsletterRepository) UpdateNewsletterBranding(ctx context.Context, newsletterID string, editorID string, branding models.NewsletterBranding) (*models.Newsletter, error) {
+	args := m.Called(ctx, newsletterID, editorID, branding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Newsletter), args.Error(1)
+}
+
+func (m *MockNewsletterRepository) UpdateNewsletterSubscriberFields(ctx context.Context, newsletterID string, editorID string, fields models.SubscriberFields) (*models.Newsletter, error) {
+	args := m.Called(ctx, newsletterID, editorID, fields)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -90,8 +106,8 @@ func (m *MockPostRepository) GetPostByID(ctx context.Context, postID string) (*m
 	return args.Get(0).(*models.Post), args.Error(1)
 }
 
-func (m *MockPostRepository) ListPostsByNewsletterID(ctx context.Context, newsletterID string, limit int, offset int) ([]models.Post, int, error) {
-	args := m.Called(ctx, newsletterID, limit, offset)
+func (m *MockPostRepository) ListPostsByNewsletterID(ctx context.Context, newsletterID string, status models.PostStatus, limit int, offset int) ([]models.Post, int, error) {
+	args := m.Called(ctx, newsletterID, status, limit, offset)
 	return args.Get(0).([]models.Post), args.Get(1).(int), args.Error(2)
 }
 
@@ -111,7 +127,39 @@ func (m *MockPostRepository) SetPostPublished(ctx context.Context, postID string
 	return args.Get(0).(*models.Post), args.Error(1)
 }
 
-func (m *MockPostRepository) SetPostUnpublished(ctx context.Context, postID string) (*models.Post, error) {
+func (m *MockPostRepository) SetScheduledPostPublished(ctx context.Context, postID string, now time.Time) (*models.Post, error) {
+	args := m.Called(ctx, postID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) RestorePostSchedule(ctx context.Context, postID string, scheduledAt time.Time) (*models.Post, error) {
+	args := m.Called(ctx, postID, scheduledAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) RestorePostDraft(ctx context.Context, postID string) (*models.Post, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) SetPostUnpublished(ctx context.Context, postID string, recalledBy string, recalledAt time.Time) (*models.Post, error) {
+	args := m.Called(ctx, postID, recalledBy, recalledAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) ClearPostSchedule(ctx context.Context, postID string) (*models.Post, error) {
 	args := m.Called(ctx, postID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -119,6 +167,24 @@ func (m *MockPostRepository) SetPostUnpublished(ctx context.Context, postID stri
 	return args.Get(0).(*models.Post), args.Error(1)
 }
 
+func (m *MockPostRepository) ListDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]models.Post, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) TransitionPostStatus(ctx context.Context, postID string, from models.PostStatus, to models.PostStatus) (*models.Post, error) {
+	args := m.Called(ctx, postID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) CompletePostSending(ctx context.Context, postID string) error {
+	args := m.Called(ctx, postID)
+	return args.Error(0)
+}
+
 func (m *MockPostRepository) DeletePost(ctx context.Context, postID string) error {
 	args := m.Called(ctx, postID)
 	return args.Error(0)
@@ -129,8 +195,32 @@ type MockSubscriberService struct {
 	mock.Mock
 }
 
-func (m *MockSubscriberService) SubscribeToNewsletter(ctx context.Context, email, newsletterID string) (*models.Subscriber, error) {
-	args := m.Called(ctx, email, newsletterID)
+func (m *MockSubscriberService) SubscribeToNewsletter(ctx context.Context, email, newsletterID string, profile models.SubscriberProfile) (*models.Subscriber, error) {
+	args := m.Called(ctx, email, newsletterID, profile)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberService) ImportSubscribers(ctx context.Context, editorAuthID string, newsletterID string, subscribers []models.SubscriberImport) (*models.SubscriberImportResult, error) {
+	args := m.Called(ctx, editorAuthID, newsletterID, subscribers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubscriberImportResult), args.Error(1)
+}
+
+func (m *MockSubscriberService) UpdateSubscriberTags(ctx context.Context, editorAuthID string, newsletterID string, subscriberID string, tags []string) (*models.Subscriber, error) {
+	args := m.Called(ctx, editorAuthID, newsletterID, subscriberID, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscriber), args.Error(1)
+}
+
+func (m *MockSubscriberService) ConfirmSubscription(ctx context.Context, token string) (*models.Subscriber, error) {
+	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -142,6 +232,22 @@ func (m *MockSubscriberService) UnsubscribeByToken(ctx context.Context, token st
 	return args.Error(0)
 }
 
+func (m *MockSubscriberService) GetPreferences(ctx context.Context, token string) (*models.SubscriberPreferences, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubscriberPreferences), args.Error(1)
+}
+
+func (m *MockSubscriberService) UpdatePreferences(ctx context.Context, token string, update models.SubscriberPreferencesUpdate) (*models.SubscriberPreferences, error) {
+	args := m.Called(ctx, token, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubscriberPreferences), args.Error(1)
+}
+
 func (m *MockSubscriberService) ListActiveSubscribersByNewsletterID(ctx context.Context, editorAuthID string, newsletterID string, limit, offset int) ([]models.Subscriber, int, error) {
 	args := m.Called(ctx, editorAuthID, newsletterID, limit, offset)
 	return args.Get(0).([]models.Subscriber), args.Get(1).(int), args.Error(2)
@@ -516,4 +622,210 @@ func TestNewsletterService_DeleteNewsletter(t *testing.T) {
 			mockSubService.AssertExpectations(t)
 		})
 	}
-} 
\ No newline at end of file
+} 
+func TestNewsletterService_UpdatePost_Schedule(t *testing.T) {
+	future := time.Now().UTC().Add(72 * time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+	publishedAt := time.Now().UTC().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		scheduledAt   time.Time
+		existingPost  *models.Post
+		setupMocks    func(*MockPostRepository)
+		expectedError string
+		expectSuccess bool
+	}{
+		{
+			name:         "schedule draft for the future",
+			scheduledAt:  future,
+			existingPost: &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Title: "Monday Issue", Status: models.PostStatusDraft},
+			setupMocks: func(mockPostRepo *MockPostRepository) {
+				mockPostRepo.On("UpdatePost", mock.Anything, "post_123", mock.MatchedBy(func(u repository.PostUpdate) bool {
+					return u.Title == nil && u.Content == nil && u.ScheduledAt != nil && u.ScheduledAt.Equal(future) &&
+						u.Status != nil && *u.Status == models.PostStatusScheduled
+				})).Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusScheduled, ScheduledAt: &future}, nil)
+			},
+			expectSuccess: true,
+		},
+		{
+			name:          "schedule in the past is rejected",
+			scheduledAt:   past,
+			existingPost:  &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Title: "Monday Issue", Status: models.PostStatusDraft},
+			setupMocks:    func(mockPostRepo *MockPostRepository) {},
+			expectedError: "scheduled_at must be in the future",
+			expectSuccess: false,
+		},
+		{
+			name:          "published post cannot be scheduled",
+			scheduledAt:   future,
+			existingPost:  &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Title: "Monday Issue", Status: models.PostStatusSent, PublishedAt: &publishedAt},
+			setupMocks:    func(mockPostRepo *MockPostRepository) {},
+			expectedError: "post is sent and cannot be scheduled",
+			expectSuccess: false,
+		},
+		{
+			name:          "post cannot be changed while sending",
+			scheduledAt:   future,
+			existingPost:  &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Title: "Monday Issue", Status: models.PostStatusSending, PublishedAt: &publishedAt},
+			setupMocks:    func(mockPostRepo *MockPostRepository) {},
+			expectedError: "post is sending and cannot be edited",
+			expectSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockNewsletterRepo := &MockNewsletterRepository{}
+			mockPostRepo := &MockPostRepository{}
+			mockSubService := &MockSubscriberService{}
+
+			mockPostRepo.On("GetPostByID", mock.Anything, "post_123").Return(tt.existingPost, nil)
+			mockNewsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+				Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+			tt.setupMocks(mockPostRepo)
+
+			service := NewNewsletterService(mockNewsletterRepo, mockPostRepo, mockSubService)
+			ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+
+			scheduledAt := tt.scheduledAt
+			post, err := service.UpdatePost(ctx, "editor_456", "post_123", nil, nil, &scheduledAt)
+
+			if tt.expectSuccess {
+				assert.NoError(t, err)
+				assert.True(t, post.IsScheduled())
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+
+			mockNewsletterRepo.AssertExpectations(t)
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewsletterService_CancelPostSchedule_AlreadyPublished(t *testing.T) {
+	future := time.Now().UTC().Add(time.Hour)
+	publishedAt := time.Now().UTC()
+	mockNewsletterRepo := &MockNewsletterRepository{}
+	mockPostRepo := &MockPostRepository{}
+
+	// The scheduler publishes the post between the ownership check and the update.
+	mockPostRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusScheduled, ScheduledAt: &future}, nil).Once()
+	mockPostRepo.On("ClearPostSchedule", mock.Anything, "post_123").Return(nil, apperrors.ErrConflict)
+	mockPostRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusSending, PublishedAt: &publishedAt}, nil).Once()
+	mockNewsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+		Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+
+	service := NewNewsletterService(mockNewsletterRepo, mockPostRepo, &MockSubscriberService{})
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+
+	_, err := service.CancelPostSchedule(ctx, "editor_456", "post_123")
+
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPostTransition)
+	assert.Contains(t, err.Error(), "post is sending")
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestNewsletterService_PublishPost_ArchivedMeanwhile(t *testing.T) {
+	mockNewsletterRepo := &MockNewsletterRepository{}
+	mockPostRepo := &MockPostRepository{}
+
+	// Another editor archives the draft between the ownership check and the update.
+	mockPostRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusDraft}, nil).Once()
+	mockPostRepo.On("SetPostPublished", mock.Anything, "post_123", mock.AnythingOfType("time.Time")).Return(nil, apperrors.ErrConflict)
+	mockPostRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusArchived}, nil).Once()
+	mockNewsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+		Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+
+	service := NewNewsletterService(mockNewsletterRepo, mockPostRepo, &MockSubscriberService{})
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+
+	_, err := service.PublishPost(ctx, "editor_456", "post_123")
+
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPostTransition)
+	assert.Contains(t, err.Error(), "post is archived")
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestNewsletterService_UnpublishPost(t *testing.T) {
+	publishedAt := time.Now().UTC().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		existingPost   *models.Post
+		setupMocks     func(*MockPostRepository)
+		expectedStatus models.PostStatus
+		expectedError  string
+		expectSuccess  bool
+	}{
+		{
+			name:         "sending post is recalled by the editor",
+			existingPost: &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusSending, PublishedAt: &publishedAt},
+			setupMocks: func(mockPostRepo *MockPostRepository) {
+				mockPostRepo.On("SetPostUnpublished", mock.Anything, "post_123", "editor_456", mock.AnythingOfType("time.Time")).
+					Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusDraft, RecalledBy: "editor_456"}, nil)
+			},
+			expectedStatus: models.PostStatusDraft,
+			expectSuccess:  true,
+		},
+		{
+			name:           "draft is left untouched",
+			existingPost:   &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusDraft},
+			setupMocks:     func(mockPostRepo *MockPostRepository) {},
+			expectedStatus: models.PostStatusDraft,
+			expectSuccess:  true,
+		},
+		{
+			name:          "archived post cannot be recalled",
+			existingPost:  &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusArchived, PublishedAt: &publishedAt},
+			setupMocks:    func(mockPostRepo *MockPostRepository) {},
+			expectedError: "invalid post status transition",
+			expectSuccess: false,
+		},
+		{
+			name:         "post archived while being recalled is left archived",
+			existingPost: &models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusSent, PublishedAt: &publishedAt},
+			setupMocks: func(mockPostRepo *MockPostRepository) {
+				mockPostRepo.On("SetPostUnpublished", mock.Anything, "post_123", "editor_456", mock.AnythingOfType("time.Time")).
+					Return(nil, apperrors.ErrConflict)
+			},
+			expectedError: "invalid post status transition",
+			expectSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockNewsletterRepo := &MockNewsletterRepository{}
+			mockPostRepo := &MockPostRepository{}
+			mockSubService := &MockSubscriberService{}
+
+			mockPostRepo.On("GetPostByID", mock.Anything, "post_123").Return(tt.existingPost, nil)
+			mockNewsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+				Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+			tt.setupMocks(mockPostRepo)
+
+			service := NewNewsletterService(mockNewsletterRepo, mockPostRepo, mockSubService)
+			ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+
+			post, err := service.UnpublishPost(ctx, "firebase_uid", "post_123")
+
+			if tt.expectSuccess {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, post.Status)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+
+			mockNewsletterRepo.AssertExpectations(t)
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -77,4 +77,56 @@ func (s *FirebasePasswordResetService) SendPasswordResetEmail(ctx context.Contex
 	
 	s.config.Logger.Printf("Password reset email sent successfully to %s", email)
 	return nil
-} 
\ No newline at end of file
+}
+
+// PasswordResetLinkGenerator generates password reset links. The Firebase Admin auth client implements it.
+type PasswordResetLinkGenerator interface {
+	PasswordResetLink(ctx context.Context, email string) (string, error)
+}
+
+// EmailPasswordResetServiceConfig holds configuration for the templated password reset service
+type EmailPasswordResetServiceConfig struct {
+	LinkGenerator PasswordResetLinkGenerator
+	EmailService  EmailService
+	Logger        *log.Logger
+}
+
+// EmailPasswordResetService implements PasswordResetService by generating the reset link with Firebase
+// and sending it ourselves, rendered from the password reset email template.
+type EmailPasswordResetService struct {
+	config EmailPasswordResetServiceConfig
+}
+
+// NewEmailPasswordResetService creates a new templated password reset service
+func NewEmailPasswordResetService(config EmailPasswordResetServiceConfig) (*EmailPasswordResetService, error) {
+	if config.LinkGenerator == nil {
+		return nil, fmt.Errorf("password reset link generator is required")
+	}
+	if config.EmailService == nil {
+		return nil, fmt.Errorf("email service is required")
+	}
+	if config.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	return &EmailPasswordResetService{
+		config: config,
+	}, nil
+}
+
+// SendPasswordResetEmail generates a password reset link for the editor and emails it
+func (s *EmailPasswordResetService) SendPasswordResetEmail(ctx context.Context, email string) error {
+	link, err := s.config.LinkGenerator.PasswordResetLink(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset link: %w", err)
+	}
+
+	data := NewEmailTemplateData(nil, email, "")
+	data.Links.PasswordReset = link
+	if err := s.config.EmailService.SendPasswordResetEmailHTML(ctx, email, data); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	s.config.Logger.Printf("Password reset email sent successfully to %s", email)
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+const (
+	// MaxSubscriberNameLength limits the display name a subscriber can set, in characters.
+	MaxSubscriberNameLength = 100
+	// MaxPauseWeeks limits how long a subscriber can pause delivery at once.
+	MaxPauseWeeks = 52
+)
+
+// GetPreferences returns the preferences of the address the unsubscribe token was issued for.
+// The preference center is opened with the unsubscribe token of any email the subscriber received,
+// so every issue can link to it without carrying a second token.
+func (s *SubscriberService) GetPreferences(ctx context.Context, token string) (*models.SubscriberPreferences, error) {
+	subscriber, subscriptions, err := s.subscriptionsForPreferences(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetPreferences: %w", err)
+	}
+	return s.buildPreferences(ctx, subscriber, subscriptions), nil
+}
+
+// UpdatePreferences applies the update to the subscriptions of the address the unsubscribe token was issued for.
+// The display name is shared by all of them; unsubscribing, pausing and the frequency apply per newsletter.
+// The update is validated as a whole before anything is changed.
+func (s *SubscriberService) UpdatePreferences(ctx context.Context, token string, update models.SubscriberPreferencesUpdate) (*models.SubscriberPreferences, error) {
+	if err := validatePreferencesUpdate(update); err != nil {
+		return nil, fmt.Errorf("service: UpdatePreferences: %w", err)
+	}
+
+	subscriber, subscriptions, err := s.subscriptionsForPreferences(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdatePreferences: %w", err)
+	}
+
+	subscribed := make(map[string]bool, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscribed[subscription.NewsletterID] = true
+	}
+	changes := make(map[string]models.SubscriptionPreferenceUpdate, len(update.Subscriptions))
+	for _, change := range update.Subscriptions {
+		if !subscribed[change.NewsletterID] {
+			return nil, fmt.Errorf("service: UpdatePreferences: %w: not subscribed to newsletter '%s'", apperrors.ErrValidation, change.NewsletterID)
+		}
+		changes[change.NewsletterID] = change
+	}
+
+	now := time.Now().UTC()
+	remaining := make([]models.Subscriber, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		change, changed := changes[subscription.NewsletterID]
+		if changed && change.Unsubscribe {
+			if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, subscription.ID, models.SubscriberStatusUnsubscribed); err != nil {
+				return nil, fmt.Errorf("service: UpdatePreferences: unsubscribing %s: %w", subscription.ID, err)
+			}
+			continue
+		}
+
+		updated := subscription
+		if update.Name != nil {
+			updated.Name = strings.TrimSpace(*update.Name)
+		}
+		if changed && change.Frequency != nil {
+			updated.Frequency = *change.Frequency
+		}
+		if changed && change.PauseWeeks != nil {
+			updated.PausedUntil = nil
+			if weeks := *change.PauseWeeks; weeks > 0 {
+				until := now.AddDate(0, 0, 7*weeks)
+				updated.PausedUntil = &until
+			}
+		}
+
+		if updated.Name != subscription.Name || updated.Frequency != subscription.Frequency || !samePause(updated.PausedUntil, subscription.PausedUntil) {
+			if err := s.subscriberRepo.UpdateSubscriberPreferences(ctx, updated.ID, updated.Name, updated.Frequency, updated.PausedUntil); err != nil {
+				return nil, fmt.Errorf("service: UpdatePreferences: updating %s: %w", updated.ID, err)
+			}
+		}
+		remaining = append(remaining, updated)
+	}
+
+	if update.Name != nil {
+		subscriber.Name = strings.TrimSpace(*update.Name)
+	}
+	return s.buildPreferences(ctx, subscriber, remaining), nil
+}
+
+// validatePreferencesUpdate checks the values of an update without looking at the subscriptions.
+func validatePreferencesUpdate(update models.SubscriberPreferencesUpdate) error {
+	if update.Name != nil && utf8.RuneCountInString(strings.TrimSpace(*update.Name)) > MaxSubscriberNameLength {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("name must not be longer than %d characters", MaxSubscriberNameLength))
+	}
+	seen := make(map[string]bool, len(update.Subscriptions))
+	for _, change := range update.Subscriptions {
+		if strings.TrimSpace(change.NewsletterID) == "" {
+			return apperrors.WrapValidation(nil, "newsletter_id is required")
+		}
+		if seen[change.NewsletterID] {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("newsletter '%s' is listed twice", change.NewsletterID))
+		}
+		seen[change.NewsletterID] = true
+		if change.Frequency != nil && !change.Frequency.IsValid() {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("invalid frequency: %s", *change.Frequency))
+		}
+		if change.PauseWeeks != nil && (*change.PauseWeeks < 0 || *change.PauseWeeks > MaxPauseWeeks) {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("pause_weeks must be between 0 and %d", MaxPauseWeeks))
+		}
+	}
+	return nil
+}
+
+// subscriptionsForPreferences returns the subscriber of the token and the active subscriptions of their address.
+func (s *SubscriberService) subscriptionsForPreferences(ctx context.Context, token string) (*models.Subscriber, []models.Subscriber, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil, fmt.Errorf("%w: token cannot be empty", apperrors.ErrValidation)
+	}
+	subscriber, err := s.subscriberForUnsubscribeToken(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all, err := s.subscriberRepo.ListSubscribersByEmail(ctx, subscriber.Email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing subscriptions: %w", err)
+	}
+	active := make([]models.Subscriber, 0, len(all))
+	for _, subscription := range all {
+		if subscription.Status == models.SubscriberStatusActive {
+			active = append(active, subscription)
+		}
+	}
+	return subscriber, active, nil
+}
+
+// buildPreferences describes the subscriptions, sorted by newsletter name. Subscriptions to newsletters
+// that no longer exist are left out.
+func (s *SubscriberService) buildPreferences(ctx context.Context, subscriber *models.Subscriber, subscriptions []models.Subscriber) *models.SubscriberPreferences {
+	preferences := &models.SubscriberPreferences{
+		Email:         subscriber.Email,
+		Name:          subscriber.Name,
+		Subscriptions: make([]models.SubscriptionPreference, 0, len(subscriptions)),
+	}
+	for _, subscription := range subscriptions {
+		newsletter, err := s.newsletterRepo.GetNewsletterByID(ctx, subscription.NewsletterID)
+		if err != nil {
+			if !errors.Is(err, apperrors.ErrNewsletterNotFound) {
+				fmt.Printf("Warning: Failed to load newsletter %s for the preferences of %s: %v\n", subscription.NewsletterID, subscription.ID, err)
+			}
+			continue
+		}
+		if preferences.Name == "" {
+			preferences.Name = subscription.Name
+		}
+		preferences.Subscriptions = append(preferences.Subscriptions, models.SubscriptionPreference{
+			NewsletterID:   subscription.NewsletterID,
+			NewsletterName: newsletter.Name,
+			SubscribedAt:   subscription.SubscriptionDate,
+			Frequency:      subscription.Frequency,
+			PausedUntil:    subscription.PausedUntil,
+		})
+	}
+	sort.SliceStable(preferences.Subscriptions, func(i, j int) bool {
+		return preferences.Subscriptions[i].NewsletterName < preferences.Subscriptions[j].NewsletterName
+	})
+	return preferences
+}
+
+// PreferencesLink returns the link to the preference center for an unsubscribe token.
+func PreferencesLink(appBaseURL, unsubscribeToken string) string {
+	return fmt.Sprintf("%s/api/subscriptions/preferences?token=%s", appBaseURL, url.QueryEscape(unsubscribeToken))
+}
+
+func samePause(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// setupPreferencesTest returns a service whose token subscriber "sub-1" is subscribed to two newsletters
+// and was unsubscribed from a third.
+func setupPreferencesTest(t *testing.T) (SubscriberServiceInterface, *MockSubscriberRepository, string) {
+	subscriberRepo := &MockSubscriberRepository{}
+	newsletterRepo := &MockNewsletterRepository{}
+	signer := newTestTokenSigner(t, "secret")
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, nil, nil, "http://localhost:8080", signer, 0, 0)
+
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", Email: "reader@example.com", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}, nil)
+	subscriberRepo.On("ListSubscribersByEmail", mock.Anything, "reader@example.com").Return([]models.Subscriber{
+		{ID: "sub-1", Email: "reader@example.com", NewsletterID: "nl-1", Status: models.SubscriberStatusActive, Frequency: models.DeliveryFrequencyImmediate},
+		{ID: "sub-2", Email: "reader@example.com", NewsletterID: "nl-2", Name: "Ann", Status: models.SubscriberStatusActive, Frequency: models.DeliveryFrequencyWeekly},
+		{ID: "sub-3", Email: "reader@example.com", NewsletterID: "nl-3", Status: models.SubscriberStatusUnsubscribed, Frequency: models.DeliveryFrequencyImmediate},
+	}, nil)
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", Name: "Weekly News"}, nil)
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-2").Return(&models.Newsletter{ID: "nl-2", Name: "Daily Tips"}, nil)
+
+	return svc, subscriberRepo, signer.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1")
+}
+
+func TestSubscriberService_GetPreferences(t *testing.T) {
+	svc, _, token := setupPreferencesTest(t)
+
+	preferences, err := svc.GetPreferences(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "reader@example.com", preferences.Email)
+	assert.Equal(t, "Ann", preferences.Name, "the name set on another subscription is shown")
+	require.Len(t, preferences.Subscriptions, 2, "only active subscriptions are listed")
+	assert.Equal(t, "Daily Tips", preferences.Subscriptions[0].NewsletterName)
+	assert.Equal(t, models.DeliveryFrequencyWeekly, preferences.Subscriptions[0].Frequency)
+	assert.Equal(t, "Weekly News", preferences.Subscriptions[1].NewsletterName)
+}
+
+func TestSubscriberService_UpdatePreferences(t *testing.T) {
+	svc, subscriberRepo, token := setupPreferencesTest(t)
+	name := " Ann Reader "
+	daily := models.DeliveryFrequencyDaily
+	pause := 2
+
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-2", models.SubscriberStatusUnsubscribed).Return(nil).Once()
+	subscriberRepo.On("UpdateSubscriberPreferences", mock.Anything, "sub-1", "Ann Reader", models.DeliveryFrequencyDaily,
+		mock.MatchedBy(func(until *time.Time) bool {
+			return until != nil && until.Sub(time.Now()) > 13*24*time.Hour && until.Sub(time.Now()) <= 14*24*time.Hour
+		})).Return(nil).Once()
+
+	preferences, err := svc.UpdatePreferences(context.Background(), token, models.SubscriberPreferencesUpdate{
+		Name: &name,
+		Subscriptions: []models.SubscriptionPreferenceUpdate{
+			{NewsletterID: "nl-1", Frequency: &daily, PauseWeeks: &pause},
+			{NewsletterID: "nl-2", Unsubscribe: true},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Ann Reader", preferences.Name)
+	require.Len(t, preferences.Subscriptions, 1)
+	assert.Equal(t, "nl-1", preferences.Subscriptions[0].NewsletterID)
+	assert.Equal(t, models.DeliveryFrequencyDaily, preferences.Subscriptions[0].Frequency)
+	assert.NotNil(t, preferences.Subscriptions[0].PausedUntil)
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestSubscriberService_UpdatePreferences_Resume(t *testing.T) {
+	subscriberRepo := &MockSubscriberRepository{}
+	newsletterRepo := &MockNewsletterRepository{}
+	signer := newTestTokenSigner(t, "secret")
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, nil, nil, "http://localhost:8080", signer, 0, 0)
+
+	pausedUntil := time.Now().Add(7 * 24 * time.Hour)
+	subscription := models.Subscriber{ID: "sub-1", Email: "reader@example.com", NewsletterID: "nl-1", Status: models.SubscriberStatusActive,
+		Frequency: models.DeliveryFrequencyImmediate, PausedUntil: &pausedUntil}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(&subscription, nil)
+	subscriberRepo.On("ListSubscribersByEmail", mock.Anything, "reader@example.com").Return([]models.Subscriber{subscription}, nil)
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", Name: "Weekly News"}, nil)
+	subscriberRepo.On("UpdateSubscriberPreferences", mock.Anything, "sub-1", "", models.DeliveryFrequencyImmediate, (*time.Time)(nil)).Return(nil).Once()
+
+	resume := 0
+	preferences, err := svc.UpdatePreferences(context.Background(), signer.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1"),
+		models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-1", PauseWeeks: &resume}}})
+
+	require.NoError(t, err)
+	assert.Nil(t, preferences.Subscriptions[0].PausedUntil)
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestSubscriberService_UpdatePreferences_Invalid(t *testing.T) {
+	longName := strings.Repeat("a", MaxSubscriberNameLength+1)
+	hourly := models.DeliveryFrequency("hourly")
+	tooLong := MaxPauseWeeks + 1
+	negative := -1
+
+	tests := []struct {
+		name   string
+		update models.SubscriberPreferencesUpdate
+	}{
+		{name: "name too long", update: models.SubscriberPreferencesUpdate{Name: &longName}},
+		{name: "unknown frequency", update: models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-1", Frequency: &hourly}}}},
+		{name: "pause too long", update: models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-1", PauseWeeks: &tooLong}}}},
+		{name: "negative pause", update: models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-1", PauseWeeks: &negative}}}},
+		{name: "newsletter listed twice", update: models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-1"}, {NewsletterID: "nl-1"}}}},
+		{name: "not subscribed", update: models.SubscriberPreferencesUpdate{Subscriptions: []models.SubscriptionPreferenceUpdate{{NewsletterID: "nl-3", Unsubscribe: true}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, subscriberRepo, token := setupPreferencesTest(t)
+
+			_, err := svc.UpdatePreferences(context.Background(), token, tt.update)
+
+			assert.True(t, errors.Is(err, apperrors.ErrValidation), "got %v", err)
+			subscriberRepo.AssertNotCalled(t, "UpdateSubscriberStatus", mock.Anything, mock.Anything, mock.Anything)
+			subscriberRepo.AssertNotCalled(t, "UpdateSubscriberPreferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSubscriberService_GetPreferences_InvalidToken(t *testing.T) {
+	svc, _, _ := setupPreferencesTest(t)
+
+	_, err := svc.GetPreferences(context.Background(), newTestTokenSigner(t, "other").Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1"))
+
+	assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid), "got %v", err)
+}
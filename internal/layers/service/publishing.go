@@ -4,48 +4,98 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/config"
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
-	// No direct import to internal/worker needed anymore
 )
 
-var _ models.Post // Trick to ensure models import is kept if auto-formatter is aggressive
-
 // PublishingServiceInterface defines the contract for the publishing service.
 type PublishingServiceInterface interface {
-	PublishPostToSubscribers(ctx context.Context, postID string, editorFirebaseUID string) error
+	// PublishPostToSubscribers publishes a post to every active subscriber of its newsletter, or only to those
+	// matching one of the newsletter's segments when segmentID is not empty.
+	PublishPostToSubscribers(ctx context.Context, postID string, editorFirebaseUID string, segmentID string) error
+	// PublishScheduledPost publishes a post whose scheduled time has passed. It is called by the
+	// scheduler rather than an editor, so no ownership check is performed. It reports whether the post was
+	// published; a post that is no longer scheduled and due is left alone.
+	PublishScheduledPost(ctx context.Context, postID string) (bool, error)
+	// RecallPost unpublishes a post and cancels its deliveries and digest items that have not been sent yet.
+	// It returns the draft post and the number of cancelled deliveries.
+	RecallPost(ctx context.Context, postID string, editorFirebaseUID string) (*models.Post, int, error)
+	// SendTestPost emails the rendered post to the given addresses, or to the editor when none are given.
+	// The post's status is not changed and no deliveries are recorded. It returns the addresses used.
+	SendTestPost(ctx context.Context, postID string, editorFirebaseUID string, recipients []string) ([]string, error)
+	// PreviewPost renders the email a subscriber would receive for the post, as "html" or "text".
+	PreviewPost(ctx context.Context, postID string, editorFirebaseUID string, format string) (string, error)
+	ListDeliveries(ctx context.Context, editorFirebaseUID string, postID string, status models.DeliveryStatus, limit int, offset int) ([]models.Delivery, int, error)
+	GetDeliverySummary(ctx context.Context, editorFirebaseUID string, postID string) (*models.DeliverySummary, error)
 }
 
 // PublishingService handles the logic for publishing posts to subscribers.
+// Emails are not sent here; one delivery per recipient is enqueued and drained by the delivery worker.
 type PublishingService struct {
-	newsletterService NewsletterServiceInterface // To get post details & mark as published
-	subscriberService SubscriberServiceInterface // To get active subscribers
-	emailService      EmailService               // For direct email sending
-	config            *config.Config             // Application configuration
+	newsletterService NewsletterServiceInterface       // To get post details and verify ownership
+	subscriberService SubscriberServiceInterface       // To get active subscribers
+	segmentService    SegmentServiceInterface          // To target a post at the subscribers of a segment
+	postRepo          repository.PostRepository        // To move posts to sending with guarded updates, and back on failure
+	deliveryRepo      repository.DeliveryRepository    // Durable queue of outgoing emails
+	digestRepo        repository.DigestRepository      // Posts waiting for the digests of subscribers who chose one
+	suppressionRepo   repository.SuppressionRepository // Addresses that must never be sent to
+	emailService      EmailService                     // To send test copies directly
+	emailRenderer     EmailRenderer                    // To render previews with the same templates as sent emails
+	tokenSigner       *SubscriptionTokenSigner         // Signs the unsubscribe links of the deliveries
+	config            *config.Config                   // Application configuration
 }
 
 // Errors
 var ErrPostAlreadyPublished = errors.New("post already published")
 
+// Preview formats supported by PreviewPost
+const (
+	PreviewFormatHTML = "html"
+	PreviewFormatText = "text"
+)
+
+// MaxTestSendRecipients limits how many addresses a single test send may target.
+const MaxTestSendRecipients = 5
+
 // NewPublishingService creates a new PublishingService.
 func NewPublishingService(
 	newsletterService NewsletterServiceInterface,
 	subscriberService SubscriberServiceInterface,
-	emailService EmailService, // For direct email sending
+	segmentService SegmentServiceInterface,
+	postRepo repository.PostRepository,
+	deliveryRepo repository.DeliveryRepository,
+	digestRepo repository.DigestRepository,
+	suppressionRepo repository.SuppressionRepository,
+	emailService EmailService,
+	emailRenderer EmailRenderer,
+	tokenSigner *SubscriptionTokenSigner,
 	cfg *config.Config,
 ) PublishingServiceInterface {
 	return &PublishingService{
 		newsletterService: newsletterService,
 		subscriberService: subscriberService,
+		segmentService:    segmentService,
+		postRepo:          postRepo,
+		deliveryRepo:      deliveryRepo,
+		digestRepo:        digestRepo,
+		suppressionRepo:   suppressionRepo,
 		emailService:      emailService,
+		emailRenderer:     emailRenderer,
+		tokenSigner:       tokenSigner,
 		config:            cfg,
 	}
 }
 
-// PublishPostToSubscribers orchestrates the process of sending a post to all active subscribers of its newsletter.
-func (s *PublishingService) PublishPostToSubscribers(ctx context.Context, postID string, editorFirebaseUID string) error {
+// PublishPostToSubscribers orchestrates the process of sending a post to the active subscribers of its newsletter.
+// It marks the post as published and enqueues the deliveries; the emails themselves are sent asynchronously.
+func (s *PublishingService) PublishPostToSubscribers(ctx context.Context, postID string, editorFirebaseUID string, segmentID string) error {
 	// 1. Get Post details and verify ownership via NewsletterService
 	post, err := s.newsletterService.GetPostForEditor(ctx, editorFirebaseUID, postID)
 	if err != nil {
@@ -53,77 +103,330 @@ func (s *PublishingService) PublishPostToSubscribers(ctx context.Context, postID
 		return fmt.Errorf("failed to get post %s for editor %s: %w", postID, editorFirebaseUID, err)
 	}
 	if post.IsPublished() {
-		// Optional: Log or return a specific status/error if already published.
-		// For now, we proceed to ensure it's marked as published and attempt sending emails if that logic changes.
-		// However, if it is already published, we might not want to re-send emails.
-		// Let's assume for now if newsletterService.PublishPost handles this idempotently, we can call it.
-		// For safety and to avoid re-sending, let's return an error or a specific status.
-		fmt.Printf("Post %s is already published. No emails will be sent.\n", postID)
-		// Ensure it is indeed marked as published by calling PublishPost, which should be idempotent.
-		_, pubErr := s.newsletterService.PublishPost(ctx, editorFirebaseUID, postID)
-		if pubErr != nil {
-			return fmt.Errorf("failed to confirm already published post %s for editor %s: %w", postID, editorFirebaseUID, pubErr)
-		}
+		// Already published: deliveries were enqueued the first time, so don't enqueue them again.
 		return ErrPostAlreadyPublished
 	}
+	if !post.Status.CanTransitionTo(models.PostStatusSending) {
+		return fmt.Errorf("post %s is %s: %w", postID, post.Status, apperrors.ErrInvalidPostTransition)
+	}
 
-	// 2. Get active subscribers for the newsletter
-	// Use the efficient method that gets all active subscribers without pagination overhead
-	activeSubscribers, err := s.subscriberService.GetActiveSubscribersForNewsletter(ctx, post.NewsletterID)
+	// 2. Resolve the segment, which must belong to the post's newsletter
+	var segment *models.Segment
+	if segmentID != "" {
+		segment, err = s.segmentService.GetSegment(ctx, editorFirebaseUID, post.NewsletterID, segmentID)
+		if err != nil {
+			return fmt.Errorf("failed to get segment %s for post %s: %w", segmentID, postID, err)
+		}
+	}
+
+	// 3. Mark post as published before enqueueing. The update only applies while the post is still a draft or
+	// scheduled, so a concurrent publish, recall or archive wins and nothing is enqueued for it.
+	published, err := s.postRepo.SetPostPublished(ctx, postID, time.Now().UTC())
 	if err != nil {
-		return fmt.Errorf("failed to get active subscribers for newsletter %s: %w", post.NewsletterID, err)
+		if errors.Is(err, apperrors.ErrConflict) {
+			return fmt.Errorf("post %s changed while being published: %w", postID, apperrors.ErrInvalidPostTransition)
+		}
+		return fmt.Errorf("failed to mark post %s as published: %w", postID, err)
 	}
 
-	if len(activeSubscribers) == 0 {
-		fmt.Printf("No active subscribers for newsletter %s to send post %s\n", post.NewsletterID, postID)
-		// Still mark as published even if no one to send to.
-	} else {
-		fmt.Printf("Found %d active subscribers for newsletter %s. Enqueuing emails for post %s...\n", len(activeSubscribers), post.NewsletterID, postID)
-
-		// For now, send emails synchronously to ensure they work
-		// TODO: Revert to async email worker once the database issues are resolved
-		for _, subscriber := range activeSubscribers {
-			if subscriber.UnsubscribeToken == "" {
-				fmt.Printf("Warning: Subscriber %s (ID: %s) missing unsubscribe token. Skipping email for post %s.\n", subscriber.Email, subscriber.ID, postID)
-				continue
-			}
-
-			// Generate unsubscribe link and extract recipient name
-			unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.config.AppBaseURL, subscriber.UnsubscribeToken)
-			recipientName := subscriber.Email
-			if atIndex := strings.Index(subscriber.Email, "@"); atIndex > 0 {
-				recipientName = subscriber.Email[:atIndex]
-			}
-
-			// Send email directly using the email service
-			err := s.emailService.SendNewsletterIssueHTML(ctx, subscriber.Email, recipientName, post.Title, post.Content, unsubscribeLink)
-			if err != nil {
-				fmt.Printf("Warning: Failed to send email to %s for post %s: %v\n", subscriber.Email, postID, err)
-				// Continue with other subscribers instead of failing completely
-			} else {
-				fmt.Printf("Successfully sent email to %s for post %s\n", subscriber.Email, postID)
-			}
+	// 4. Enqueue one delivery per recipient
+	if err := s.enqueueDeliveries(ctx, published, segment); err != nil {
+		// Return the post to where it was, so it can be published again; enqueueing is idempotent.
+		s.undoPublish(ctx, post)
+		return err
+	}
+
+	// 5. A newsletter without recipients has nothing left to send
+	s.completeSending(ctx, postID)
+
+	return nil
+}
+
+func (s *PublishingService) PublishScheduledPost(ctx context.Context, postID string) (bool, error) {
+	// Re-read the post: it may have been published, rescheduled or cancelled since the scheduler listed it.
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get scheduled post %s: %w", postID, err)
+	}
+	if !post.IsScheduled() || post.ScheduledAt.After(time.Now()) {
+		return false, nil
+	}
+	scheduledAt := *post.ScheduledAt
+
+	// Mark the post as published before enqueueing. The update only applies while the post is still scheduled
+	// and due, so an editor cancelling or rescheduling it since the read above wins.
+	post, err = s.postRepo.SetScheduledPostPublished(ctx, postID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			return false, nil
 		}
-		fmt.Printf("Finished enqueuing %d emails for post %s.\n", len(activeSubscribers), postID)
+		return false, fmt.Errorf("failed to mark scheduled post %s as published: %w", postID, err)
+	}
+
+	if err := s.enqueueDeliveries(ctx, post, nil); err != nil {
+		// Put the post back on its schedule so the next run retries it; enqueueing is idempotent.
+		s.undoPublish(ctx, &models.Post{ID: postID, Status: models.PostStatusScheduled, ScheduledAt: &scheduledAt})
+		return false, err
 	}
+	s.completeSending(ctx, postID)
+	return true, nil
+}
 
-	// 4. Mark post as published
-	// This uses the PublishPost method from NewsletterService which should handle setting published_at.
-	_, err = s.newsletterService.PublishPost(ctx, editorFirebaseUID, postID)
+func (s *PublishingService) RecallPost(ctx context.Context, postID string, editorFirebaseUID string) (*models.Post, int, error) {
+	// Unpublishing first checks ownership and stops the worker from claiming further deliveries.
+	post, err := s.newsletterService.UnpublishPost(ctx, editorFirebaseUID, postID)
 	if err != nil {
-		// This is a critical error if we can't mark it as published.
-		return fmt.Errorf("failed to mark post %s as published: %w", postID, err)
+		return nil, 0, fmt.Errorf("failed to unpublish post %s: %w", postID, err)
+	}
+
+	// Runs even if the post was already a draft, so a recall interrupted here can simply be repeated.
+	cancelled, err := s.deliveryRepo.CancelPendingDeliveries(ctx, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to cancel deliveries for post %s: %w", postID, err)
+	}
+	cancelledDigestItems, err := s.digestRepo.CancelPendingDigestItems(ctx, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to cancel digest items for post %s: %w", postID, err)
+	}
+	fmt.Printf("Recalled post %s, cancelled %d pending deliveries and %d digest items.\n", postID, cancelled, cancelledDigestItems)
+
+	return post, cancelled, nil
+}
+
+func (s *PublishingService) SendTestPost(ctx context.Context, postID string, editorFirebaseUID string, recipients []string) ([]string, error) {
+	post, err := s.newsletterService.GetPostForEditor(ctx, editorFirebaseUID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service: SendTestPost: %w", err)
+	}
+
+	recipients, err = s.testSendRecipients(ctx, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("service: SendTestPost: %w", err)
+	}
+
+	newsletter, err := s.newsletterService.GetNewsletterByID(ctx, post.NewsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("service: SendTestPost: %w", err)
+	}
+
+	// Test recipients are not subscribers, so the unsubscribe link carries a placeholder token.
+	unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.config.AppBaseURL, "test-send")
+	for _, recipient := range recipients {
+		data := NewIssueEmailTemplateData(newsletter, post, recipient, unsubscribeLink)
+		if err := s.emailService.SendNewsletterIssueHTML(ctx, recipient, data); err != nil {
+			return nil, fmt.Errorf("service: SendTestPost: sending to %s: %w", recipient, err)
+		}
+	}
+	return recipients, nil
+}
+
+func (s *PublishingService) PreviewPost(ctx context.Context, postID string, editorFirebaseUID string, format string) (string, error) {
+	if format != PreviewFormatHTML && format != PreviewFormatText {
+		return "", fmt.Errorf("service: PreviewPost: %w: format must be %q or %q", apperrors.ErrValidation, PreviewFormatHTML, PreviewFormatText)
+	}
+
+	post, err := s.newsletterService.GetPostForEditor(ctx, editorFirebaseUID, postID)
+	if err != nil {
+		return "", fmt.Errorf("service: PreviewPost: %w", err)
+	}
+
+	newsletter, err := s.newsletterService.GetNewsletterByID(ctx, post.NewsletterID)
+	if err != nil {
+		return "", fmt.Errorf("service: PreviewPost: %w", err)
+	}
+
+	// Placeholders stand in for the per-subscriber values filled in at send time.
+	unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.config.AppBaseURL, "preview")
+	data := NewIssueEmailTemplateData(newsletter, post, "subscriber@example.com", unsubscribeLink)
+
+	rendered, err := s.emailRenderer.Render(ctx, EmailTemplateIssue, data)
+	if err != nil {
+		return "", fmt.Errorf("service: PreviewPost: %w", err)
+	}
+	if format == PreviewFormatText {
+		return HTMLToText(rendered), nil
+	}
+	return rendered, nil
+}
+
+// testSendRecipients validates and de-duplicates the requested addresses, defaulting to the editor's own email.
+func (s *PublishingService) testSendRecipients(ctx context.Context, recipients []string) ([]string, error) {
+	if len(recipients) == 0 {
+		editor, ok := middleware.GetEditorFromContext(ctx)
+		if !ok || editor.Email == "" {
+			return nil, fmt.Errorf("%w: no recipients given and editor email is unknown", apperrors.ErrValidation)
+		}
+		return []string{editor.Email}, nil
+	}
+	if len(recipients) > MaxTestSendRecipients {
+		return nil, fmt.Errorf("%w: at most %d recipients are allowed", apperrors.ErrValidation, MaxTestSendRecipients)
+	}
+
+	seen := make(map[string]bool, len(recipients))
+	unique := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		email := strings.TrimSpace(strings.ToLower(recipient))
+		if _, err := mail.ParseAddress(email); err != nil || !subscriberEmailRegex.MatchString(email) {
+			return nil, fmt.Errorf("%w '%s'", apperrors.ErrInvalidEmail, recipient)
+		}
+		if !seen[email] {
+			seen[email] = true
+			unique = append(unique, email)
+		}
+	}
+	return unique, nil
+}
+
+// undoPublish returns a post whose deliveries could not be enqueued from sending to the draft or schedule it was
+// published from. Deliveries enqueued before the failure stay queued, but are not claimed until the post is sending
+// again. A failure here is only logged; the enqueueing error is what the caller reports.
+func (s *PublishingService) undoPublish(ctx context.Context, previous *models.Post) {
+	var err error
+	if previous.IsScheduled() && previous.ScheduledAt != nil {
+		_, err = s.postRepo.RestorePostSchedule(ctx, previous.ID, *previous.ScheduledAt)
+	} else {
+		_, err = s.postRepo.RestorePostDraft(ctx, previous.ID)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to return post %s to %s: %v\n", previous.ID, previous.Status, err)
+	}
+}
+
+// completeSending moves the post out of sending if no delivery is pending. Otherwise the
+// delivery worker does so once the last delivery is finished, so a failure here is only logged.
+func (s *PublishingService) completeSending(ctx context.Context, postID string) {
+	if err := s.postRepo.CompletePostSending(ctx, postID); err != nil {
+		fmt.Printf("Warning: failed to complete sending of post %s: %v\n", postID, err)
+	}
+}
+
+// enqueueDeliveries queues one delivery per active subscriber of the post's newsletter, or adds the post
+// to the next digest of subscribers who chose a digest frequency. Paused subscribers get nothing.
+// A non-nil segment limits the recipients to the subscribers matching it.
+// Deliveries capture the subscriber's name and attributes for the merge tags of the post.
+func (s *PublishingService) enqueueDeliveries(ctx context.Context, post *models.Post, segment *models.Segment) error {
+	var activeSubscribers []models.Subscriber
+	var err error
+	if segment != nil {
+		activeSubscribers, err = s.segmentService.ResolveSegmentSubscribers(ctx, segment)
+	} else {
+		// Use the efficient method that gets all active subscribers without pagination overhead
+		activeSubscribers, err = s.subscriberService.GetActiveSubscribersForNewsletter(ctx, post.NewsletterID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get active subscribers for newsletter %s: %w", post.NewsletterID, err)
+	}
+
+	// Addresses suppressed after they subscribed are skipped here, so the suppression list always has the last word.
+	emails := make([]string, 0, len(activeSubscribers))
+	for _, subscriber := range activeSubscribers {
+		emails = append(emails, strings.ToLower(subscriber.Email))
+	}
+	suppressedEmails, err := s.suppressionRepo.FilterSuppressedEmails(ctx, post.NewsletterID, emails)
+	if err != nil {
+		return fmt.Errorf("failed to check the suppression list for newsletter %s: %w", post.NewsletterID, err)
+	}
+	suppressed := make(map[string]bool, len(suppressedEmails))
+	for _, email := range suppressedEmails {
+		suppressed[email] = true
 	}
 
-	fmt.Printf("Post %s successfully marked as published.\n", postID)
-	// The actual email sending is asynchronous. The error from this function now primarily relates to
-	// fetching data or marking the post as published, not individual email send failures.
-	// Those are handled by the worker.
+	now := time.Now().UTC()
+	deliveries := make([]models.Delivery, 0, len(activeSubscribers))
+	var digestItems []models.DigestItem
+	paused := 0
+	for _, subscriber := range activeSubscribers {
+		// Pending double opt-in subscribers have not confirmed they want the newsletter yet.
+		if subscriber.Status != models.SubscriberStatusActive {
+			continue
+		}
+		if suppressed[strings.ToLower(subscriber.Email)] {
+			continue
+		}
+		// Issues published during a pause are skipped rather than sent when it ends.
+		if subscriber.IsPaused(now) {
+			paused++
+			continue
+		}
+		unsubscribeToken := s.tokenSigner.Sign(TokenPurposeUnsubscribe, subscriber.ID, post.NewsletterID)
+		if subscriber.Frequency.IsDigest() {
+			digestItems = append(digestItems, models.DigestItem{
+				PostID:           post.ID,
+				SubscriberID:     subscriber.ID,
+				Email:            subscriber.Email,
+				UnsubscribeToken: unsubscribeToken,
+				DueAt:            subscriber.Frequency.NextDigestAt(now),
+			})
+			continue
+		}
+		deliveries = append(deliveries, models.Delivery{
+			PostID:               post.ID,
+			SubscriberID:         subscriber.ID,
+			Email:                subscriber.Email,
+			SubscriberName:       subscriber.Name,
+			SubscriberAttributes: subscriber.Attributes,
+			UnsubscribeToken:     unsubscribeToken,
+		})
+	}
+
+	// Enqueueing is idempotent per (post, subscriber), so a retry after a later failure won't double-send.
+	enqueued, err := s.deliveryRepo.EnqueueDeliveries(ctx, post.ID, deliveries)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue deliveries for post %s: %w", post.ID, err)
+	}
+	digested, err := s.digestRepo.EnqueueDigestItems(ctx, post.ID, digestItems)
+	if err != nil {
+		return fmt.Errorf("failed to add post %s to digests: %w", post.ID, err)
+	}
+	audience := "newsletter " + post.NewsletterID
+	if segment != nil {
+		audience = fmt.Sprintf("newsletter %s, segment %s", post.NewsletterID, segment.ID)
+	}
+	fmt.Printf("Enqueued %d deliveries and %d digest items for post %s (%s), skipped %d suppressed and %d paused.\n",
+		enqueued, digested, post.ID, audience, len(suppressedEmails), paused)
 	return nil
 }
 
-// GetPostForPublishingRequest and Response would be part of NewsletterService if we define it there.
-// For now, assuming NewsletterService has a method like:
-// GetPostForPublishing(ctx context.Context, postID string, editorFirebaseUID string) (*models.Post, error)
-// which returns the post if the editor owns it and it's not already published.
-// And models.Post has fields like NewsletterID, Title, Body.
+// ListDeliveries returns a page of deliveries for a post owned by the editor.
+// An empty status lists deliveries in every state.
+func (s *PublishingService) ListDeliveries(ctx context.Context, editorFirebaseUID string, postID string, status models.DeliveryStatus, limit int, offset int) ([]models.Delivery, int, error) {
+	if status != "" && !status.IsValid() {
+		return nil, 0, apperrors.WrapValidation(nil, fmt.Sprintf("unknown delivery status %q", status))
+	}
+
+	if _, err := s.newsletterService.GetPostForEditor(ctx, editorFirebaseUID, postID); err != nil {
+		return nil, 0, fmt.Errorf("service: ListDeliveries: %w", err)
+	}
+
+	deliveries, total, err := s.deliveryRepo.ListDeliveriesByPostID(ctx, postID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service: ListDeliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// GetDeliverySummary returns per-status delivery counts for a post owned by the editor.
+func (s *PublishingService) GetDeliverySummary(ctx context.Context, editorFirebaseUID string, postID string) (*models.DeliverySummary, error) {
+	if _, err := s.newsletterService.GetPostForEditor(ctx, editorFirebaseUID, postID); err != nil {
+		return nil, fmt.Errorf("service: GetDeliverySummary: %w", err)
+	}
+
+	counts, err := s.deliveryRepo.CountDeliveriesByStatus(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetDeliverySummary: %w", err)
+	}
+
+	summary := &models.DeliverySummary{
+		PostID:            postID,
+		Queued:            counts[models.DeliveryStatusQueued],
+		Processing:        counts[models.DeliveryStatusProcessing],
+		Sent:              counts[models.DeliveryStatusSent],
+		Failed:            counts[models.DeliveryStatusFailed],
+		PermanentlyFailed: counts[models.DeliveryStatusPermanentlyFailed],
+		Cancelled:         counts[models.DeliveryStatusCancelled],
+	}
+	for _, count := range counts {
+		summary.Total += count
+	}
+	return summary, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+func TestPublishingService_TestSendRecipients(t *testing.T) {
+	editorCtx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456", Email: "editor@example.com"})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		recipients    []string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "defaults to the editor",
+			ctx:      editorCtx,
+			expected: []string{"editor@example.com"},
+		},
+		{
+			name:       "normalizes and de-duplicates addresses",
+			ctx:        editorCtx,
+			recipients: []string{"Reviewer@Example.com", "reviewer@example.com ", "qa@example.com"},
+			expected:   []string{"reviewer@example.com", "qa@example.com"},
+		},
+		{
+			name:          "rejects more than five addresses",
+			ctx:           editorCtx,
+			recipients:    []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com", "f@example.com"},
+			expectedError: "at most 5 recipients",
+		},
+		{
+			name:          "rejects invalid address",
+			ctx:           editorCtx,
+			recipients:    []string{"not-an-email"},
+			expectedError: "invalid email format",
+		},
+		{
+			name:          "no recipients and no editor in context",
+			ctx:           context.Background(),
+			expectedError: "editor email is unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PublishingService{}
+			recipients, err := s.testSendRecipients(tt.ctx, tt.recipients)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, recipients)
+		})
+	}
+}
+
+func TestPublishingService_PublishPostToSubscribers_ChangedMeanwhile(t *testing.T) {
+	postRepo := &MockPostRepository{}
+	newsletterRepo := &MockNewsletterRepository{}
+	postRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusDraft}, nil)
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+		Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+	// The post was archived after it was read, so the guarded update changes nothing.
+	postRepo.On("SetPostPublished", mock.Anything, "post_123", mock.AnythingOfType("time.Time")).
+		Return(nil, apperrors.ErrConflict)
+
+	s := &PublishingService{
+		newsletterService: NewNewsletterService(newsletterRepo, postRepo, &MockSubscriberService{}),
+		postRepo:          postRepo,
+	}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+	err := s.PublishPostToSubscribers(ctx, "post_123", "editor_456", "")
+
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPostTransition)
+	postRepo.AssertExpectations(t) // Nothing is enqueued: the subscriber service is never called
+}
+
+func TestPublishingService_PublishPostToSubscribers_RestoresDraftOnFailure(t *testing.T) {
+	postRepo := &MockPostRepository{}
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberService := &MockSubscriberService{}
+	postRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusDraft}, nil)
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "newsletter_123").
+		Return(&models.Newsletter{ID: "newsletter_123", EditorID: "editor_456"}, nil)
+	postRepo.On("SetPostPublished", mock.Anything, "post_123", mock.AnythingOfType("time.Time")).
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusSending}, nil)
+	subscriberService.On("GetActiveSubscribersForNewsletter", mock.Anything, "newsletter_123").
+		Return([]models.Subscriber(nil), errors.New("firestore unavailable"))
+	postRepo.On("RestorePostDraft", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", Status: models.PostStatusDraft}, nil).Once()
+
+	s := &PublishingService{
+		newsletterService: NewNewsletterService(newsletterRepo, postRepo, subscriberService),
+		subscriberService: subscriberService,
+		postRepo:          postRepo,
+	}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor_456"})
+	err := s.PublishPostToSubscribers(ctx, "post_123", "editor_456", "")
+
+	assert.Error(t, err)
+	postRepo.AssertExpectations(t)
+}
+
+func TestPublishingService_PublishScheduledPost_NoLongerScheduled(t *testing.T) {
+	due := time.Now().UTC().Add(-time.Minute)
+	postRepo := &MockPostRepository{}
+	postRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusScheduled, ScheduledAt: &due}, nil)
+	// The editor cancelled the schedule after the post was read, so the guarded update changes nothing.
+	postRepo.On("SetScheduledPostPublished", mock.Anything, "post_123", mock.AnythingOfType("time.Time")).
+		Return(nil, apperrors.ErrConflict)
+
+	s := &PublishingService{postRepo: postRepo}
+	published, err := s.PublishScheduledPost(context.Background(), "post_123")
+
+	assert.NoError(t, err)
+	assert.False(t, published)
+	postRepo.AssertExpectations(t) // Nothing is enqueued: the subscriber service is never called
+}
+
+func TestPublishingService_PublishScheduledPost_RestoresScheduleOnFailure(t *testing.T) {
+	due := time.Now().UTC().Add(-time.Minute)
+	postRepo := &MockPostRepository{}
+	subscriberService := &MockSubscriberService{}
+	postRepo.On("GetPostByID", mock.Anything, "post_123").
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusScheduled, ScheduledAt: &due}, nil)
+	postRepo.On("SetScheduledPostPublished", mock.Anything, "post_123", mock.AnythingOfType("time.Time")).
+		Return(&models.Post{ID: "post_123", NewsletterID: "newsletter_123", Status: models.PostStatusSending}, nil)
+	subscriberService.On("GetActiveSubscribersForNewsletter", mock.Anything, "newsletter_123").
+		Return([]models.Subscriber(nil), errors.New("firestore unavailable"))
+	postRepo.On("RestorePostSchedule", mock.Anything, "post_123", due).
+		Return(&models.Post{ID: "post_123", Status: models.PostStatusScheduled, ScheduledAt: &due}, nil).Once()
+
+	s := &PublishingService{postRepo: postRepo, subscriberService: subscriberService}
+	published, err := s.PublishScheduledPost(context.Background(), "post_123")
+
+	assert.Error(t, err)
+	assert.False(t, published)
+	postRepo.AssertExpectations(t)
+}
+
+func TestPublishingService_PreviewPost_InvalidFormat(t *testing.T) {
+	s := &PublishingService{}
+	_, err := s.PreviewPost(context.Background(), "post_123", "editor_456", "pdf")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "format must be")
+}
+
+func TestHTMLToText(t *testing.T) {
+	text := HTMLToText("<html><head><title>Weekly</title></head><body>\n\t<h1>Weekly</h1>\n\n\n\t<p>Hello &amp; <b>welcome</b></p>\n</body></html>")
+
+	assert.Equal(t, "Weekly\n\nHello & welcome\n", text)
+}
+
+func TestHTMLToText_LinkFootnotes(t *testing.T) {
+	text := HTMLToText(`<p>Read <a href="https://example.com/a?x=1&amp;y=2">the <b>post</b></a> or <a href="https://example.com/b">this</a>.<br>` +
+		`Again: <a href="https://example.com/a?x=1&amp;y=2">post</a>, <a href="https://example.com/c">https://example.com/c</a></p>`)
+
+	assert.Equal(t, "Read the post [1] or this [2].\n"+
+		"Again: post [1], https://example.com/c\n\n"+
+		"Links:\n[1] https://example.com/a?x=1&y=2\n[2] https://example.com/b\n", text)
+}
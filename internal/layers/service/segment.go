@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// SegmentServiceInterface defines the operations for managing a newsletter's saved segments.
+type SegmentServiceInterface interface {
+	ListSegments(ctx context.Context, editorID string, newsletterID string) ([]models.Segment, error)
+	// CreateSegment validates and stores a segment of the newsletter. Only its name, description, match and rules are used.
+	CreateSegment(ctx context.Context, editorID string, newsletterID string, segment models.Segment) (*models.Segment, error)
+	GetSegment(ctx context.Context, editorID string, newsletterID string, segmentID string) (*models.Segment, error)
+	// UpdateSegment replaces the name, description, match and rules of a segment.
+	UpdateSegment(ctx context.Context, editorID string, newsletterID string, segmentID string, segment models.Segment) (*models.Segment, error)
+	DeleteSegment(ctx context.Context, editorID string, newsletterID string, segmentID string) error
+	// CountSegmentSubscribers returns how many subscribers a post published to the segment now would go to,
+	// before suppressions and pauses are applied.
+	CountSegmentSubscribers(ctx context.Context, editorID string, newsletterID string, segmentID string) (int, error)
+	// ResolveSegmentSubscribers returns the active subscribers of the segment's newsletter who match it.
+	// It is called when publishing, after ownership was checked, so it performs no check of its own.
+	ResolveSegmentSubscribers(ctx context.Context, segment *models.Segment) ([]models.Subscriber, error)
+}
+
+type segmentService struct {
+	newsletterService NewsletterServiceInterface // For newsletter ownership checks and subscriber fields
+	segmentRepo       repository.SegmentRepository
+	subscriberRepo    repository.SubscriberRepository // Subscribers the rules are evaluated against
+	deliveryRepo      repository.DeliveryRepository   // Delivery history for engagement rules
+}
+
+// NewSegmentService creates a new segment service.
+func NewSegmentService(
+	newsletterService NewsletterServiceInterface,
+	segmentRepo repository.SegmentRepository,
+	subscriberRepo repository.SubscriberRepository,
+	deliveryRepo repository.DeliveryRepository,
+) SegmentServiceInterface {
+	return &segmentService{
+		newsletterService: newsletterService,
+		segmentRepo:       segmentRepo,
+		subscriberRepo:    subscriberRepo,
+		deliveryRepo:      deliveryRepo,
+	}
+}
+
+func (s *segmentService) ListSegments(ctx context.Context, editorID string, newsletterID string) ([]models.Segment, error) {
+	if _, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID); err != nil {
+		return nil, fmt.Errorf("service: ListSegments: %w", err)
+	}
+	segments, err := s.segmentRepo.ListSegmentsByNewsletterID(ctx, newsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("service: ListSegments: %w", err)
+	}
+	return segments, nil
+}
+
+func (s *segmentService) CreateSegment(ctx context.Context, editorID string, newsletterID string, segment models.Segment) (*models.Segment, error) {
+	newsletter, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("service: CreateSegment: %w", err)
+	}
+	if err := segment.Normalize(newsletter.SubscriberFields); err != nil {
+		return nil, fmt.Errorf("service: CreateSegment: %w", err)
+	}
+
+	segment.NewsletterID = newsletterID
+	created, err := s.segmentRepo.CreateSegment(ctx, segment)
+	if err != nil {
+		return nil, fmt.Errorf("service: CreateSegment: %w", err)
+	}
+	return created, nil
+}
+
+func (s *segmentService) GetSegment(ctx context.Context, editorID string, newsletterID string, segmentID string) (*models.Segment, error) {
+	segment, _, err := s.getForEditor(ctx, editorID, newsletterID, segmentID)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetSegment: %w", err)
+	}
+	return segment, nil
+}
+
+func (s *segmentService) UpdateSegment(ctx context.Context, editorID string, newsletterID string, segmentID string, segment models.Segment) (*models.Segment, error) {
+	_, newsletter, err := s.getForEditor(ctx, editorID, newsletterID, segmentID)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSegment: %w", err)
+	}
+	if err := segment.Normalize(newsletter.SubscriberFields); err != nil {
+		return nil, fmt.Errorf("service: UpdateSegment: %w", err)
+	}
+
+	segment.ID = segmentID
+	segment.NewsletterID = newsletterID
+	updated, err := s.segmentRepo.UpdateSegment(ctx, segment)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSegment: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *segmentService) DeleteSegment(ctx context.Context, editorID string, newsletterID string, segmentID string) error {
+	if _, _, err := s.getForEditor(ctx, editorID, newsletterID, segmentID); err != nil {
+		return fmt.Errorf("service: DeleteSegment: %w", err)
+	}
+	if err := s.segmentRepo.DeleteSegment(ctx, segmentID); err != nil {
+		return fmt.Errorf("service: DeleteSegment: %w", err)
+	}
+	return nil
+}
+
+func (s *segmentService) CountSegmentSubscribers(ctx context.Context, editorID string, newsletterID string, segmentID string) (int, error) {
+	segment, _, err := s.getForEditor(ctx, editorID, newsletterID, segmentID)
+	if err != nil {
+		return 0, fmt.Errorf("service: CountSegmentSubscribers: %w", err)
+	}
+	subscribers, err := s.ResolveSegmentSubscribers(ctx, segment)
+	if err != nil {
+		return 0, fmt.Errorf("service: CountSegmentSubscribers: %w", err)
+	}
+	return len(subscribers), nil
+}
+
+func (s *segmentService) ResolveSegmentSubscribers(ctx context.Context, segment *models.Segment) ([]models.Subscriber, error) {
+	// Firestore cannot evaluate the rules, so every active subscriber is matched here.
+	subscribers, err := s.subscriberRepo.GetAllActiveSubscribersByNewsletterID(ctx, segment.NewsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("resolving segment %s: %w", segment.ID, err)
+	}
+
+	now := time.Now().UTC()
+	engagement := make(models.SegmentEngagement)
+	for i, rule := range segment.Rules {
+		if rule.Field != models.SegmentRuleFieldEngagement {
+			continue
+		}
+		metric := models.SegmentEngagementMetric(rule.Key)
+		counts, err := s.deliveryRepo.CountSubscriberDeliveriesSince(ctx, segment.NewsletterID, metric.DeliveryStatus(), rule.EngagementSince(now))
+		if err != nil {
+			return nil, fmt.Errorf("resolving segment %s: counting deliveries: %w", segment.ID, err)
+		}
+		engagement[i] = counts
+	}
+
+	matching := make([]models.Subscriber, 0, len(subscribers))
+	for i := range subscribers {
+		if segment.Matches(&subscribers[i], engagement, now) {
+			matching = append(matching, subscribers[i])
+		}
+	}
+	return matching, nil
+}
+
+// getForEditor fetches a segment of a newsletter owned by the editor, along with the newsletter.
+// Segments of other newsletters are reported as not found.
+func (s *segmentService) getForEditor(ctx context.Context, editorID string, newsletterID string, segmentID string) (*models.Segment, *models.Newsletter, error) {
+	newsletter, err := s.newsletterService.GetNewsletterForEditor(ctx, editorID, newsletterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	segment, err := s.segmentRepo.GetSegmentByID(ctx, segmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if segment.NewsletterID != newsletterID {
+		return nil, nil, apperrors.ErrSegmentNotFound
+	}
+	return segment, newsletter, nil
+}
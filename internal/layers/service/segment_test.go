@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockSegmentRepository mocks the segment repository
+type MockSegmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockSegmentRepository) CreateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error) {
+	args := m.Called(ctx, segment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) GetSegmentByID(ctx context.Context, segmentID string) (*models.Segment, error) {
+	args := m.Called(ctx, segmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) ListSegmentsByNewsletterID(ctx context.Context, newsletterID string) ([]models.Segment, error) {
+	args := m.Called(ctx, newsletterID)
+	return args.Get(0).([]models.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) UpdateSegment(ctx context.Context, segment models.Segment) (*models.Segment, error) {
+	args := m.Called(ctx, segment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Segment), args.Error(1)
+}
+
+func (m *MockSegmentRepository) DeleteSegment(ctx context.Context, segmentID string) error {
+	args := m.Called(ctx, segmentID)
+	return args.Error(0)
+}
+
+// engagementDeliveryRepository mocks the delivery counts used by engagement rules.
+// The other methods of the repository are not used by the segment service.
+type engagementDeliveryRepository struct {
+	repository.DeliveryRepository
+	mock.Mock
+}
+
+func (m *engagementDeliveryRepository) CountSubscriberDeliveriesSince(ctx context.Context, newsletterID string, status models.DeliveryStatus, since time.Time) (map[string]int, error) {
+	args := m.Called(ctx, newsletterID, status, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func newTestSegmentService(newsletterRepo *MockNewsletterRepository, segmentRepo *MockSegmentRepository, subscriberRepo *MockSubscriberRepository, deliveryRepo *engagementDeliveryRepository) SegmentServiceInterface {
+	newsletterService := NewNewsletterService(newsletterRepo, &MockPostRepository{}, &MockSubscriberService{})
+	return NewSegmentService(newsletterService, segmentRepo, subscriberRepo, deliveryRepo)
+}
+
+func TestSegmentService_CreateSegment(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	segmentRepo := &MockSegmentRepository{}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor-1"})
+
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", EditorID: "editor-1",
+		SubscriberFields: models.SubscriberFields{{Key: "plan", Type: models.SubscriberFieldTypeText}}}, nil)
+	segmentRepo.On("CreateSegment", mock.Anything, mock.MatchedBy(func(s models.Segment) bool {
+		return s.NewsletterID == "nl-1" && s.Name == "Pro" && s.Match == models.SegmentMatchAll && s.Rules[0].Value == "pro"
+	})).Return(&models.Segment{ID: "seg-1", NewsletterID: "nl-1", Name: "Pro"}, nil).Once()
+
+	svc := newTestSegmentService(newsletterRepo, segmentRepo, nil, nil)
+
+	segment, err := svc.CreateSegment(ctx, "auth-1", "nl-1", models.Segment{Name: " Pro ", Rules: []models.SegmentRule{
+		{Field: models.SegmentRuleFieldAttribute, Operator: models.SegmentOperatorEquals, Key: "plan", Value: " pro "},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "seg-1", segment.ID)
+
+	_, err = svc.CreateSegment(ctx, "auth-1", "nl-1", models.Segment{Name: "Seats", Rules: []models.SegmentRule{
+		{Field: models.SegmentRuleFieldAttribute, Operator: models.SegmentOperatorGreater, Key: "seats", Value: "10"},
+	}})
+	assert.True(t, apperrors.IsValidation(err), "seats is not a subscriber field: got %v", err)
+
+	otherCtx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor-2"})
+	_, err = svc.CreateSegment(otherCtx, "auth-2", "nl-1", models.Segment{Name: "Pro"})
+	assert.True(t, errors.Is(err, apperrors.ErrForbidden), "got %v", err)
+
+	segmentRepo.AssertExpectations(t)
+}
+
+func TestSegmentService_GetSegment_OtherNewsletter(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	segmentRepo := &MockSegmentRepository{}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor-1"})
+
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", EditorID: "editor-1"}, nil)
+	segmentRepo.On("GetSegmentByID", mock.Anything, "seg-2").Return(&models.Segment{ID: "seg-2", NewsletterID: "nl-2"}, nil)
+
+	svc := newTestSegmentService(newsletterRepo, segmentRepo, nil, nil)
+
+	_, err := svc.GetSegment(ctx, "auth-1", "nl-1", "seg-2")
+	assert.True(t, errors.Is(err, apperrors.ErrSegmentNotFound), "got %v", err)
+}
+
+func TestSegmentService_ResolveSegmentSubscribers(t *testing.T) {
+	subscriberRepo := &MockSubscriberRepository{}
+	deliveryRepo := &engagementDeliveryRepository{}
+
+	subscriberRepo.On("GetAllActiveSubscribersByNewsletterID", mock.Anything, "nl-1").Return([]models.Subscriber{
+		{ID: "sub-1", Tags: []string{"vip"}},
+		{ID: "sub-2", Tags: []string{"vip"}},
+		{ID: "sub-3"},
+	}, nil)
+	deliveryRepo.On("CountSubscriberDeliveriesSince", mock.Anything, "nl-1", models.DeliveryStatusSent, mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) > 29*24*time.Hour && time.Since(since) < 31*24*time.Hour
+	})).Return(map[string]int{"sub-1": 4, "sub-2": 1, "sub-3": 9}, nil).Once()
+
+	svc := newTestSegmentService(nil, nil, subscriberRepo, deliveryRepo)
+
+	// VIPs who received at least two posts over the last 30 days.
+	subscribers, err := svc.ResolveSegmentSubscribers(context.Background(), &models.Segment{
+		ID:           "seg-1",
+		NewsletterID: "nl-1",
+		Match:        models.SegmentMatchAll,
+		Rules: []models.SegmentRule{
+			{Field: models.SegmentRuleFieldTag, Operator: models.SegmentOperatorHas, Value: "vip"},
+			{Field: models.SegmentRuleFieldEngagement, Operator: models.SegmentOperatorAtLeast, Key: "received", Value: "2", Days: 30},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, subscribers, 1)
+	assert.Equal(t, "sub-1", subscribers[0].ID)
+	deliveryRepo.AssertExpectations(t)
+}
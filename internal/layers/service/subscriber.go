@@ -5,6 +5,7 @@ import (
 	"errors" // For basic error creation
 	"fmt"
 	"net/mail"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -13,7 +14,6 @@ import (
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
-	"github.com/google/uuid"
 )
 
 // Note: All error definitions have been moved to internal/errors package for centralization.
@@ -31,8 +31,22 @@ const (
 // SubscriberServiceInterface defines the operations for subscriber management.
 // Note: The EmailServiceInterface dependency is implicitly expected by NewSubscriberService.
 type SubscriberServiceInterface interface {
-	SubscribeToNewsletter(ctx context.Context, email, newsletterID string) (*models.Subscriber, error)
+	// SubscribeToNewsletter subscribes the address with the given profile, whose attributes must match the
+	// newsletter's subscriber fields.
+	SubscribeToNewsletter(ctx context.Context, email, newsletterID string, profile models.SubscriberProfile) (*models.Subscriber, error)
+	// ImportSubscribers adds existing subscribers to a newsletter owned by the editor, or updates the name,
+	// attributes and tags of those already subscribed.
+	ImportSubscribers(ctx context.Context, editorAuthID string, newsletterID string, subscribers []models.SubscriberImport) (*models.SubscriberImportResult, error)
+	// UpdateSubscriberTags replaces the tags of a subscriber of a newsletter owned by the editor.
+	UpdateSubscriberTags(ctx context.Context, editorAuthID string, newsletterID string, subscriberID string, tags []string) (*models.Subscriber, error)
+	// ConfirmSubscription activates a pending double opt-in subscription using the token of its confirmation link.
+	ConfirmSubscription(ctx context.Context, token string) (*models.Subscriber, error)
 	UnsubscribeByToken(ctx context.Context, token string) error
+	// GetPreferences returns the preferences of the subscriber an unsubscribe token was issued for, covering
+	// every newsletter their address is subscribed to.
+	GetPreferences(ctx context.Context, token string) (*models.SubscriberPreferences, error)
+	// UpdatePreferences applies the changes to the subscriptions of the token's address and returns the result.
+	UpdatePreferences(ctx context.Context, token string, update models.SubscriberPreferencesUpdate) (*models.SubscriberPreferences, error)
 	ListActiveSubscribersByNewsletterID(ctx context.Context, editorAuthID string, newsletterID string, limit, offset int) ([]models.Subscriber, int, error)
 	GetActiveSubscribersForNewsletter(ctx context.Context, newsletterID string) ([]models.Subscriber, error)
 	DeleteAllSubscribersByNewsletterID(ctx context.Context, newsletterID string) error
@@ -40,11 +54,15 @@ type SubscriberServiceInterface interface {
 
 // SubscriberService manages subscriber operations for newsletters.
 type SubscriberService struct {
-	subscriberRepo repository.SubscriberRepository
-	newsletterRepo repository.NewsletterRepository
-	editorRepo     repository.EditorRepository // For authorization
-	emailService   EmailService                 // Use direct email service instead of email worker
-	appBaseURL     string                      // For generating unsubscribe links, e.g., "http://localhost:8080"
+	subscriberRepo  repository.SubscriberRepository
+	newsletterRepo  repository.NewsletterRepository
+	editorRepo      repository.EditorRepository      // For authorization
+	suppressionRepo repository.SuppressionRepository // To refuse suppressed addresses
+	emailService    EmailService                     // Use direct email service instead of email worker
+	appBaseURL      string                           // For generating unsubscribe links, e.g., "http://localhost:8080"
+	tokenSigner     *SubscriptionTokenSigner         // Signs the confirmation and unsubscribe links
+	confirmationTTL time.Duration                    // How long a confirmation link stays valid
+	unsubscribeTTL  time.Duration                    // How long an unsubscribe link stays valid, zero for ever
 }
 
 // NewSubscriberService creates a new SubscriberService.
@@ -52,15 +70,23 @@ func NewSubscriberService(
 	subRepo repository.SubscriberRepository,
 	newsRepo repository.NewsletterRepository,
 	editorRepo repository.EditorRepository,
+	suppressionRepo repository.SuppressionRepository,
 	emailService EmailService, // Use direct email service instead of email worker
 	appBaseURL string,
+	tokenSigner *SubscriptionTokenSigner,
+	confirmationTTL time.Duration,
+	unsubscribeTTL time.Duration,
 ) SubscriberServiceInterface {
 	return &SubscriberService{
-		subscriberRepo: subRepo,
-		newsletterRepo: newsRepo,
-		editorRepo:     editorRepo,
-		emailService:   emailService,
-		appBaseURL:     appBaseURL,
+		subscriberRepo:  subRepo,
+		newsletterRepo:  newsRepo,
+		editorRepo:      editorRepo,
+		suppressionRepo: suppressionRepo,
+		emailService:    emailService,
+		appBaseURL:      appBaseURL,
+		tokenSigner:     tokenSigner,
+		confirmationTTL: confirmationTTL,
+		unsubscribeTTL:  unsubscribeTTL,
 	}
 }
 
@@ -79,8 +105,10 @@ type SubscribeToNewsletterResponse struct {
 }
 
 // SubscribeToNewsletter processes a subscription request.
-// It creates a new subscriber record with a pending_confirmation status and triggers a confirmation email.
-func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, newsletterID string) (*models.Subscriber, error) {
+// For single opt-in newsletters the subscriber is active right away and gets a confirmation email. For double
+// opt-in newsletters the subscriber is pending_confirmation and gets an email with a link to ConfirmSubscription.
+// Subscribing again with a profile updates the name and attributes of the existing subscription.
+func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, newsletterID string, profile models.SubscriberProfile) (*models.Subscriber, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 	newsletterID = strings.TrimSpace(newsletterID)
 
@@ -102,41 +130,80 @@ func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, ne
 		return nil, fmt.Errorf("service: SubscribeToNewsletter: checking newsletter: %w", err)
 	}
 
+	profile, err = normalizeSubscriberProfile(newsletter.SubscriberFields, profile)
+	if err != nil {
+		return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", err)
+	}
+
+	suppressed, err := s.suppressionRepo.FilterSuppressedEmails(ctx, newsletterID, []string{email})
+	if err != nil {
+		return nil, fmt.Errorf("service: SubscribeToNewsletter: checking suppression list: %w", err)
+	}
+	if len(suppressed) > 0 {
+		return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", apperrors.ErrAddressSuppressed)
+	}
+
 	existingSub, err := s.subscriberRepo.GetSubscriberByEmailAndNewsletterID(ctx, email, newsletterID)
 	if err != nil && !errors.Is(err, apperrors.ErrSubscriberNotFound) {
 		return nil, fmt.Errorf("service: SubscribeToNewsletter: checking existing subscription: %w", err)
 	}
 
-	unsubscribeToken := uuid.NewString()
 	now := time.Now().UTC()
 
+	// Double opt-in subscribers stay pending until they confirm through the emailed link.
+	doubleOptIn := newsletter.OptInMode == models.OptInModeDouble
+	status := models.SubscriberStatusActive
+	if doubleOptIn {
+		status = models.SubscriberStatusPendingConfirmation
+	}
+
 	if existingSub != nil {
 		if existingSub.Status == models.SubscriberStatusActive {
 			return nil, fmt.Errorf("service: SubscribeToNewsletter: %w: email '%s' is already actively subscribed to newsletter '%s'", apperrors.ErrConflict, email, newsletter.Name)
 		}
-		if existingSub.Status == models.SubscriberStatusUnsubscribed {
-			existingSub.Status = models.SubscriberStatusActive
+		// A spam complaint is final: the subscriber asked their mailbox provider to stop the mail.
+		if existingSub.Status == models.SubscriberStatusComplained {
+			return nil, fmt.Errorf("service: SubscribeToNewsletter: %w: email '%s' reported newsletter '%s' as spam and cannot subscribe again", apperrors.ErrConflict, email, newsletter.Name)
+		}
+		if _, err := s.mergeSubscriberProfile(ctx, newsletter.SubscriberFields, existingSub, profile); err != nil {
+			return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", err)
+		}
+		// Subscribing again while pending sends a new confirmation link, e.g. after the first one expired.
+		if existingSub.Status == models.SubscriberStatusPendingConfirmation && doubleOptIn {
+			if err := s.sendOptInEmail(ctx, newsletter, existingSub); err != nil {
+				return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", err)
+			}
+			return existingSub, nil
+		}
+		// Subscribing again also lifts a bounce suppression; a new hard bounce suppresses the address again.
+		// Pending subscribers of a newsletter switched to single opt-in are activated as well.
+		if existingSub.Status == models.SubscriberStatusUnsubscribed || existingSub.Status == models.SubscriberStatusBounced ||
+			existingSub.Status == models.SubscriberStatusPendingConfirmation {
+			wasPending := existingSub.Status == models.SubscriberStatusPendingConfirmation
+			existingSub.Status = status
 			existingSub.SubscriptionDate = now
-			existingSub.UnsubscribeToken = unsubscribeToken
 
-			if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, existingSub.ID, models.SubscriberStatusActive); err != nil {
+			if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, existingSub.ID, status); err != nil {
 				return nil, fmt.Errorf("service: SubscribeToNewsletter: reactivating subscriber status: %w", err)
 			}
-			if err := s.subscriberRepo.UpdateSubscriberUnsubscribeToken(ctx, existingSub.ID, unsubscribeToken); err != nil {
-				return nil, fmt.Errorf("service: SubscribeToNewsletter: updating token for reactivated subscriber: %w", err)
-			}
 
-			// Generate unsubscribe link and extract recipient name
-			unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.appBaseURL, unsubscribeToken)
-			recipientName := email
-			if atIndex := strings.Index(email, "@"); atIndex > 0 {
-				recipientName = email[:atIndex]
+			if doubleOptIn {
+				if err := s.sendOptInEmail(ctx, newsletter, existingSub); err != nil {
+					return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", err)
+				}
+				return existingSub, nil
 			}
 
-			// Send confirmation email directly
-			err := s.emailService.SendConfirmationEmailHTML(ctx, existingSub.Email, recipientName, unsubscribeLink)
+			// Send welcome back email directly, or the confirmation email to a subscriber who never got one
+			data := s.subscriberEmailData(newsletter, existingSub)
+			var err error
+			if wasPending {
+				err = s.emailService.SendConfirmationEmailHTML(ctx, existingSub.Email, data)
+			} else {
+				err = s.emailService.SendWelcomeBackEmailHTML(ctx, existingSub.Email, data)
+			}
 			if err != nil {
-				fmt.Printf("Warning: Failed to send confirmation email to subscriber %s: %v\n", existingSub.Email, err)
+				fmt.Printf("Warning: Failed to send welcome email to subscriber %s: %v\n", existingSub.Email, err)
 			}
 
 			// Return a model representing the updated state.
@@ -150,8 +217,14 @@ func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, ne
 		Email:            email,
 		NewsletterID:     newsletterID,
 		SubscriptionDate: now,
-		Status:           models.SubscriberStatusActive,
-		UnsubscribeToken: unsubscribeToken,
+		Status:           status,
+		Frequency:        models.DeliveryFrequencyImmediate,
+		Name:             profile.Name,
+		Attributes:       profile.Attributes,
+		Tags:             profile.Tags,
+	}
+	if err := newsletter.SubscriberFields.CheckRequired(subscriber.Attributes); err != nil {
+		return nil, fmt.Errorf("service: SubscribeToNewsletter: %w", err)
 	}
 
 	subscriberIDVal, err := s.subscriberRepo.CreateSubscriber(ctx, subscriber)
@@ -160,15 +233,12 @@ func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, ne
 	}
 	subscriber.ID = subscriberIDVal
 
-	// Generate unsubscribe link and extract recipient name
-	unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.appBaseURL, unsubscribeToken)
-	recipientName := email
-	if atIndex := strings.Index(email, "@"); atIndex > 0 {
-		recipientName = email[:atIndex]
+	if doubleOptIn {
+		err = s.sendOptInEmail(ctx, newsletter, &subscriber)
+	} else {
+		// Send confirmation email directly
+		err = s.emailService.SendConfirmationEmailHTML(ctx, subscriber.Email, s.subscriberEmailData(newsletter, &subscriber))
 	}
-
-	// Send confirmation email directly
-	err = s.emailService.SendConfirmationEmailHTML(ctx, subscriber.Email, recipientName, unsubscribeLink)
 	if err != nil {
 		// Critical: If we can't send the confirmation email, we should fail the subscription
 		// The subscriber was already created in the database, so we need to clean up
@@ -181,29 +251,93 @@ func (s *SubscriberService) SubscribeToNewsletter(ctx context.Context, email, ne
 	return &subscriber, nil
 }
 
-// UnsubscribeByToken processes an unsubscription request using a token.
-func (s *SubscriberService) UnsubscribeByToken(ctx context.Context, token string) error {
+// sendOptInEmail emails a pending subscriber a signed link to confirm their subscription.
+func (s *SubscriberService) sendOptInEmail(ctx context.Context, newsletter *models.Newsletter, subscriber *models.Subscriber) error {
+	token := s.tokenSigner.Sign(TokenPurposeConfirm, subscriber.ID, subscriber.NewsletterID)
+	data := NewEmailTemplateData(newsletter, subscriber.Email, "")
+	data.Subscriber = NewEmailSubscriberData(subscriber.Email, subscriber.Name, subscriber.Attributes)
+	data.Links.Confirm = fmt.Sprintf("%s/api/subscriptions/confirm?token=%s", s.appBaseURL, url.QueryEscape(token))
+	if err := s.emailService.SendOptInEmailHTML(ctx, subscriber.Email, data); err != nil {
+		return fmt.Errorf("sending opt-in email to %s: %w", subscriber.Email, err)
+	}
+	return nil
+}
+
+// subscriberEmailData builds the template data for an email to an active subscriber. Its unsubscribe and
+// preference center links carry the same signed unsubscribe token.
+func (s *SubscriberService) subscriberEmailData(newsletter *models.Newsletter, subscriber *models.Subscriber) EmailTemplateData {
+	token := s.tokenSigner.Sign(TokenPurposeUnsubscribe, subscriber.ID, subscriber.NewsletterID)
+	data := NewEmailTemplateData(newsletter, subscriber.Email, fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", s.appBaseURL, url.QueryEscape(token)))
+	data.Subscriber = NewEmailSubscriberData(subscriber.Email, subscriber.Name, subscriber.Attributes)
+	data.Links.Preferences = PreferencesLink(s.appBaseURL, token)
+	return data
+}
+
+// ConfirmSubscription activates the pending subscription the token was issued for. Confirming an active
+// subscription again succeeds, so a link that is clicked twice does not show an error.
+func (s *SubscriberService) ConfirmSubscription(ctx context.Context, token string) (*models.Subscriber, error) {
 	token = strings.TrimSpace(token)
 	if token == "" {
-		return fmt.Errorf("service: UnsubscribeByToken: %w: unsubscribe token cannot be empty", apperrors.ErrValidation)
+		return nil, fmt.Errorf("service: ConfirmSubscription: %w: confirmation token cannot be empty", apperrors.ErrValidation)
+	}
+
+	claims, err := s.tokenSigner.Verify(token, TokenPurposeConfirm, s.confirmationTTL)
+	if err != nil {
+		return nil, fmt.Errorf("service: ConfirmSubscription: %w", err)
 	}
 
-	subscriber, err := s.subscriberRepo.GetSubscriberByUnsubscribeToken(ctx, token)
+	subscriber, err := s.subscriberRepo.GetSubscriberByID(ctx, claims.SubscriberID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrSubscriberNotFound) {
-			return fmt.Errorf("service: UnsubscribeByToken: %w: invalid or expired token", apperrors.ErrTokenInvalid)
+			return nil, fmt.Errorf("service: ConfirmSubscription: %w: subscription no longer exists", apperrors.ErrTokenInvalid)
 		}
-		return fmt.Errorf("service: UnsubscribeByToken: retrieving subscriber by token: %w", err)
+		return nil, fmt.Errorf("service: ConfirmSubscription: retrieving subscriber: %w", err)
+	}
+	if subscriber.NewsletterID != claims.NewsletterID {
+		return nil, fmt.Errorf("service: ConfirmSubscription: %w", apperrors.ErrTokenInvalid)
+	}
+
+	switch subscriber.Status {
+	case models.SubscriberStatusActive:
+		return subscriber, nil
+	case models.SubscriberStatusPendingConfirmation:
+	default:
+		return nil, fmt.Errorf("service: ConfirmSubscription: %w: subscription is no longer pending", apperrors.ErrTokenInvalid)
 	}
 
-	if subscriber.Status == models.SubscriberStatusUnsubscribed {
-		return nil // Already unsubscribed
+	if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, subscriber.ID, models.SubscriberStatusActive); err != nil {
+		return nil, fmt.Errorf("service: ConfirmSubscription: activating subscriber: %w", err)
 	}
+	subscriber.Status = models.SubscriberStatusActive
 
-	if err := s.subscriberRepo.UpdateSubscriberUnsubscribeToken(ctx, subscriber.ID, ""); err != nil {
-		// Log original error for server visibility, return a generic one to user if needed for security.
-		fmt.Printf("ERROR: service: UnsubscribeByToken: failed to invalidate token for subscriber %s: %v\n", subscriber.ID, err)
-		return fmt.Errorf("service: UnsubscribeByToken: failed to update token state: %w", apperrors.ErrInternal) 
+	// The welcome email carries the unsubscribe link; the subscription is confirmed even if it cannot be sent.
+	newsletter, err := s.newsletterRepo.GetNewsletterByID(ctx, subscriber.NewsletterID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to load newsletter %s to welcome confirmed subscriber %s: %v\n", subscriber.NewsletterID, subscriber.ID, err)
+		return subscriber, nil
+	}
+	if err := s.emailService.SendConfirmationEmailHTML(ctx, subscriber.Email, s.subscriberEmailData(newsletter, subscriber)); err != nil {
+		fmt.Printf("Warning: Failed to send confirmation email to subscriber %s: %v\n", subscriber.Email, err)
+	}
+	return subscriber, nil
+}
+
+// UnsubscribeByToken processes an unsubscription request using a token.
+// The signed token names the subscription, so it is verified without looking the token up. It stays valid after
+// the subscriber subscribes again, so the links in every issue they received keep working.
+func (s *SubscriberService) UnsubscribeByToken(ctx context.Context, token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("service: UnsubscribeByToken: %w: unsubscribe token cannot be empty", apperrors.ErrValidation)
+	}
+
+	subscriber, err := s.subscriberForUnsubscribeToken(ctx, token)
+	if err != nil {
+		return fmt.Errorf("service: UnsubscribeByToken: %w", err)
+	}
+
+	if subscriber.Status == models.SubscriberStatusUnsubscribed || subscriber.Status == models.SubscriberStatusComplained {
+		return nil // Already not receiving mail; a complaint is kept so the subscriber cannot be resubscribed
 	}
 
 	if err := s.subscriberRepo.UpdateSubscriberStatus(ctx, subscriber.ID, models.SubscriberStatusUnsubscribed); err != nil {
@@ -214,9 +348,35 @@ func (s *SubscriberService) UnsubscribeByToken(ctx context.Context, token string
 	return nil
 }
 
-// ConfirmSubscriptionRequest defines the input for confirming a subscription.
-type ConfirmSubscriptionRequest struct {
-	Token string
+// subscriberForUnsubscribeToken returns the subscriber an unsubscribe token was issued for. Links sent before
+// tokens were signed carry a random token stored with the subscriber, which is still looked up.
+func (s *SubscriberService) subscriberForUnsubscribeToken(ctx context.Context, token string) (*models.Subscriber, error) {
+	if !isSignedSubscriptionToken(token) {
+		subscriber, err := s.subscriberRepo.GetSubscriberByUnsubscribeToken(ctx, token)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+				return nil, fmt.Errorf("%w: invalid or expired token", apperrors.ErrTokenInvalid)
+			}
+			return nil, fmt.Errorf("retrieving subscriber by token: %w", err)
+		}
+		return subscriber, nil
+	}
+
+	claims, err := s.tokenSigner.Verify(token, TokenPurposeUnsubscribe, s.unsubscribeTTL)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := s.subscriberRepo.GetSubscriberByID(ctx, claims.SubscriberID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+			return nil, fmt.Errorf("%w: subscription no longer exists", apperrors.ErrTokenInvalid)
+		}
+		return nil, fmt.Errorf("retrieving subscriber: %w", err)
+	}
+	if subscriber.NewsletterID != claims.NewsletterID {
+		return nil, apperrors.ErrTokenInvalid
+	}
+	return subscriber, nil
 }
 
 // GetActiveSubscribersForNewsletter retrieves all active subscribers for a given newsletter.
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MaxSubscriberImportSize limits how many subscribers can be imported in one request.
+const MaxSubscriberImportSize = 1000
+
+// ImportSubscribers adds the subscribers to the newsletter. The editor vouches for their consent, so new
+// subscribers are active right away, even with double opt-in, and get no email. Subscribers who are already
+// subscribed have their name and attributes updated and the tags added; those who unsubscribed, bounced or
+// complained, and suppressed addresses, are skipped rather than subscribed again.
+func (s *SubscriberService) ImportSubscribers(ctx context.Context, editorAuthID string, newsletterID string, subscribers []models.SubscriberImport) (*models.SubscriberImportResult, error) {
+	newsletterID = strings.TrimSpace(newsletterID)
+	if newsletterID == "" {
+		return nil, fmt.Errorf("service: ImportSubscribers: %w: newsletterID cannot be empty", apperrors.ErrValidation)
+	}
+	if len(subscribers) == 0 {
+		return nil, fmt.Errorf("service: ImportSubscribers: %w: no subscribers to import", apperrors.ErrValidation)
+	}
+	if len(subscribers) > MaxSubscriberImportSize {
+		return nil, fmt.Errorf("service: ImportSubscribers: %w: at most %d subscribers can be imported at once", apperrors.ErrValidation, MaxSubscriberImportSize)
+	}
+
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: ImportSubscribers: authorization failed: %w", err)
+	}
+	newsletter, err := s.newsletterRepo.GetNewsletterByID(ctx, newsletterID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNewsletterNotFound) {
+			return nil, fmt.Errorf("service: ImportSubscribers: newsletter '%s' %w", newsletterID, apperrors.ErrNotFound)
+		}
+		return nil, fmt.Errorf("service: ImportSubscribers: getting newsletter: %w", err)
+	}
+	if newsletter.EditorID != editor.ID {
+		return nil, fmt.Errorf("service: ImportSubscribers: %w: editor does not own newsletter '%s'", apperrors.ErrForbidden, newsletterID)
+	}
+
+	result := &models.SubscriberImportResult{Errors: []models.SubscriberImportError{}}
+	reject := func(index int, email string, err error) {
+		message := strings.TrimPrefix(err.Error(), apperrors.ErrValidation.Error()+": ")
+		result.Errors = append(result.Errors, models.SubscriberImportError{Index: index, Email: email, Error: message})
+	}
+
+	// Validate every entry before anything is written, so the suppression list is checked in one query.
+	type entry struct {
+		index   int
+		email   string
+		profile models.SubscriberProfile
+	}
+	entries := make([]entry, 0, len(subscribers))
+	emails := make([]string, 0, len(subscribers))
+	seen := make(map[string]bool, len(subscribers))
+	for i, subscriber := range subscribers {
+		email := strings.TrimSpace(strings.ToLower(subscriber.Email))
+		if _, err := mail.ParseAddress(email); err != nil || !subscriberEmailRegex.MatchString(email) {
+			reject(i, subscriber.Email, apperrors.ErrInvalidEmail)
+			continue
+		}
+		if seen[email] {
+			reject(i, subscriber.Email, apperrors.WrapValidation(nil, "email is listed more than once"))
+			continue
+		}
+		seen[email] = true
+
+		profile, err := normalizeSubscriberProfile(newsletter.SubscriberFields, models.SubscriberProfile{Name: subscriber.Name, Attributes: subscriber.Attributes, Tags: subscriber.Tags})
+		if err != nil {
+			reject(i, subscriber.Email, err)
+			continue
+		}
+		entries = append(entries, entry{index: i, email: email, profile: profile})
+		emails = append(emails, email)
+	}
+
+	suppressedEmails, err := s.suppressionRepo.FilterSuppressedEmails(ctx, newsletterID, emails)
+	if err != nil {
+		return nil, fmt.Errorf("service: ImportSubscribers: checking suppression list: %w", err)
+	}
+	suppressed := make(map[string]bool, len(suppressedEmails))
+	for _, email := range suppressedEmails {
+		suppressed[email] = true
+	}
+
+	now := time.Now().UTC()
+	for _, e := range entries {
+		if suppressed[e.email] {
+			result.Skipped++
+			continue
+		}
+
+		existing, err := s.subscriberRepo.GetSubscriberByEmailAndNewsletterID(ctx, e.email, newsletterID)
+		if err != nil && !errors.Is(err, apperrors.ErrSubscriberNotFound) {
+			return nil, fmt.Errorf("service: ImportSubscribers: checking existing subscription of %s: %w", e.email, err)
+		}
+		if existing != nil {
+			if existing.Status != models.SubscriberStatusActive && existing.Status != models.SubscriberStatusPendingConfirmation {
+				result.Skipped++
+				continue
+			}
+			changed, err := s.mergeSubscriberProfile(ctx, newsletter.SubscriberFields, existing, e.profile)
+			if err != nil {
+				if errors.Is(err, apperrors.ErrValidation) {
+					reject(e.index, e.email, err)
+					continue
+				}
+				return nil, fmt.Errorf("service: ImportSubscribers: %w", err)
+			}
+			if changed {
+				result.Updated++
+			} else {
+				result.Skipped++
+			}
+			continue
+		}
+
+		if err := newsletter.SubscriberFields.CheckRequired(e.profile.Attributes); err != nil {
+			reject(e.index, e.email, err)
+			continue
+		}
+		_, err = s.subscriberRepo.CreateSubscriber(ctx, models.Subscriber{
+			Email:            e.email,
+			NewsletterID:     newsletterID,
+			SubscriptionDate: now,
+			Status:           models.SubscriberStatusActive,
+			Frequency:        models.DeliveryFrequencyImmediate,
+			Name:             e.profile.Name,
+			Attributes:       e.profile.Attributes,
+			Tags:             e.profile.Tags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("service: ImportSubscribers: creating subscriber %s: %w", e.email, err)
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// normalizeSubscriberProfile trims the name, checks the attributes against the newsletter's subscriber fields and
+// normalizes the tags. Required fields are checked once the profile is known to be complete.
+func normalizeSubscriberProfile(fields models.SubscriberFields, profile models.SubscriberProfile) (models.SubscriberProfile, error) {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if utf8.RuneCountInString(profile.Name) > MaxSubscriberNameLength {
+		return profile, apperrors.WrapValidation(nil, fmt.Sprintf("name must not be longer than %d characters", MaxSubscriberNameLength))
+	}
+	attributes, err := fields.Normalize(profile.Attributes)
+	if err != nil {
+		return profile, err
+	}
+	profile.Attributes = attributes
+	if profile.Tags, err = models.NormalizeSubscriberTags(profile.Tags); err != nil {
+		return profile, err
+	}
+	return profile, nil
+}
+
+// mergeSubscriberProfile applies a normalized profile to an existing subscription: a name replaces the stored one,
+// attributes replace those with the same key and tags are added. The subscription is only written when something
+// changed, which is reported.
+func (s *SubscriberService) mergeSubscriberProfile(ctx context.Context, fields models.SubscriberFields, subscriber *models.Subscriber, profile models.SubscriberProfile) (bool, error) {
+	name := subscriber.Name
+	if profile.Name != "" {
+		name = profile.Name
+	}
+	attributes := maps.Clone(subscriber.Attributes)
+	if len(profile.Attributes) > 0 && attributes == nil {
+		attributes = make(map[string]string, len(profile.Attributes))
+	}
+	maps.Copy(attributes, profile.Attributes)
+	tags, err := models.NormalizeSubscriberTags(append(slices.Clone(subscriber.Tags), profile.Tags...))
+	if err != nil {
+		return false, err
+	}
+
+	if err := fields.CheckRequired(attributes); err != nil {
+		return false, err
+	}
+	if name == subscriber.Name && maps.Equal(attributes, subscriber.Attributes) && slices.Equal(tags, subscriber.Tags) {
+		return false, nil
+	}
+
+	if err := s.subscriberRepo.UpdateSubscriberProfile(ctx, subscriber.ID, name, attributes, tags); err != nil {
+		return false, fmt.Errorf("updating profile of subscriber %s: %w", subscriber.ID, err)
+	}
+	subscriber.Name = name
+	subscriber.Attributes = attributes
+	subscriber.Tags = tags
+	return true, nil
+}
+
+// UpdateSubscriberTags replaces the tags of one of the newsletter's subscribers.
+func (s *SubscriberService) UpdateSubscriberTags(ctx context.Context, editorAuthID string, newsletterID string, subscriberID string, tags []string) (*models.Subscriber, error) {
+	tags, err := models.NormalizeSubscriberTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: %w", err)
+	}
+
+	editor, err := s.getEditorFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: authorization failed: %w", err)
+	}
+	newsletter, err := s.newsletterRepo.GetNewsletterByID(ctx, newsletterID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNewsletterNotFound) {
+			return nil, fmt.Errorf("service: UpdateSubscriberTags: newsletter '%s' %w", newsletterID, apperrors.ErrNotFound)
+		}
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: getting newsletter: %w", err)
+	}
+	if newsletter.EditorID != editor.ID {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: %w: editor does not own newsletter '%s'", apperrors.ErrForbidden, newsletterID)
+	}
+
+	// Subscribers of other newsletters are reported as not found, so their IDs cannot be probed.
+	subscriber, err := s.subscriberRepo.GetSubscriberByID(ctx, subscriberID)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: %w", err)
+	}
+	if subscriber.NewsletterID != newsletterID {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: %w", apperrors.ErrSubscriberNotFound)
+	}
+
+	if err := s.subscriberRepo.UpdateSubscriberTags(ctx, subscriberID, tags); err != nil {
+		return nil, fmt.Errorf("service: UpdateSubscriberTags: %w", err)
+	}
+	subscriber.Tags = tags
+	return subscriber, nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/middleware"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockEmailService mocks the email service
+type MockEmailService struct {
+	mock.Mock
+}
+
+func (m *MockEmailService) SendEmail(ctx context.Context, to, subject, body string) error {
+	args := m.Called(ctx, to, subject, body)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOptInEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendConfirmationEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendWelcomeBackEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendNewsletterIssueHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendDigestHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendPasswordResetEmailHTML(ctx context.Context, to string, data EmailTemplateData) error {
+	args := m.Called(ctx, to, data)
+	return args.Error(0)
+}
+
+// confirmTokenFromLink extracts the token from a confirmation link.
+func confirmTokenFromLink(t *testing.T, link string) string {
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/subscriptions/confirm", parsed.Path)
+	return parsed.Query().Get("token")
+}
+
+func TestSubscriberService_SubscribeToNewsletter_DoubleOptIn(t *testing.T) {
+	ctx := context.Background()
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	suppressionRepo := &MockSuppressionRepository{}
+	emailService := &MockEmailService{}
+	signer := newTestTokenSigner(t, "secret")
+
+	newsletter := &models.Newsletter{ID: "nl-1", Name: "Weekly", OptInMode: models.OptInModeDouble}
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(newsletter, nil)
+	suppressionRepo.On("FilterSuppressedEmails", mock.Anything, "nl-1", []string{"reader@example.com"}).Return([]string(nil), nil)
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "reader@example.com", "nl-1").
+		Return(nil, apperrors.ErrSubscriberNotFound)
+	subscriberRepo.On("CreateSubscriber", mock.Anything, mock.MatchedBy(func(s models.Subscriber) bool {
+		return s.Status == models.SubscriberStatusPendingConfirmation
+	})).Return("sub-1", nil)
+
+	var confirmLink string
+	emailService.On("SendOptInEmailHTML", mock.Anything, "reader@example.com", mock.Anything).
+		Run(func(args mock.Arguments) { confirmLink = args.Get(2).(EmailTemplateData).Links.Confirm }).
+		Return(nil).Once()
+
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, suppressionRepo, emailService, "http://localhost:8080", signer, 0, 0)
+	subscriber, err := svc.SubscribeToNewsletter(ctx, "reader@example.com", "nl-1", models.SubscriberProfile{})
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriberStatusPendingConfirmation, subscriber.Status)
+	emailService.AssertNotCalled(t, "SendConfirmationEmailHTML", mock.Anything, mock.Anything, mock.Anything)
+
+	claims, err := signer.Verify(confirmTokenFromLink(t, confirmLink), TokenPurposeConfirm, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", claims.SubscriberID)
+	assert.Equal(t, "nl-1", claims.NewsletterID)
+}
+
+func TestSubscriberService_ConfirmSubscription(t *testing.T) {
+	ctx := context.Background()
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	emailService := &MockEmailService{}
+	signer := newTestTokenSigner(t, "secret")
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, nil, emailService, "http://localhost:8080", signer, 0, 0)
+
+	pending := &models.Subscriber{ID: "sub-1", Email: "reader@example.com", NewsletterID: "nl-1",
+		Status: models.SubscriberStatusPendingConfirmation}
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").Return(pending, nil).Once()
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-1", models.SubscriberStatusActive).Return(nil).Once()
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", Name: "Weekly"}, nil)
+	emailService.On("SendConfirmationEmailHTML", mock.Anything, "reader@example.com", mock.MatchedBy(func(data EmailTemplateData) bool {
+		link, err := url.Parse(data.Links.Unsubscribe)
+		if err != nil || link.Path != "/api/subscriptions/unsubscribe" {
+			return false
+		}
+		claims, err := signer.Verify(link.Query().Get("token"), TokenPurposeUnsubscribe, 0)
+		return err == nil && claims.SubscriberID == "sub-1" && claims.NewsletterID == "nl-1"
+	})).Return(nil).Once()
+
+	token := signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1")
+	subscriber, err := svc.ConfirmSubscription(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriberStatusActive, subscriber.Status)
+
+	// Opening the link again after confirming is not an error and sends nothing more.
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}, nil).Once()
+	subscriber, err = svc.ConfirmSubscription(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, models.SubscriberStatusActive, subscriber.Status)
+
+	subscriberRepo.AssertExpectations(t)
+	emailService.AssertExpectations(t)
+}
+
+func TestSubscriberService_ConfirmSubscription_Invalid(t *testing.T) {
+	ctx := context.Background()
+	subscriberRepo := &MockSubscriberRepository{}
+	signer := newTestTokenSigner(t, "secret")
+	svc := NewSubscriberService(subscriberRepo, nil, nil, nil, nil, "http://localhost:8080", signer, 0, 0)
+
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusPendingConfirmation}, nil)
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-2").
+		Return(&models.Subscriber{ID: "sub-2", NewsletterID: "nl-1", Status: models.SubscriberStatusUnsubscribed}, nil)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "other newsletter", token: signer.Sign(TokenPurposeConfirm, "sub-1", "nl-2")},
+		{name: "no longer pending", token: signer.Sign(TokenPurposeConfirm, "sub-2", "nl-1")},
+		{name: "other signer", token: newTestTokenSigner(t, "other").Sign(TokenPurposeConfirm, "sub-1", "nl-1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ConfirmSubscription(ctx, tt.token)
+			assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid), "got %v", err)
+		})
+	}
+	subscriberRepo.AssertNotCalled(t, "UpdateSubscriberStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubscriberService_UnsubscribeByToken(t *testing.T) {
+	ctx := context.Background()
+	subscriberRepo := &MockSubscriberRepository{}
+	signer := newTestTokenSigner(t, "secret")
+	svc := NewSubscriberService(subscriberRepo, nil, nil, nil, nil, "http://localhost:8080", signer, 0, 0)
+
+	// A token from before the subscriber unsubscribed and subscribed again still works.
+	token := signer.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1")
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}, nil).Once()
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-1", models.SubscriberStatusUnsubscribed).Return(nil).Once()
+	require.NoError(t, svc.UnsubscribeByToken(ctx, token))
+
+	// Unsubscribing twice is not an error.
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusUnsubscribed}, nil).Once()
+	require.NoError(t, svc.UnsubscribeByToken(ctx, token))
+
+	subscriberRepo.AssertExpectations(t)
+	subscriberRepo.AssertNotCalled(t, "GetSubscriberByUnsubscribeToken", mock.Anything, mock.Anything)
+}
+
+func TestSubscriberService_UnsubscribeByToken_LegacyToken(t *testing.T) {
+	subscriberRepo := &MockSubscriberRepository{}
+	svc := NewSubscriberService(subscriberRepo, nil, nil, nil, nil, "http://localhost:8080", newTestTokenSigner(t, "secret"), 0, 0)
+
+	subscriberRepo.On("GetSubscriberByUnsubscribeToken", mock.Anything, "9b2c6a0e-1d7f-4c1e-8a8e-3f5e2b7c9d10").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}, nil)
+	subscriberRepo.On("UpdateSubscriberStatus", mock.Anything, "sub-1", models.SubscriberStatusUnsubscribed).Return(nil).Once()
+
+	require.NoError(t, svc.UnsubscribeByToken(context.Background(), "9b2c6a0e-1d7f-4c1e-8a8e-3f5e2b7c9d10"))
+	subscriberRepo.AssertExpectations(t)
+}
+
+func TestSubscriberService_UnsubscribeByToken_Invalid(t *testing.T) {
+	ctx := context.Background()
+	subscriberRepo := &MockSubscriberRepository{}
+	signer := newTestTokenSigner(t, "secret")
+	signer.now = func() time.Time { return time.Now().Add(-48 * time.Hour) }
+	expired := signer.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1")
+	signer.now = time.Now
+	svc := NewSubscriberService(subscriberRepo, nil, nil, nil, nil, "http://localhost:8080", signer, 0, 24*time.Hour)
+
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Status: models.SubscriberStatusActive}, nil)
+
+	err := svc.UnsubscribeByToken(ctx, signer.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-2"))
+	assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid), "token of another newsletter: %v", err)
+	err = svc.UnsubscribeByToken(ctx, signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1"))
+	assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid), "confirmation token: %v", err)
+	err = svc.UnsubscribeByToken(ctx, expired)
+	assert.True(t, errors.Is(err, apperrors.ErrTokenExpired), "expired token: %v", err)
+	subscriberRepo.AssertNotCalled(t, "UpdateSubscriberStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubscriberService_SubscribeToNewsletter_Profile(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	suppressionRepo := &MockSuppressionRepository{}
+	emailService := &MockEmailService{}
+
+	newsletter := &models.Newsletter{ID: "nl-1", Name: "Weekly", SubscriberFields: models.SubscriberFields{
+		{Key: "company", Type: models.SubscriberFieldTypeText, Required: true},
+		{Key: "seats", Type: models.SubscriberFieldTypeNumber},
+	}}
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(newsletter, nil)
+	suppressionRepo.On("FilterSuppressedEmails", mock.Anything, "nl-1", []string{"reader@example.com"}).Return([]string(nil), nil)
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "reader@example.com", "nl-1").
+		Return(nil, apperrors.ErrSubscriberNotFound)
+	subscriberRepo.On("CreateSubscriber", mock.Anything, mock.MatchedBy(func(s models.Subscriber) bool {
+		return s.Name == "Ann Reader" && s.Attributes["company"] == "Acme" && s.Attributes["seats"] == "12"
+	})).Return("sub-1", nil).Once()
+	emailService.On("SendConfirmationEmailHTML", mock.Anything, "reader@example.com", mock.MatchedBy(func(data EmailTemplateData) bool {
+		return data.Subscriber.FirstName == "Ann"
+	})).Return(nil).Once()
+
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, suppressionRepo, emailService, "http://localhost:8080", newTestTokenSigner(t, "secret"), 0, 0)
+
+	_, err := svc.SubscribeToNewsletter(context.Background(), "reader@example.com", "nl-1",
+		models.SubscriberProfile{Name: " Ann Reader ", Attributes: map[string]string{"company": " Acme ", "seats": "12.0"}})
+	require.NoError(t, err)
+
+	_, err = svc.SubscribeToNewsletter(context.Background(), "reader@example.com", "nl-1",
+		models.SubscriberProfile{Attributes: map[string]string{"seats": "12"}})
+	assert.True(t, apperrors.IsValidation(err), "a required attribute is missing: got %v", err)
+
+	_, err = svc.SubscribeToNewsletter(context.Background(), "reader@example.com", "nl-1",
+		models.SubscriberProfile{Attributes: map[string]string{"company": "Acme", "plan": "pro"}})
+	assert.True(t, apperrors.IsValidation(err), "an attribute is not a subscriber field: got %v", err)
+
+	subscriberRepo.AssertExpectations(t)
+	emailService.AssertExpectations(t)
+}
+
+func TestSubscriberService_ImportSubscribers(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	suppressionRepo := &MockSuppressionRepository{}
+	editor := &models.Editor{ID: "editor-1"}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, editor)
+
+	newsletter := &models.Newsletter{ID: "nl-1", EditorID: "editor-1", Name: "Weekly", OptInMode: models.OptInModeDouble,
+		SubscriberFields: models.SubscriberFields{{Key: "plan", Type: models.SubscriberFieldTypeText}}}
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(newsletter, nil)
+	suppressionRepo.On("FilterSuppressedEmails", mock.Anything, "nl-1",
+		[]string{"new@example.com", "active@example.com", "same@example.com", "left@example.com", "blocked@example.com"}).
+		Return([]string{"blocked@example.com"}, nil)
+
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "new@example.com", "nl-1").Return(nil, apperrors.ErrSubscriberNotFound)
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "active@example.com", "nl-1").
+		Return(&models.Subscriber{ID: "sub-2", Email: "active@example.com", Status: models.SubscriberStatusActive, Attributes: map[string]string{"plan": "free"}, Tags: []string{"vip"}}, nil)
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "same@example.com", "nl-1").
+		Return(&models.Subscriber{ID: "sub-3", Email: "same@example.com", Name: "Sam", Status: models.SubscriberStatusActive}, nil)
+	subscriberRepo.On("GetSubscriberByEmailAndNewsletterID", mock.Anything, "left@example.com", "nl-1").
+		Return(&models.Subscriber{ID: "sub-4", Email: "left@example.com", Status: models.SubscriberStatusUnsubscribed}, nil)
+
+	subscriberRepo.On("CreateSubscriber", mock.Anything, mock.MatchedBy(func(s models.Subscriber) bool {
+		return s.Email == "new@example.com" && s.Status == models.SubscriberStatusActive && s.Name == "New Reader" &&
+			slices.Equal(s.Tags, []string{"beta"})
+	})).Return("sub-1", nil).Once()
+	subscriberRepo.On("UpdateSubscriberProfile", mock.Anything, "sub-2", "", map[string]string{"plan": "pro"}, []string{"beta", "vip"}).Return(nil).Once()
+
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, suppressionRepo, nil, "http://localhost:8080", nil, 0, 0)
+	result, err := svc.ImportSubscribers(ctx, "auth-1", "nl-1", []models.SubscriberImport{
+		{Email: "New@Example.com", Name: "New Reader", Tags: []string{" Beta "}},
+		{Email: "active@example.com", Attributes: map[string]string{"plan": "pro"}, Tags: []string{"beta", "VIP"}},
+		{Email: "same@example.com", Name: "Sam"},
+		{Email: "left@example.com"},
+		{Email: "blocked@example.com"},
+		{Email: "not-an-email"},
+		{Email: "new@example.com"},
+		{Email: "other@example.com", Attributes: map[string]string{"age": "40"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 3, result.Skipped)
+	require.Len(t, result.Errors, 3)
+	assert.Equal(t, 5, result.Errors[0].Index)
+	assert.Equal(t, 6, result.Errors[1].Index)
+	assert.Equal(t, "email is listed more than once", result.Errors[1].Error)
+	assert.Equal(t, 7, result.Errors[2].Index)
+	subscriberRepo.AssertExpectations(t)
+
+	// Only the owner of the newsletter can import into it.
+	otherCtx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor-2"})
+	_, err = svc.ImportSubscribers(otherCtx, "auth-2", "nl-1", []models.SubscriberImport{{Email: "new@example.com"}})
+	assert.True(t, errors.Is(err, apperrors.ErrForbidden), "got %v", err)
+}
+
+func TestSubscriberService_UpdateSubscriberTags(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	ctx := context.WithValue(context.Background(), middleware.EditorContextKey, &models.Editor{ID: "editor-1"})
+
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", EditorID: "editor-1"}, nil)
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-1").
+		Return(&models.Subscriber{ID: "sub-1", NewsletterID: "nl-1", Tags: []string{"old"}}, nil)
+	subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub-2").
+		Return(&models.Subscriber{ID: "sub-2", NewsletterID: "nl-2"}, nil)
+	subscriberRepo.On("UpdateSubscriberTags", mock.Anything, "sub-1", []string{"beta", "vip"}).Return(nil).Once()
+
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, nil, nil, "http://localhost:8080", newTestTokenSigner(t, "secret"), 0, 0)
+
+	subscriber, err := svc.UpdateSubscriberTags(ctx, "auth-1", "nl-1", "sub-1", []string{"VIP", " beta", "vip"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beta", "vip"}, subscriber.Tags)
+
+	_, err = svc.UpdateSubscriberTags(ctx, "auth-1", "nl-1", "sub-1", []string{"not a tag"})
+	assert.True(t, apperrors.IsValidation(err), "got %v", err)
+
+	// A subscriber of another newsletter is not found, even to the editor of this one.
+	_, err = svc.UpdateSubscriberTags(ctx, "auth-1", "nl-1", "sub-2", []string{"vip"})
+	assert.True(t, errors.Is(err, apperrors.ErrSubscriberNotFound), "got %v", err)
+
+	subscriberRepo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// TokenPurpose is the action a subscription token authorizes. A token signed for one purpose is never accepted for another.
+type TokenPurpose string
+
+const (
+	// TokenPurposeConfirm authorizes confirming a pending double opt-in subscription.
+	TokenPurposeConfirm TokenPurpose = "confirm"
+	// TokenPurposeUnsubscribe authorizes unsubscribing from a newsletter.
+	TokenPurposeUnsubscribe TokenPurpose = "unsubscribe"
+)
+
+// SubscriptionToken is the content of a signed token in the links emailed to subscribers.
+type SubscriptionToken struct {
+	KeyID        string // Key the token was signed with
+	Purpose      TokenPurpose
+	SubscriberID string
+	NewsletterID string
+	IssuedAt     time.Time
+}
+
+// SubscriptionTokenSigner signs and verifies subscription tokens with HMAC-SHA256.
+// A token is "<key ID>.<payload>.<signature>", payload and signature unpadded base64url, so it can be used in URLs as is.
+// Tokens are signed with the current key and verified with whichever key they name, so keys can be rotated
+// without invalidating the links already sent: add a new current key and keep the old ones until their links expire.
+type SubscriptionTokenSigner struct {
+	keys         map[string][]byte
+	currentKeyID string
+	now          func() time.Time
+}
+
+// NewSubscriptionTokenSigner creates a signer signing with the key currentKeyID and verifying with all of keys,
+// which map key IDs to secrets.
+func NewSubscriptionTokenSigner(currentKeyID string, keys map[string]string) (*SubscriptionTokenSigner, error) {
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("subscription token signer: key %q is not configured", currentKeyID)
+	}
+	signer := &SubscriptionTokenSigner{keys: make(map[string][]byte, len(keys)), currentKeyID: currentKeyID, now: time.Now}
+	for keyID, secret := range keys {
+		if keyID == "" || strings.Contains(keyID, ".") {
+			return nil, fmt.Errorf("subscription token signer: invalid key ID %q", keyID)
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("subscription token signer: key %q has an empty secret", keyID)
+		}
+		signer.keys[keyID] = []byte(secret)
+	}
+	return signer, nil
+}
+
+// Sign returns the signed token for the subscription, issued now.
+func (s *SubscriptionTokenSigner) Sign(purpose TokenPurpose, subscriberID, newsletterID string) string {
+	payload := strings.Join([]string{string(purpose), subscriberID, newsletterID, strconv.FormatInt(s.now().Unix(), 10)}, ":")
+	signed := s.currentKeyID + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signSubscriptionToken(s.keys[s.currentKeyID], signed))
+}
+
+// Verify checks the signature and purpose of a token and, unless maxAge is zero, that it was issued no longer than maxAge ago.
+// Tampered, malformed and foreign tokens and tokens of retired keys return ErrTokenInvalid, old ones ErrTokenExpired.
+func (s *SubscriptionTokenSigner) Verify(token string, purpose TokenPurpose, maxAge time.Duration) (*SubscriptionToken, error) {
+	if !isSignedSubscriptionToken(token) {
+		return nil, fmt.Errorf("%w: malformed token", apperrors.ErrTokenInvalid)
+	}
+	dot := strings.LastIndexByte(token, '.')
+	signed, signature := token[:dot], token[dot+1:]
+	keyID, encoded, _ := strings.Cut(signed, ".")
+	secret, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key", apperrors.ErrTokenInvalid)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, signSubscriptionToken(secret, signed)) {
+		return nil, fmt.Errorf("%w: bad signature", apperrors.ErrTokenInvalid)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", apperrors.ErrTokenInvalid)
+	}
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("%w: malformed token", apperrors.ErrTokenInvalid)
+	}
+	issuedAt, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", apperrors.ErrTokenInvalid)
+	}
+
+	parsed := &SubscriptionToken{
+		KeyID:        keyID,
+		Purpose:      TokenPurpose(fields[0]),
+		SubscriberID: fields[1],
+		NewsletterID: fields[2],
+		IssuedAt:     time.Unix(issuedAt, 0).UTC(),
+	}
+	if parsed.Purpose != purpose {
+		return nil, fmt.Errorf("%w: token is not for %s", apperrors.ErrTokenInvalid, purpose)
+	}
+	if maxAge > 0 && s.now().Sub(parsed.IssuedAt) > maxAge {
+		return nil, apperrors.ErrTokenExpired
+	}
+	return parsed, nil
+}
+
+// isSignedSubscriptionToken reports whether a token has the shape of a signed token. Unsubscribe links sent
+// before tokens were signed carry a random token stored with the subscriber instead.
+func isSignedSubscriptionToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func signSubscriptionToken(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// newTestTokenSigner returns a signer with a single key.
+func newTestTokenSigner(t *testing.T, secret string) *SubscriptionTokenSigner {
+	signer, err := NewSubscriptionTokenSigner("k1", map[string]string{"k1": secret})
+	require.NoError(t, err)
+	return signer
+}
+
+func TestSubscriptionTokenSigner_RoundTrip(t *testing.T) {
+	signer := newTestTokenSigner(t, "secret")
+	token := signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1")
+
+	parsed, err := signer.Verify(token, TokenPurposeConfirm, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", parsed.KeyID)
+	assert.Equal(t, TokenPurposeConfirm, parsed.Purpose)
+	assert.Equal(t, "sub-1", parsed.SubscriberID)
+	assert.Equal(t, "nl-1", parsed.NewsletterID)
+	assert.WithinDuration(t, time.Now(), parsed.IssuedAt, time.Minute)
+}
+
+func TestSubscriptionTokenSigner_Invalid(t *testing.T) {
+	signer := newTestTokenSigner(t, "secret")
+	token := signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1")
+
+	tests := []struct {
+		name   string
+		token  string
+		signer *SubscriptionTokenSigner
+	}{
+		{name: "malformed", token: "not-a-token", signer: signer},
+		{name: "unsigned", token: "k1.payload", signer: signer},
+		{name: "tampered payload", token: "x" + token, signer: signer},
+		{name: "tampered signature", token: token + "x", signer: signer},
+		{name: "other secret", token: token, signer: newTestTokenSigner(t, "other")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.Verify(tt.token, TokenPurposeConfirm, time.Hour)
+			assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid), "got %v", err)
+		})
+	}
+}
+
+func TestSubscriptionTokenSigner_WrongPurpose(t *testing.T) {
+	signer := newTestTokenSigner(t, "secret")
+	token := signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1")
+
+	_, err := signer.Verify(token, TokenPurposeUnsubscribe, time.Hour)
+	assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid))
+}
+
+func TestSubscriptionTokenSigner_Expired(t *testing.T) {
+	signer := newTestTokenSigner(t, "secret")
+	issued := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	signer.now = func() time.Time { return issued }
+	token := signer.Sign(TokenPurposeConfirm, "sub-1", "nl-1")
+
+	signer.now = func() time.Time { return issued.Add(71 * time.Hour) }
+	_, err := signer.Verify(token, TokenPurposeConfirm, 72*time.Hour)
+	require.NoError(t, err)
+
+	signer.now = func() time.Time { return issued.Add(73 * time.Hour) }
+	_, err = signer.Verify(token, TokenPurposeConfirm, 72*time.Hour)
+	assert.True(t, errors.Is(err, apperrors.ErrTokenExpired))
+}
+
+func TestSubscriptionTokenSigner_KeyRotation(t *testing.T) {
+	oldSigner := newTestTokenSigner(t, "old-secret")
+	oldToken := oldSigner.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1")
+
+	rotated, err := NewSubscriptionTokenSigner("k2", map[string]string{"k2": "new-secret", "k1": "old-secret"})
+	require.NoError(t, err)
+	parsed, err := rotated.Verify(oldToken, TokenPurposeUnsubscribe, 0)
+	require.NoError(t, err, "tokens of a previous key stay valid while the key is configured")
+	assert.Equal(t, "k1", parsed.KeyID)
+
+	newToken := rotated.Sign(TokenPurposeUnsubscribe, "sub-1", "nl-1")
+	parsed, err = rotated.Verify(newToken, TokenPurposeUnsubscribe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", parsed.KeyID)
+
+	retired, err := NewSubscriptionTokenSigner("k2", map[string]string{"k2": "new-secret"})
+	require.NoError(t, err)
+	_, err = retired.Verify(oldToken, TokenPurposeUnsubscribe, 0)
+	assert.True(t, errors.Is(err, apperrors.ErrTokenInvalid))
+}
+
+func TestNewSubscriptionTokenSigner_Invalid(t *testing.T) {
+	_, err := NewSubscriptionTokenSigner("k2", map[string]string{"k1": "secret"})
+	assert.Error(t, err, "current key missing")
+	_, err = NewSubscriptionTokenSigner("k1", map[string]string{"k1": ""})
+	assert.Error(t, err, "empty secret")
+	_, err = NewSubscriptionTokenSigner("k.1", map[string]string{"k.1": "secret"})
+	assert.Error(t, err, "key ID with a dot")
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MaxSuppressionReasonLength limits the note stored with a suppression.
+const MaxSuppressionReasonLength = 500
+
+// SuppressionServiceInterface defines the operations for managing the suppression list.
+// Every operation works on one scope: the editor's own list, or the global list of the instance.
+// The global list must only be exposed to instance admins; the editor is then only recorded as the author.
+type SuppressionServiceInterface interface {
+	ListSuppressions(ctx context.Context, editorID string, scope models.SuppressionScope, limit, offset int) ([]models.Suppression, int, error)
+	// CreateSuppression adds an address or a domain to the list. An empty source means manual.
+	CreateSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, value, reason string, source models.SuppressionSource) (*models.Suppression, error)
+	GetSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string) (*models.Suppression, error)
+	// UpdateSuppression changes the reason or source of an entry; the suppressed value itself cannot change.
+	UpdateSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string, reason *string, source *models.SuppressionSource) (*models.Suppression, error)
+	DeleteSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string) error
+}
+
+type suppressionService struct {
+	suppressionRepo repository.SuppressionRepository
+}
+
+// NewSuppressionService creates a new suppression service.
+func NewSuppressionService(suppressionRepo repository.SuppressionRepository) SuppressionServiceInterface {
+	return &suppressionService{suppressionRepo: suppressionRepo}
+}
+
+// listOwner returns the editor ID entries of the scope are stored under; global entries have none.
+func listOwner(editorID string, scope models.SuppressionScope) (string, error) {
+	switch scope {
+	case models.SuppressionScopeEditor:
+		return editorID, nil
+	case models.SuppressionScopeGlobal:
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: invalid suppression scope: %s", apperrors.ErrValidation, scope)
+}
+
+func (s *suppressionService) ListSuppressions(ctx context.Context, editorID string, scope models.SuppressionScope, limit, offset int) ([]models.Suppression, int, error) {
+	owner, err := listOwner(editorID, scope)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service: ListSuppressions: %w", err)
+	}
+	suppressions, total, err := s.suppressionRepo.ListSuppressions(ctx, owner, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service: ListSuppressions: %w", err)
+	}
+	return suppressions, total, nil
+}
+
+func (s *suppressionService) CreateSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, value, reason string, source models.SuppressionSource) (*models.Suppression, error) {
+	owner, err := listOwner(editorID, scope)
+	if err != nil {
+		return nil, fmt.Errorf("service: CreateSuppression: %w", err)
+	}
+	suppressionType, value, err := models.ParseSuppressionValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("service: CreateSuppression: %w", err)
+	}
+	if source == "" {
+		source = models.SuppressionSourceManual
+	}
+	reason = strings.TrimSpace(reason)
+	if err := validateSuppressionFields(reason, source); err != nil {
+		return nil, fmt.Errorf("service: CreateSuppression: %w", err)
+	}
+
+	suppression := &models.Suppression{
+		EditorID:  owner,
+		Type:      suppressionType,
+		Value:     value,
+		Reason:    reason,
+		Source:    source,
+		CreatedBy: editorID,
+	}
+	if err := s.suppressionRepo.CreateSuppression(ctx, suppression); err != nil {
+		return nil, fmt.Errorf("service: CreateSuppression: %w", err)
+	}
+	return suppression, nil
+}
+
+func (s *suppressionService) GetSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string) (*models.Suppression, error) {
+	suppression, err := s.getInScope(ctx, editorID, scope, suppressionID)
+	if err != nil {
+		return nil, fmt.Errorf("service: GetSuppression: %w", err)
+	}
+	return suppression, nil
+}
+
+func (s *suppressionService) UpdateSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string, reason *string, source *models.SuppressionSource) (*models.Suppression, error) {
+	if _, err := s.getInScope(ctx, editorID, scope, suppressionID); err != nil {
+		return nil, fmt.Errorf("service: UpdateSuppression: %w", err)
+	}
+	if reason != nil {
+		trimmed := strings.TrimSpace(*reason)
+		reason = &trimmed
+		if err := validateSuppressionFields(trimmed, models.SuppressionSourceManual); err != nil {
+			return nil, fmt.Errorf("service: UpdateSuppression: %w", err)
+		}
+	}
+	if source != nil && !source.IsValid() {
+		return nil, fmt.Errorf("service: UpdateSuppression: %w: invalid source: %s", apperrors.ErrValidation, *source)
+	}
+
+	suppression, err := s.suppressionRepo.UpdateSuppression(ctx, suppressionID, reason, source)
+	if err != nil {
+		return nil, fmt.Errorf("service: UpdateSuppression: %w", err)
+	}
+	return suppression, nil
+}
+
+func (s *suppressionService) DeleteSuppression(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string) error {
+	if _, err := s.getInScope(ctx, editorID, scope, suppressionID); err != nil {
+		return fmt.Errorf("service: DeleteSuppression: %w", err)
+	}
+	if err := s.suppressionRepo.DeleteSuppression(ctx, suppressionID); err != nil {
+		return fmt.Errorf("service: DeleteSuppression: %w", err)
+	}
+	return nil
+}
+
+// getInScope fetches an entry of the scope's list. Entries of other lists are reported as not found,
+// so editors cannot learn about each other's entries.
+func (s *suppressionService) getInScope(ctx context.Context, editorID string, scope models.SuppressionScope, suppressionID string) (*models.Suppression, error) {
+	owner, err := listOwner(editorID, scope)
+	if err != nil {
+		return nil, err
+	}
+	suppression, err := s.suppressionRepo.GetSuppressionByID(ctx, suppressionID)
+	if err != nil {
+		return nil, err
+	}
+	if suppression.EditorID != owner {
+		return nil, apperrors.ErrSuppressionNotFound
+	}
+	return suppression, nil
+}
+
+// validateSuppressionFields checks the reason length and the source.
+func validateSuppressionFields(reason string, source models.SuppressionSource) error {
+	if len(reason) > MaxSuppressionReasonLength {
+		return fmt.Errorf("%w: reason must be no more than %d characters long", apperrors.ErrValidation, MaxSuppressionReasonLength)
+	}
+	if !source.IsValid() {
+		return fmt.Errorf("%w: invalid source: %s", apperrors.ErrValidation, source)
+	}
+	return nil
+}
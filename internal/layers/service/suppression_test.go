@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockSuppressionRepository mocks the suppression repository
+type MockSuppressionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSuppressionRepository) CreateSuppression(ctx context.Context, suppression *models.Suppression) error {
+	args := m.Called(ctx, suppression)
+	return args.Error(0)
+}
+
+func (m *MockSuppressionRepository) GetSuppressionByID(ctx context.Context, suppressionID string) (*models.Suppression, error) {
+	args := m.Called(ctx, suppressionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionRepository) ListSuppressions(ctx context.Context, editorID string, limit int, offset int) ([]models.Suppression, int, error) {
+	args := m.Called(ctx, editorID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Suppression), args.Int(1), args.Error(2)
+}
+
+func (m *MockSuppressionRepository) UpdateSuppression(ctx context.Context, suppressionID string, reason *string, source *models.SuppressionSource) (*models.Suppression, error) {
+	args := m.Called(ctx, suppressionID, reason, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionRepository) DeleteSuppression(ctx context.Context, suppressionID string) error {
+	args := m.Called(ctx, suppressionID)
+	return args.Error(0)
+}
+
+func (m *MockSuppressionRepository) FilterSuppressedEmails(ctx context.Context, newsletterID string, emails []string) ([]string, error) {
+	args := m.Called(ctx, newsletterID, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestSuppressionService_CreateSuppression(t *testing.T) {
+	tests := []struct {
+		name         string
+		scope        models.SuppressionScope
+		value        string
+		expectedType models.SuppressionType
+		expectedVal  string
+		expectedOwn  string
+	}{
+		{"editor address", models.SuppressionScopeEditor, " Reader@Example.com ", models.SuppressionTypeAddress, "reader@example.com", "editor-1"},
+		{"global domain", models.SuppressionScopeGlobal, "@Spam.Example", models.SuppressionTypeDomain, "spam.example", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockSuppressionRepository{}
+			repo.On("CreateSuppression", mock.Anything, mock.MatchedBy(func(s *models.Suppression) bool {
+				return s.EditorID == tt.expectedOwn && s.Type == tt.expectedType && s.Value == tt.expectedVal &&
+					s.Source == models.SuppressionSourceManual && s.CreatedBy == "editor-1" && s.Reason == "asked to be removed"
+			})).Return(nil).Once()
+
+			svc := NewSuppressionService(repo)
+			suppression, err := svc.CreateSuppression(context.Background(), "editor-1", tt.scope, tt.value, " asked to be removed ", "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVal, suppression.Value)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSuppressionService_CreateSuppression_Invalid(t *testing.T) {
+	repo := &MockSuppressionRepository{}
+	svc := NewSuppressionService(repo)
+
+	_, err := svc.CreateSuppression(context.Background(), "editor-1", models.SuppressionScopeEditor, "not a domain", "", "")
+	assert.True(t, errors.Is(err, apperrors.ErrValidation))
+
+	_, err = svc.CreateSuppression(context.Background(), "editor-1", models.SuppressionScopeEditor, "example.com", "", "gossip")
+	assert.True(t, errors.Is(err, apperrors.ErrValidation))
+
+	repo.AssertNotCalled(t, "CreateSuppression", mock.Anything, mock.Anything)
+}
+
+func TestSuppressionService_ScopeIsolation(t *testing.T) {
+	editorEntry := &models.Suppression{ID: "s-1", Scope: models.SuppressionScopeEditor, EditorID: "editor-1", Value: "example.com"}
+	globalEntry := &models.Suppression{ID: "s-2", Scope: models.SuppressionScopeGlobal, Value: "spam.example"}
+
+	repo := &MockSuppressionRepository{}
+	repo.On("GetSuppressionByID", mock.Anything, "s-1").Return(editorEntry, nil)
+	repo.On("GetSuppressionByID", mock.Anything, "s-2").Return(globalEntry, nil)
+	svc := NewSuppressionService(repo)
+	ctx := context.Background()
+
+	got, err := svc.GetSuppression(ctx, "editor-1", models.SuppressionScopeEditor, "s-1")
+	require.NoError(t, err)
+	assert.Equal(t, editorEntry, got)
+
+	_, err = svc.GetSuppression(ctx, "editor-2", models.SuppressionScopeEditor, "s-1")
+	assert.True(t, errors.Is(err, apperrors.ErrSuppressionNotFound), "another editor's entry is not found")
+
+	_, err = svc.GetSuppression(ctx, "editor-1", models.SuppressionScopeEditor, "s-2")
+	assert.True(t, errors.Is(err, apperrors.ErrSuppressionNotFound), "global entries are not part of the editor's list")
+
+	err = svc.DeleteSuppression(ctx, "editor-1", models.SuppressionScopeGlobal, "s-1")
+	assert.True(t, errors.Is(err, apperrors.ErrSuppressionNotFound), "editor entries are not part of the global list")
+	repo.AssertNotCalled(t, "DeleteSuppression", mock.Anything, mock.Anything)
+
+	repo.On("DeleteSuppression", mock.Anything, "s-2").Return(nil).Once()
+	require.NoError(t, svc.DeleteSuppression(ctx, "admin-1", models.SuppressionScopeGlobal, "s-2"))
+	repo.AssertExpectations(t)
+}
+
+func TestSubscriberService_SubscribeToNewsletter_Suppressed(t *testing.T) {
+	newsletterRepo := &MockNewsletterRepository{}
+	subscriberRepo := &MockSubscriberRepository{}
+	suppressionRepo := &MockSuppressionRepository{}
+	newsletterRepo.On("GetNewsletterByID", mock.Anything, "nl-1").Return(&models.Newsletter{ID: "nl-1", Name: "Weekly"}, nil)
+	suppressionRepo.On("FilterSuppressedEmails", mock.Anything, "nl-1", []string{"reader@example.com"}).
+		Return([]string{"reader@example.com"}, nil)
+
+	svc := NewSubscriberService(subscriberRepo, newsletterRepo, nil, suppressionRepo, nil, "http://localhost:8080", nil, 0, 0)
+	_, err := svc.SubscribeToNewsletter(context.Background(), "Reader@Example.com", "nl-1", models.SubscriberProfile{})
+	assert.True(t, errors.Is(err, apperrors.ErrAddressSuppressed))
+	assert.True(t, errors.Is(err, apperrors.ErrConflict))
+	subscriberRepo.AssertNotCalled(t, "CreateSubscriber", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AdminMiddleware restricts routes to instance admins: editors whose email is one of the lower-case adminEmails.
+// It must run after AuthMiddleware; with no admins configured every request is forbidden.
+func AdminMiddleware(adminEmails []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(adminEmails))
+	for _, email := range adminEmails {
+		admins[email] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			editor, ok := GetEditorFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			if !admins[strings.ToLower(strings.TrimSpace(editor.Email))] {
+				http.Error(w, "Admin access required", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
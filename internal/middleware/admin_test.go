@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name               string
+		adminEmails        []string
+		editor             *models.Editor
+		expectedStatusCode int
+	}{
+		{
+			name:               "admin is let through",
+			adminEmails:        []string{"admin@example.com"},
+			editor:             &models.Editor{ID: "editor_1", Email: "Admin@Example.com"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "other editor is forbidden",
+			adminEmails:        []string{"admin@example.com"},
+			editor:             &models.Editor{ID: "editor_2", Email: "editor@example.com"},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "no admins configured",
+			editor:             &models.Editor{ID: "editor_1", Email: "admin@example.com"},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "unauthenticated request",
+			adminEmails:        []string{"admin@example.com"},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AdminMiddleware(tt.adminEmails)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/admin/suppressions", nil)
+			if tt.editor != nil {
+				req = req.WithContext(context.WithValue(req.Context(), EditorContextKey, tt.editor))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+		})
+	}
+}
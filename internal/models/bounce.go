@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// BounceType distinguishes permanent from temporary delivery failures reported by a receiving server.
+type BounceType string
+
+const (
+	// BounceTypeHard indicates a permanent failure (a 5.x.x status), e.g. the mailbox does not exist.
+	BounceTypeHard BounceType = "hard"
+	// BounceTypeSoft indicates a temporary failure (a 4.x.x status), e.g. a full mailbox.
+	BounceTypeSoft BounceType = "soft"
+)
+
+// Bounce is one recipient of a delivery status notification (RFC 3464) that reported a failed or delayed delivery
+type Bounce struct {
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Type           BounceType `json:"type"`
+	Action         string     `json:"action"`                    // failed or delayed
+	Status         string     `json:"status,omitempty"`          // Enhanced status code, e.g. 5.1.1
+	DiagnosticCode string     `json:"diagnostic_code,omitempty"` // The remote server's reply, e.g. "smtp; 550 5.1.1 No such user"
+	ReportingMTA   string     `json:"reporting_mta,omitempty"`
+	ReceivedAt     time.Time  `json:"received_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Complaint is a spam report for an issue, received from a mailbox provider's feedback loop as an ARF report (RFC 5965)
+type Complaint struct {
+	ID           string    `json:"id"`
+	NewsletterID string    `json:"newsletter_id"`
+	SubscriberID string    `json:"subscriber_id"`
+	Email        string    `json:"email"`
+	FeedbackType string    `json:"feedback_type"`        // abuse, fraud, virus or other
+	UserAgent    string    `json:"user_agent,omitempty"` // The feedback loop that sent the report, e.g. "Yahoo!-Mail-Feedback/2.0"
+	ReceivedAt   time.Time `json:"received_at"`
+}
+
+// ComplaintStats summarises the spam complaints about a newsletter's issues over a period.
+type ComplaintStats struct {
+	NewsletterID   string    `json:"newsletter_id"`
+	Since          time.Time `json:"since"`
+	Sent           int       `json:"sent"`            // Issue emails sent since Since
+	Complaints     int       `json:"complaints"`      // Complaints received since Since
+	ComplaintRate  float64   `json:"complaint_rate"`  // Complaints per email sent, 0 when nothing was sent
+	AboveThreshold bool      `json:"above_threshold"` // The rate exceeds what mailbox providers tolerate
+}
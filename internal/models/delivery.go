@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// DeliveryStatus defines the possible states of a single email delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusQueued indicates the delivery is waiting to be picked up by a worker.
+	DeliveryStatusQueued DeliveryStatus = "queued"
+	// DeliveryStatusProcessing indicates a worker has claimed the delivery and is sending it.
+	DeliveryStatusProcessing DeliveryStatus = "processing"
+	// DeliveryStatusSent indicates the email was accepted by the mail server.
+	DeliveryStatusSent DeliveryStatus = "sent"
+	// DeliveryStatusFailed indicates the last attempt failed with a transient error and a retry is scheduled.
+	DeliveryStatusFailed DeliveryStatus = "failed"
+	// DeliveryStatusPermanentlyFailed indicates the delivery was rejected or ran out of retries.
+	DeliveryStatusPermanentlyFailed DeliveryStatus = "permanently_failed"
+	// DeliveryStatusCancelled indicates the post was recalled, or the recipient left or was suppressed, before this delivery was sent.
+	DeliveryStatusCancelled DeliveryStatus = "cancelled"
+)
+
+// IsValid reports whether s is one of the known delivery statuses.
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryStatusQueued, DeliveryStatusProcessing, DeliveryStatusSent, DeliveryStatusFailed,
+		DeliveryStatusPermanentlyFailed, DeliveryStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Delivery represents a single delivery of a post to one subscriber
+// The subscriber's name and attributes are captured when the post is published, for its merge tags.
+type Delivery struct {
+	ID                   string            `json:"id"`
+	PostID               string            `json:"post_id"`
+	SubscriberID         string            `json:"subscriber_id"`
+	Email                string            `json:"email"`
+	SubscriberName       string            `json:"-"`
+	SubscriberAttributes map[string]string `json:"-"`
+	UnsubscribeToken     string            `json:"-"`
+	Status               DeliveryStatus    `json:"status"`
+	Attempts             int               `json:"attempts"`
+	LastError            string            `json:"last_error,omitempty"`
+	NextAttemptAt        time.Time         `json:"next_attempt_at"`
+	LockedAt             *time.Time        `json:"-"`
+	SentAt               *time.Time        `json:"sent_at,omitempty"`
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+}
+
+// DeliverySummary aggregates the delivery progress of a published post
+type DeliverySummary struct {
+	PostID            string `json:"post_id"`
+	Total             int    `json:"total"`
+	Queued            int    `json:"queued"`
+	Processing        int    `json:"processing"`
+	Sent              int    `json:"sent"`
+	Failed            int    `json:"failed"`             // Failed at least once, retry pending
+	PermanentlyFailed int    `json:"permanently_failed"` // Rejected or out of retries
+	Cancelled         int    `json:"cancelled"`          // Recalled before being sent
+}
+
+// Pending returns the number of deliveries that have not reached a final state yet.
+func (s *DeliverySummary) Pending() int {
+	return s.Queued + s.Processing + s.Failed
+}
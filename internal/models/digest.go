@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DigestItem is a post waiting to be sent to a subscriber in their next digest.
+// Its status follows the deliveries: queued until due, then processing, sent, failed or cancelled.
+type DigestItem struct {
+	ID               string         `json:"id"`
+	PostID           string         `json:"post_id"`
+	SubscriberID     string         `json:"subscriber_id"`
+	Email            string         `json:"email"`
+	UnsubscribeToken string         `json:"-"`
+	Status           DeliveryStatus `json:"status"`
+	Attempts         int            `json:"attempts"`
+	LastError        string         `json:"last_error,omitempty"`
+	DueAt            time.Time      `json:"due_at"` // When the digest is sent, or retried after a failure
+	SentAt           *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
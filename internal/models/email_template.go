@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// EmailTemplate is a newsletter's override of one of the built-in email templates.
+type EmailTemplate struct {
+	NewsletterID string    `json:"newsletter_id"`
+	Name         string    `json:"name"`
+	Body         string    `json:"body"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
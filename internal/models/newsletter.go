@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -9,12 +11,30 @@ import (
 
 // Newsletter represents the domain model for a newsletter
 type Newsletter struct {
-	ID          string    `json:"id"`
-	EditorID    string    `json:"editor_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID               string             `json:"id"`
+	EditorID         string             `json:"editor_id"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description,omitempty"`
+	Branding         NewsletterBranding `json:"branding"`
+	OptInMode        OptInMode          `json:"opt_in_mode"`
+	SubscriberFields SubscriberFields   `json:"subscriber_fields"` // Custom attributes its subscribers can carry
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// OptInMode defines how new subscribers of a newsletter are confirmed.
+type OptInMode string
+
+const (
+	// OptInModeSingle activates subscribers as soon as they subscribe.
+	OptInModeSingle OptInMode = "single"
+	// OptInModeDouble keeps subscribers pending until they click the link in a confirmation email.
+	OptInModeDouble OptInMode = "double"
+)
+
+// IsValid checks if the mode is one of the defined modes.
+func (m OptInMode) IsValid() bool {
+	return m == OptInModeSingle || m == OptInModeDouble
 }
 
 // Validate performs business validation on the Newsletter fields
@@ -43,4 +63,51 @@ func (n *Newsletter) Validate() error {
 	}
 	
 	return nil
-} 
\ No newline at end of file
+}
+
+// Branding limits
+const (
+	MaxBrandingTextLength = 500
+	MaxBrandingHTMLLength = 10000
+)
+
+// accentColorRegex matches a CSS hex color such as #1a73e8.
+var accentColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// NewsletterBranding holds the look of the emails sent on behalf of a newsletter.
+// Empty fields fall back to the defaults of the email layout.
+type NewsletterBranding struct {
+	LogoURL        string `json:"logo_url"`
+	AccentColor    string `json:"accent_color"`
+	FooterText     string `json:"footer_text"`
+	MailingAddress string `json:"mailing_address"` // Physical postal address, required by CAN-SPAM for commercial email
+	HeaderHTML     string `json:"header_html"`
+	FooterHTML     string `json:"footer_html"`
+}
+
+// Validate performs business validation on the branding fields
+func (b *NewsletterBranding) Validate() error {
+	if b.LogoURL != "" {
+		logoURL, err := url.Parse(b.LogoURL)
+		if err != nil || (logoURL.Scheme != "https" && logoURL.Scheme != "http") || logoURL.Host == "" {
+			return apperrors.WrapValidation(nil, "logo URL must be an absolute http or https URL")
+		}
+	}
+	if len(b.LogoURL) > MaxBrandingTextLength {
+		return apperrors.WrapValidation(nil, "logo URL is too long")
+	}
+	
+	if b.AccentColor != "" && !accentColorRegex.MatchString(b.AccentColor) {
+		return apperrors.WrapValidation(nil, "accent color must be a hex color such as #1a73e8")
+	}
+	
+	if len(b.FooterText) > MaxBrandingTextLength || len(b.MailingAddress) > MaxBrandingTextLength {
+		return apperrors.WrapValidation(nil, "footer text and mailing address must not exceed 500 characters")
+	}
+	
+	if len(b.HeaderHTML) > MaxBrandingHTMLLength || len(b.FooterHTML) > MaxBrandingHTMLLength {
+		return apperrors.WrapValidation(nil, "header and footer HTML must not exceed 10000 characters")
+	}
+	
+	return nil
+}
@@ -255,4 +255,66 @@ func TestNewsletter_Validate_EdgeCases(t *testing.T) {
 		}
 		assert.NoError(t, newsletter.Validate())
 	})
-} 
\ No newline at end of file
+}
+
+func TestNewsletterBranding_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		branding      NewsletterBranding
+		expectedError string
+	}{
+		{
+			name: "valid branding",
+			branding: NewsletterBranding{
+				LogoURL:        "https://example.com/logo.png",
+				AccentColor:    "#1A73e8",
+				FooterText:     "Thanks for reading",
+				MailingAddress: "1 Main Street, Springfield",
+				HeaderHTML:     "<p>Header</p>",
+			},
+		},
+		{
+			name:     "empty branding uses the defaults",
+			branding: NewsletterBranding{},
+		},
+		{
+			name:          "relative logo URL",
+			branding:      NewsletterBranding{LogoURL: "/logo.png"},
+			expectedError: "logo URL must be an absolute http or https URL",
+		},
+		{
+			name:          "javascript logo URL",
+			branding:      NewsletterBranding{LogoURL: "javascript:alert(1)"},
+			expectedError: "logo URL must be an absolute http or https URL",
+		},
+		{
+			name:          "named accent color",
+			branding:      NewsletterBranding{AccentColor: "red"},
+			expectedError: "accent color must be a hex color",
+		},
+		{
+			name:          "mailing address too long",
+			branding:      NewsletterBranding{MailingAddress: strings.Repeat("a", MaxBrandingTextLength+1)},
+			expectedError: "must not exceed 500 characters",
+		},
+		{
+			name:          "footer HTML too long",
+			branding:      NewsletterBranding{FooterHTML: strings.Repeat("a", MaxBrandingHTMLLength+1)},
+			expectedError: "must not exceed 10000 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.branding.Validate()
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.True(t, apperrors.IsValidation(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
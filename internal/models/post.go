@@ -7,13 +7,68 @@ import (
 	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
 )
 
+// PostStatus defines the lifecycle states of a post.
+type PostStatus string
+
+const (
+	// PostStatusDraft indicates the post is being written and has not been sent.
+	PostStatusDraft PostStatus = "draft"
+	// PostStatusScheduled indicates the post will be published by the scheduler at ScheduledAt.
+	PostStatusScheduled PostStatus = "scheduled"
+	// PostStatusSending indicates the post was published and deliveries are still in progress.
+	PostStatusSending PostStatus = "sending"
+	// PostStatusSent indicates every delivery reached a final state and at least one was sent.
+	PostStatusSent PostStatus = "sent"
+	// PostStatusFailed indicates every delivery reached a final state and none were sent.
+	PostStatusFailed PostStatus = "failed"
+	// PostStatusArchived indicates the post was retired and can no longer change.
+	PostStatusArchived PostStatus = "archived"
+)
+
+// postStatusTransitions lists the statuses each status may move to.
+// Published posts return to draft only through a recall (unpublish).
+var postStatusTransitions = map[PostStatus][]PostStatus{
+	PostStatusDraft:     {PostStatusScheduled, PostStatusSending, PostStatusArchived},
+	PostStatusScheduled: {PostStatusDraft, PostStatusScheduled, PostStatusSending, PostStatusArchived},
+	PostStatusSending:   {PostStatusSent, PostStatusFailed, PostStatusDraft},
+	PostStatusSent:      {PostStatusArchived, PostStatusDraft},
+	PostStatusFailed:    {PostStatusArchived, PostStatusDraft},
+	PostStatusArchived:  {},
+}
+
+// IsValid reports whether s is one of the known post statuses.
+func (s PostStatus) IsValid() bool {
+	_, ok := postStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a post in status s may move to next.
+func (s PostStatus) CanTransitionTo(next PostStatus) bool {
+	for _, allowed := range postStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether the title and content of a post in status s may change.
+// Posts are frozen while their deliveries are in flight and once archived.
+func (s PostStatus) IsEditable() bool {
+	return s.IsValid() && s != PostStatusSending && s != PostStatusArchived
+}
+
 // Post represents the domain model for a blog post within a newsletter
 type Post struct {
 	ID           string     `json:"id"`
 	NewsletterID string     `json:"newsletter_id"`
 	Title        string     `json:"title"`
 	Content      string     `json:"content"`
+	Status       PostStatus `json:"status"`
 	PublishedAt  *time.Time `json:"published_at,omitempty"` // Pointer for nullability
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"` // Set while the post waits to be published by the scheduler
+	RecalledAt   *time.Time `json:"recalled_at,omitempty"`  // When the post was last unpublished
+	RecalledBy   string     `json:"recalled_by,omitempty"`  // Editor ID that last unpublished the post
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -39,7 +94,18 @@ func (p *Post) Validate() error {
 	return nil
 }
 
-// IsPublished checks if the post has a publication timestamp set
+// IsPublished checks if the post has been handed over for delivery
 func (p *Post) IsPublished() bool {
-	return p.PublishedAt != nil
+	switch p.Status {
+	case PostStatusSending, PostStatusSent, PostStatusFailed:
+		return true
+	case PostStatusArchived:
+		return p.PublishedAt != nil // Archived after being sent
+	}
+	return false
+}
+
+// IsScheduled checks if the post is waiting to be published at a scheduled time
+func (p *Post) IsScheduled() bool {
+	return p.Status == PostStatusScheduled
 }
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     PostStatus
+		to       PostStatus
+		expected bool
+	}{
+		{"draft can be scheduled", PostStatusDraft, PostStatusScheduled, true},
+		{"draft can be published", PostStatusDraft, PostStatusSending, true},
+		{"scheduled can be rescheduled", PostStatusScheduled, PostStatusScheduled, true},
+		{"scheduled can be cancelled", PostStatusScheduled, PostStatusDraft, true},
+		{"sending finishes as sent", PostStatusSending, PostStatusSent, true},
+		{"sending finishes as failed", PostStatusSending, PostStatusFailed, true},
+		{"sending cannot be archived", PostStatusSending, PostStatusArchived, false},
+		{"sent cannot be published again", PostStatusSent, PostStatusSending, false},
+		{"sent can be recalled to draft", PostStatusSent, PostStatusDraft, true},
+		{"sending can be recalled to draft", PostStatusSending, PostStatusDraft, true},
+		{"sent can be archived", PostStatusSent, PostStatusArchived, true},
+		{"archived is final", PostStatusArchived, PostStatusDraft, false},
+		{"unknown status cannot move", PostStatus("bogus"), PostStatusDraft, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestPost_IsPublished(t *testing.T) {
+	publishedAt := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		post     Post
+		expected bool
+	}{
+		{"draft", Post{Status: PostStatusDraft}, false},
+		{"scheduled", Post{Status: PostStatusScheduled}, false},
+		{"sending", Post{Status: PostStatusSending, PublishedAt: &publishedAt}, true},
+		{"sent", Post{Status: PostStatusSent, PublishedAt: &publishedAt}, true},
+		{"failed", Post{Status: PostStatusFailed, PublishedAt: &publishedAt}, true},
+		{"archived draft", Post{Status: PostStatusArchived}, false},
+		{"archived after sending", Post{Status: PostStatusArchived, PublishedAt: &publishedAt}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.post.IsPublished())
+		})
+	}
+}
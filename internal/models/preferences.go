@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// SubscriberPreferences is what the preference center shows a subscriber: their display name and
+// every newsletter of the instance their address is actively subscribed to.
+type SubscriberPreferences struct {
+	Email         string                   `json:"email"`
+	Name          string                   `json:"name"`
+	Subscriptions []SubscriptionPreference `json:"subscriptions"`
+}
+
+// SubscriptionPreference describes how one newsletter is delivered to the subscriber.
+type SubscriptionPreference struct {
+	NewsletterID   string            `json:"newsletter_id"`
+	NewsletterName string            `json:"newsletter_name"`
+	SubscribedAt   time.Time         `json:"subscribed_at"`
+	Frequency      DeliveryFrequency `json:"frequency"`
+	PausedUntil    *time.Time        `json:"paused_until,omitempty"`
+}
+
+// SubscriberPreferencesUpdate changes the preferences of a subscriber. Nil fields are left unchanged.
+type SubscriberPreferencesUpdate struct {
+	Name          *string                        `json:"name,omitempty"`
+	Subscriptions []SubscriptionPreferenceUpdate `json:"subscriptions,omitempty"`
+}
+
+// SubscriptionPreferenceUpdate changes the delivery of one newsletter.
+type SubscriptionPreferenceUpdate struct {
+	NewsletterID string             `json:"newsletter_id"`
+	Unsubscribe  bool               `json:"unsubscribe,omitempty"`
+	Frequency    *DeliveryFrequency `json:"frequency,omitempty"`
+	PauseWeeks   *int               `json:"pause_weeks,omitempty"` // Pause delivery for this many weeks, 0 to resume
+}
@@ -0,0 +1,340 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// SegmentMatch defines how the rules of a segment combine.
+type SegmentMatch string
+
+const (
+	// SegmentMatchAll selects subscribers who match every rule.
+	SegmentMatchAll SegmentMatch = "all"
+	// SegmentMatchAny selects subscribers who match at least one rule.
+	SegmentMatchAny SegmentMatch = "any"
+)
+
+// SegmentRuleField defines what a segment rule looks at.
+type SegmentRuleField string
+
+const (
+	// SegmentRuleFieldTag looks at the subscriber's tags.
+	SegmentRuleFieldTag SegmentRuleField = "tag"
+	// SegmentRuleFieldAttribute looks at one of the subscriber's custom attributes, named by the rule's key.
+	SegmentRuleFieldAttribute SegmentRuleField = "attribute"
+	// SegmentRuleFieldSubscribedAt looks at when the subscriber subscribed.
+	SegmentRuleFieldSubscribedAt SegmentRuleField = "subscribed_at"
+	// SegmentRuleFieldEngagement looks at the deliveries the subscriber had over the last days, by the metric in the rule's key.
+	SegmentRuleFieldEngagement SegmentRuleField = "engagement"
+)
+
+// SegmentOperator defines how a segment rule compares its field with its value.
+type SegmentOperator string
+
+const (
+	SegmentOperatorHas        SegmentOperator = "has"         // tag
+	SegmentOperatorNotHas     SegmentOperator = "not_has"     // tag
+	SegmentOperatorEquals     SegmentOperator = "eq"          // attribute, ignoring case
+	SegmentOperatorNotEquals  SegmentOperator = "neq"         // attribute, ignoring case; also matches a missing attribute
+	SegmentOperatorContains   SegmentOperator = "contains"    // text attribute, ignoring case
+	SegmentOperatorGreater    SegmentOperator = "gt"          // number or date attribute
+	SegmentOperatorLess       SegmentOperator = "lt"          // number or date attribute
+	SegmentOperatorExists     SegmentOperator = "exists"      // attribute
+	SegmentOperatorNotExists  SegmentOperator = "not_exists"  // attribute
+	SegmentOperatorBefore     SegmentOperator = "before"      // subscribed_at, on an earlier day than the value
+	SegmentOperatorAfter      SegmentOperator = "after"       // subscribed_at, on a later day than the value
+	SegmentOperatorWithinDays SegmentOperator = "within_days" // subscribed_at, during the last days
+	SegmentOperatorAtLeast    SegmentOperator = "at_least"    // engagement
+	SegmentOperatorFewerThan  SegmentOperator = "fewer_than"  // engagement
+)
+
+// SegmentEngagementMetric names the deliveries an engagement rule counts.
+// Opens and clicks are not tracked, so engagement is measured by what reached the subscriber.
+type SegmentEngagementMetric string
+
+const (
+	// SegmentEngagementReceived counts the posts sent to the subscriber, on their own or in a digest.
+	SegmentEngagementReceived SegmentEngagementMetric = "received"
+	// SegmentEngagementFailed counts the posts that could not be delivered to the subscriber.
+	SegmentEngagementFailed SegmentEngagementMetric = "failed"
+)
+
+// DeliveryStatus returns the final status of the deliveries the metric counts.
+func (m SegmentEngagementMetric) DeliveryStatus() DeliveryStatus {
+	if m == SegmentEngagementFailed {
+		return DeliveryStatusPermanentlyFailed
+	}
+	return DeliveryStatusSent
+}
+
+// Segment limits
+const (
+	MaxSegmentNameLength        = 100
+	MaxSegmentDescriptionLength = 500
+	MaxSegmentRules             = 20
+	MaxSegmentDays              = 3650
+)
+
+// SegmentRule selects subscribers by one of their properties.
+type SegmentRule struct {
+	Field    SegmentRuleField `json:"field"`
+	Operator SegmentOperator  `json:"operator"`
+	Key      string           `json:"key,omitempty"`   // Attribute key, or engagement metric
+	Value    string           `json:"value,omitempty"` // Tag, attribute value, date (YYYY-MM-DD) or number of deliveries
+	Days     int              `json:"days,omitempty"`  // Period of within_days and engagement rules
+}
+
+// Segment is a saved selection of a newsletter's subscribers that posts can be published to.
+// Subscribers are matched against its rules when a post is published, so the segment follows the list as it changes.
+type Segment struct {
+	ID           string        `json:"id"`
+	NewsletterID string        `json:"newsletter_id"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Match        SegmentMatch  `json:"match"`
+	Rules        []SegmentRule `json:"rules"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// SegmentEngagement holds, by the index of each engagement rule of a segment, the number of deliveries the rule
+// counts per subscriber ID. Subscribers without deliveries are left out.
+type SegmentEngagement map[int]map[string]int
+
+// Normalize validates the segment against the newsletter's subscriber fields and rewrites it in canonical form:
+// the name is trimmed, the match defaults to all, tags are lower-cased and attribute values are written the way
+// SubscriberFields.Normalize writes them.
+func (s *Segment) Normalize(fields SubscriberFields) error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+	if s.Name == "" {
+		return apperrors.ErrNameEmpty
+	}
+	if utf8.RuneCountInString(s.Name) > MaxSegmentNameLength {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("name must not exceed %d characters", MaxSegmentNameLength))
+	}
+	if utf8.RuneCountInString(s.Description) > MaxSegmentDescriptionLength {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("description must not exceed %d characters", MaxSegmentDescriptionLength))
+	}
+	switch s.Match {
+	case "":
+		s.Match = SegmentMatchAll
+	case SegmentMatchAll, SegmentMatchAny:
+	default:
+		return apperrors.WrapValidation(nil, fmt.Sprintf("invalid match '%s': use all or any", s.Match))
+	}
+	if len(s.Rules) == 0 {
+		return apperrors.WrapValidation(nil, "a segment needs at least one rule")
+	}
+	if len(s.Rules) > MaxSegmentRules {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("a segment can have at most %d rules", MaxSegmentRules))
+	}
+
+	rules := make([]SegmentRule, len(s.Rules))
+	for i, rule := range s.Rules {
+		normalized, err := normalizeSegmentRule(rule, fields)
+		if err != nil {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("rule %d: %s", i+1, strings.TrimPrefix(err.Error(), apperrors.ErrValidation.Error()+": ")))
+		}
+		rules[i] = normalized
+	}
+	s.Rules = rules
+	return nil
+}
+
+func normalizeSegmentRule(rule SegmentRule, fields SubscriberFields) (SegmentRule, error) {
+	rule.Key = strings.TrimSpace(rule.Key)
+	rule.Value = strings.TrimSpace(rule.Value)
+	invalidOperator := apperrors.WrapValidation(nil, fmt.Sprintf("operator '%s' cannot be used with %s", rule.Operator, rule.Field))
+
+	switch rule.Field {
+	case SegmentRuleFieldTag:
+		if rule.Operator != SegmentOperatorHas && rule.Operator != SegmentOperatorNotHas {
+			return rule, invalidOperator
+		}
+		tags, err := NormalizeSubscriberTags([]string{rule.Value})
+		if err != nil {
+			return rule, err
+		}
+		if len(tags) == 0 {
+			return rule, apperrors.WrapValidation(nil, "tag is required")
+		}
+		return SegmentRule{Field: rule.Field, Operator: rule.Operator, Value: tags[0]}, nil
+
+	case SegmentRuleFieldAttribute:
+		var field *SubscriberField
+		for i := range fields {
+			if fields[i].Key == rule.Key {
+				field = &fields[i]
+			}
+		}
+		if field == nil {
+			return rule, apperrors.WrapValidation(nil, fmt.Sprintf("unknown attribute '%s'", rule.Key))
+		}
+		switch rule.Operator {
+		case SegmentOperatorExists, SegmentOperatorNotExists:
+			return SegmentRule{Field: rule.Field, Operator: rule.Operator, Key: rule.Key}, nil
+		case SegmentOperatorContains:
+			if field.Type != SubscriberFieldTypeText {
+				return rule, apperrors.WrapValidation(nil, fmt.Sprintf("contains only works with text attributes, '%s' is a %s", rule.Key, field.Type))
+			}
+		case SegmentOperatorGreater, SegmentOperatorLess:
+			if field.Type != SubscriberFieldTypeNumber && field.Type != SubscriberFieldTypeDate {
+				return rule, apperrors.WrapValidation(nil, fmt.Sprintf("%s only works with number and date attributes, '%s' is a %s", rule.Operator, rule.Key, field.Type))
+			}
+		case SegmentOperatorEquals, SegmentOperatorNotEquals:
+		default:
+			return rule, invalidOperator
+		}
+		values, err := SubscriberFields{*field}.Normalize(map[string]string{rule.Key: rule.Value})
+		if err != nil {
+			return rule, err
+		}
+		if values[rule.Key] == "" {
+			return rule, apperrors.WrapValidation(nil, "value is required")
+		}
+		return SegmentRule{Field: rule.Field, Operator: rule.Operator, Key: rule.Key, Value: values[rule.Key]}, nil
+
+	case SegmentRuleFieldSubscribedAt:
+		switch rule.Operator {
+		case SegmentOperatorBefore, SegmentOperatorAfter:
+			date, err := time.Parse(time.DateOnly, rule.Value)
+			if err != nil {
+				return rule, apperrors.WrapValidation(nil, "value must be a date such as 2024-01-15")
+			}
+			return SegmentRule{Field: rule.Field, Operator: rule.Operator, Value: date.Format(time.DateOnly)}, nil
+		case SegmentOperatorWithinDays:
+			if err := validateSegmentDays(rule.Days); err != nil {
+				return rule, err
+			}
+			return SegmentRule{Field: rule.Field, Operator: rule.Operator, Days: rule.Days}, nil
+		}
+		return rule, invalidOperator
+
+	case SegmentRuleFieldEngagement:
+		metric := SegmentEngagementMetric(rule.Key)
+		if metric != SegmentEngagementReceived && metric != SegmentEngagementFailed {
+			return rule, apperrors.WrapValidation(nil, fmt.Sprintf("unknown engagement metric '%s': use received or failed", rule.Key))
+		}
+		if rule.Operator != SegmentOperatorAtLeast && rule.Operator != SegmentOperatorFewerThan {
+			return rule, invalidOperator
+		}
+		count, err := strconv.Atoi(rule.Value)
+		if err != nil || count < 0 {
+			return rule, apperrors.WrapValidation(nil, "value must be a number of deliveries")
+		}
+		if err := validateSegmentDays(rule.Days); err != nil {
+			return rule, err
+		}
+		return SegmentRule{Field: rule.Field, Operator: rule.Operator, Key: rule.Key, Value: strconv.Itoa(count), Days: rule.Days}, nil
+	}
+	return rule, apperrors.WrapValidation(nil, fmt.Sprintf("unknown field '%s'", rule.Field))
+}
+
+func validateSegmentDays(days int) error {
+	if days < 1 || days > MaxSegmentDays {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("days must be between 1 and %d", MaxSegmentDays))
+	}
+	return nil
+}
+
+// EngagementSince returns the start of the period an engagement rule counts deliveries over.
+func (r SegmentRule) EngagementSince(now time.Time) time.Time {
+	return now.AddDate(0, 0, -r.Days)
+}
+
+// Matches reports whether the subscriber belongs to the normalized segment at the given time.
+func (s *Segment) Matches(subscriber *Subscriber, engagement SegmentEngagement, now time.Time) bool {
+	for i, rule := range s.Rules {
+		matched := rule.matches(subscriber, engagement[i][subscriber.ID], now)
+		if s.Match == SegmentMatchAny && matched {
+			return true
+		}
+		if s.Match != SegmentMatchAny && !matched {
+			return false
+		}
+	}
+	return s.Match != SegmentMatchAny
+}
+
+// matches evaluates the rule; deliveries is the subscriber's count for an engagement rule.
+func (r SegmentRule) matches(subscriber *Subscriber, deliveries int, now time.Time) bool {
+	switch r.Field {
+	case SegmentRuleFieldTag:
+		return subscriber.HasTag(r.Value) == (r.Operator == SegmentOperatorHas)
+
+	case SegmentRuleFieldAttribute:
+		value, ok := subscriber.Attributes[r.Key]
+		ok = ok && value != ""
+		switch r.Operator {
+		case SegmentOperatorExists:
+			return ok
+		case SegmentOperatorNotExists:
+			return !ok
+		case SegmentOperatorNotEquals:
+			return !ok || !strings.EqualFold(value, r.Value)
+		}
+		if !ok {
+			return false
+		}
+		switch r.Operator {
+		case SegmentOperatorEquals:
+			return strings.EqualFold(value, r.Value)
+		case SegmentOperatorContains:
+			return strings.Contains(strings.ToLower(value), strings.ToLower(r.Value))
+		case SegmentOperatorGreater:
+			return compareAttributeValues(value, r.Value) > 0
+		case SegmentOperatorLess:
+			return compareAttributeValues(value, r.Value) < 0
+		}
+
+	case SegmentRuleFieldSubscribedAt:
+		switch r.Operator {
+		case SegmentOperatorWithinDays:
+			return !subscriber.SubscriptionDate.Before(now.AddDate(0, 0, -r.Days))
+		case SegmentOperatorBefore, SegmentOperatorAfter:
+			day, err := time.Parse(time.DateOnly, r.Value)
+			if err != nil {
+				return false
+			}
+			if r.Operator == SegmentOperatorBefore {
+				return subscriber.SubscriptionDate.Before(day)
+			}
+			return !subscriber.SubscriptionDate.Before(day.AddDate(0, 0, 1))
+		}
+
+	case SegmentRuleFieldEngagement:
+		count, err := strconv.Atoi(r.Value)
+		if err != nil {
+			return false
+		}
+		if r.Operator == SegmentOperatorAtLeast {
+			return deliveries >= count
+		}
+		return deliveries < count
+	}
+	return false
+}
+
+// compareAttributeValues compares two normalized number or date attribute values.
+// Numbers are compared by value; dates written as YYYY-MM-DD sort as strings.
+func compareAttributeValues(a, b string) int {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+var segmentTestFields = SubscriberFields{
+	{Key: "company", Type: SubscriberFieldTypeText},
+	{Key: "seats", Type: SubscriberFieldTypeNumber},
+	{Key: "trial", Type: SubscriberFieldTypeBoolean},
+	{Key: "renews_on", Type: SubscriberFieldTypeDate},
+}
+
+func TestSegment_Normalize(t *testing.T) {
+	segment := Segment{Name: "  Big customers ", Rules: []SegmentRule{
+		{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: " VIP "},
+		{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorGreater, Key: "seats", Value: "010.0"},
+		{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorExists, Key: "company", Value: "ignored"},
+		{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorWithinDays, Value: "ignored", Days: 30},
+		{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorAtLeast, Key: "received", Value: "03", Days: 90},
+	}}
+	require.NoError(t, segment.Normalize(segmentTestFields))
+	assert.Equal(t, "Big customers", segment.Name)
+	assert.Equal(t, SegmentMatchAll, segment.Match)
+	assert.Equal(t, []SegmentRule{
+		{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "vip"},
+		{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorGreater, Key: "seats", Value: "10"},
+		{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorExists, Key: "company"},
+		{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorWithinDays, Days: 30},
+		{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorAtLeast, Key: "received", Value: "3", Days: 90},
+	}, segment.Rules)
+
+	tooManyRules := make([]SegmentRule, MaxSegmentRules+1)
+	for i := range tooManyRules {
+		tooManyRules[i] = SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "vip"}
+	}
+	validRule := []SegmentRule{{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "vip"}}
+
+	tests := []struct {
+		name    string
+		segment Segment
+	}{
+		{"empty name", Segment{Name: " ", Rules: validRule}},
+		{"name too long", Segment{Name: strings.Repeat("a", MaxSegmentNameLength+1), Rules: validRule}},
+		{"unknown match", Segment{Name: "s", Match: "most", Rules: validRule}},
+		{"no rules", Segment{Name: "s"}},
+		{"too many rules", Segment{Name: "s", Rules: tooManyRules}},
+		{"unknown field", Segment{Name: "s", Rules: []SegmentRule{{Field: "country", Operator: SegmentOperatorEquals, Value: "CZ"}}}},
+		{"tag with attribute operator", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldTag, Operator: SegmentOperatorEquals, Value: "vip"}}}},
+		{"missing tag", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas}}}},
+		{"unknown attribute", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorEquals, Key: "plan", Value: "pro"}}}},
+		{"contains on a number", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorContains, Key: "seats", Value: "1"}}}},
+		{"greater on text", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorGreater, Key: "company", Value: "a"}}}},
+		{"value of the wrong type", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorEquals, Key: "trial", Value: "maybe"}}}},
+		{"missing attribute value", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorEquals, Key: "company"}}}},
+		{"invalid date", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorBefore, Value: "15/01/2024"}}}},
+		{"within no days", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorWithinDays}}}},
+		{"unknown metric", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorAtLeast, Key: "opened", Value: "1", Days: 30}}}},
+		{"negative count", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorAtLeast, Key: "received", Value: "-1", Days: 30}}}},
+		{"too many days", Segment{Name: "s", Rules: []SegmentRule{{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorFewerThan, Key: "received", Value: "1", Days: MaxSegmentDays + 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.segment.Normalize(segmentTestFields)
+			assert.True(t, apperrors.IsValidation(err), "got %v", err)
+		})
+	}
+}
+
+func TestSegment_Matches(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	subscriber := &Subscriber{
+		ID:               "sub-1",
+		SubscriptionDate: time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC),
+		Tags:             []string{"beta", "vip"},
+		Attributes:       map[string]string{"company": "Acme Corp", "seats": "12", "renews_on": "2024-06-01"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     SegmentRule
+		expected bool
+	}{
+		{"has tag", SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "vip"}, true},
+		{"has missing tag", SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "churned"}, false},
+		{"does not have tag", SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorNotHas, Value: "churned"}, true},
+		{"equals ignoring case", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorEquals, Key: "company", Value: "acme corp"}, true},
+		{"not equals", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorNotEquals, Key: "company", Value: "Globex"}, true},
+		{"not equals a missing attribute", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorNotEquals, Key: "trial", Value: "true"}, true},
+		{"equals a missing attribute", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorEquals, Key: "trial", Value: "true"}, false},
+		{"contains", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorContains, Key: "company", Value: "CORP"}, true},
+		{"greater compares numbers", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorGreater, Key: "seats", Value: "9"}, true},
+		{"less compares numbers", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorLess, Key: "seats", Value: "9"}, false},
+		{"greater compares dates", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorGreater, Key: "renews_on", Value: "2024-05-31"}, true},
+		{"exists", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorExists, Key: "seats"}, true},
+		{"not exists", SegmentRule{Field: SegmentRuleFieldAttribute, Operator: SegmentOperatorNotExists, Key: "seats"}, false},
+		{"subscribed before", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorBefore, Value: "2024-01-16"}, true},
+		{"subscribed before the same day", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorBefore, Value: "2024-01-15"}, false},
+		{"subscribed after the day before", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorAfter, Value: "2024-01-14"}, true},
+		{"subscribed after the same day", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorAfter, Value: "2024-01-15"}, false},
+		{"subscribed within days", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorWithinDays, Days: 60}, true},
+		{"subscribed earlier than days", SegmentRule{Field: SegmentRuleFieldSubscribedAt, Operator: SegmentOperatorWithinDays, Days: 30}, false},
+		{"received at least", SegmentRule{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorAtLeast, Key: "received", Value: "3", Days: 30}, true},
+		{"received fewer than", SegmentRule{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorFewerThan, Key: "received", Value: "3", Days: 30}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segment := &Segment{Match: SegmentMatchAll, Rules: []SegmentRule{tt.rule}}
+			engagement := SegmentEngagement{0: {"sub-1": 3}}
+			assert.Equal(t, tt.expected, segment.Matches(subscriber, engagement, now))
+		})
+	}
+
+	// A subscriber without deliveries is not in the engagement counts.
+	inactive := &Segment{Match: SegmentMatchAll, Rules: []SegmentRule{{Field: SegmentRuleFieldEngagement, Operator: SegmentOperatorFewerThan, Key: "received", Value: "1", Days: 30}}}
+	assert.True(t, inactive.Matches(subscriber, SegmentEngagement{}, now))
+
+	hasVIP := SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "vip"}
+	hasChurned := SegmentRule{Field: SegmentRuleFieldTag, Operator: SegmentOperatorHas, Value: "churned"}
+	assert.False(t, (&Segment{Match: SegmentMatchAll, Rules: []SegmentRule{hasVIP, hasChurned}}).Matches(subscriber, nil, now))
+	assert.True(t, (&Segment{Match: SegmentMatchAny, Rules: []SegmentRule{hasVIP, hasChurned}}).Matches(subscriber, nil, now))
+	assert.False(t, (&Segment{Match: SegmentMatchAny, Rules: []SegmentRule{hasChurned}}).Matches(subscriber, nil, now))
+}
@@ -3,8 +3,11 @@ package models
 import (
 	"fmt"
 	"net/mail"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
 )
@@ -13,20 +16,152 @@ import (
 type SubscriberStatus string
 
 const (
+	// SubscriberStatusPendingConfirmation indicates a double opt-in subscriber has not confirmed yet; they receive no issues.
+	SubscriberStatusPendingConfirmation SubscriberStatus = "pending_confirmation"
 	// SubscriberStatusActive indicates the subscription is active.
 	SubscriberStatusActive       SubscriberStatus = "active"
 	// SubscriberStatusUnsubscribed indicates the user has unsubscribed.
 	SubscriberStatusUnsubscribed SubscriberStatus = "unsubscribed"
+	// SubscriberStatusBounced indicates mail to the address hard-bounced too often and is no longer sent.
+	SubscriberStatusBounced      SubscriberStatus = "bounced"
+	// SubscriberStatusComplained indicates the subscriber reported an issue as spam; they are never mailed again.
+	SubscriberStatusComplained   SubscriberStatus = "complained"
 )
 
+// IsValid checks if the status is one of the defined statuses.
+func (s SubscriberStatus) IsValid() bool {
+	switch s {
+	case SubscriberStatusPendingConfirmation, SubscriberStatusActive, SubscriberStatusUnsubscribed, SubscriberStatusBounced, SubscriberStatusComplained:
+		return true
+	}
+	return false
+}
+
+// DeliveryFrequency defines how often a subscriber is sent the issues of a newsletter.
+type DeliveryFrequency string
+
+const (
+	// DeliveryFrequencyImmediate sends every issue as soon as it is published.
+	DeliveryFrequencyImmediate DeliveryFrequency = "immediate"
+	// DeliveryFrequencyDaily collects the issues of a day into one digest.
+	DeliveryFrequencyDaily DeliveryFrequency = "daily"
+	// DeliveryFrequencyWeekly collects the issues of a week into one digest.
+	DeliveryFrequencyWeekly DeliveryFrequency = "weekly"
+)
+
+// DigestHourUTC is the hour of the day at which digests are sent.
+const DigestHourUTC = 8
+
+// IsValid checks if the frequency is one of the defined frequencies.
+func (f DeliveryFrequency) IsValid() bool {
+	switch f {
+	case DeliveryFrequencyImmediate, DeliveryFrequencyDaily, DeliveryFrequencyWeekly:
+		return true
+	}
+	return false
+}
+
+// IsDigest reports whether issues are collected into digests rather than sent one by one.
+func (f DeliveryFrequency) IsDigest() bool {
+	return f == DeliveryFrequencyDaily || f == DeliveryFrequencyWeekly
+}
+
+// NextDigestAt returns when the digest that includes an issue published at t is sent:
+// at DigestHourUTC of the next day for daily digests, of the next Monday for weekly ones.
+func (f DeliveryFrequency) NextDigestAt(t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), DigestHourUTC, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if f == DeliveryFrequencyWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
 // Subscriber represents a subscriber to a newsletter
 type Subscriber struct {
-	ID               string           `json:"id"`
-	Email            string           `json:"email"`
-	NewsletterID     string           `json:"newsletter_id"`      // Consistent snake_case naming
-	SubscriptionDate time.Time        `json:"subscription_date"`  // Consistent snake_case naming
-	Status           SubscriberStatus `json:"status"`
-	UnsubscribeToken string           `json:"-"` // Token for one-click unsubscribe (omit from JSON)
+	ID               string            `json:"id"`
+	Email            string            `json:"email"`
+	Name             string            `json:"name,omitempty"`         // Display name chosen by the subscriber
+	NewsletterID     string            `json:"newsletter_id"`          // Consistent snake_case naming
+	SubscriptionDate time.Time         `json:"subscription_date"`      // Consistent snake_case naming
+	Status           SubscriberStatus  `json:"status"`
+	Frequency        DeliveryFrequency `json:"frequency"`
+	PausedUntil      *time.Time        `json:"paused_until,omitempty"` // No issues are sent before this time
+	Attributes       map[string]string `json:"attributes,omitempty"`   // Custom attributes, keyed by the newsletter's subscriber fields
+	Tags             []string          `json:"tags,omitempty"`         // Labels set by the newsletter's editor, used by segments
+}
+
+// SubscriberProfile is what is known about a subscriber beyond their address, given when they subscribe or are imported.
+// Tags can only be given by the newsletter's editor and are added to those the subscriber already has.
+type SubscriberProfile struct {
+	Name       string
+	Attributes map[string]string
+	Tags       []string
+}
+
+// SubscriberImport is one subscriber in an import of existing subscribers.
+type SubscriberImport struct {
+	Email      string            `json:"email"`
+	Name       string            `json:"name,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+}
+
+// SubscriberImportResult reports the outcome of an import. Entries that failed validation are listed in Errors
+// and do not stop the rest from being imported.
+type SubscriberImportResult struct {
+	Created int                     `json:"created"`
+	Updated int                     `json:"updated"` // Existing subscriptions whose name, attributes or tags were updated
+	Skipped int                     `json:"skipped"` // Suppressed addresses, subscribers who left the newsletter and entries that changed nothing
+	Errors  []SubscriberImportError `json:"errors"`
+}
+
+// SubscriberImportError describes an entry of an import that was rejected.
+type SubscriberImportError struct {
+	Index int    `json:"index"` // Position of the entry in the import, starting at 0
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// Subscriber tag limits
+const (
+	MaxSubscriberTags      = 50
+	MaxSubscriberTagLength = 50
+)
+
+var subscriberTagRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]*$`)
+
+// NormalizeSubscriberTags lower-cases, trims and sorts tags and drops duplicates and empty ones.
+// Tags are made of letters, digits, dashes and underscores, e.g. "vip" or "beta-tester".
+func NormalizeSubscriberTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxSubscriberTagLength || !subscriberTagRegex.MatchString(tag) {
+			return nil, apperrors.WrapValidation(nil, fmt.Sprintf("invalid tag '%s': use up to %d letters, digits, dashes and underscores", tag, MaxSubscriberTagLength))
+		}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxSubscriberTags {
+		return nil, apperrors.WrapValidation(nil, fmt.Sprintf("a subscriber can have at most %d tags", MaxSubscriberTags))
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// HasTag reports whether the subscriber carries the normalized tag.
+func (s *Subscriber) HasTag(tag string) bool {
+	return slices.Contains(s.Tags, tag)
+}
+
+// IsPaused reports whether the subscriber has paused delivery at the given time.
+func (s *Subscriber) IsPaused(now time.Time) bool {
+	return s.PausedUntil != nil && now.Before(*s.PausedUntil)
 }
 
 // Validate checks the subscriber's fields for business validation
@@ -43,7 +178,7 @@ func (s *Subscriber) Validate() error {
 		return apperrors.WrapValidation(nil, "newsletter ID is required")
 	}
 	
-	if s.Status != SubscriberStatusActive && s.Status != SubscriberStatusUnsubscribed {
+	if !s.Status.IsValid() {
 		return apperrors.WrapValidation(nil, fmt.Sprintf("invalid subscriber status: %s", s.Status))
 	}
 	
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// SubscriberFieldType defines the kind of value a custom subscriber field holds.
+type SubscriberFieldType string
+
+const (
+	// SubscriberFieldTypeText holds free text.
+	SubscriberFieldTypeText SubscriberFieldType = "text"
+	// SubscriberFieldTypeNumber holds a decimal number.
+	SubscriberFieldTypeNumber SubscriberFieldType = "number"
+	// SubscriberFieldTypeBoolean holds true or false.
+	SubscriberFieldTypeBoolean SubscriberFieldType = "boolean"
+	// SubscriberFieldTypeDate holds a calendar date written as YYYY-MM-DD.
+	SubscriberFieldTypeDate SubscriberFieldType = "date"
+)
+
+// IsValid checks if the type is one of the defined field types.
+func (t SubscriberFieldType) IsValid() bool {
+	switch t {
+	case SubscriberFieldTypeText, SubscriberFieldTypeNumber, SubscriberFieldTypeBoolean, SubscriberFieldTypeDate:
+		return true
+	}
+	return false
+}
+
+// Subscriber field limits
+const (
+	MaxSubscriberFields           = 30
+	MaxSubscriberFieldLabelLength = 100
+	MaxSubscriberAttributeLength  = 500
+)
+
+// subscriberFieldKeyRegex matches field keys that can be used as map keys in merge tags,
+// e.g. {{ .Subscriber.Attributes.company }}.
+var subscriberFieldKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// SubscriberField describes a custom attribute the subscribers of a newsletter can carry.
+type SubscriberField struct {
+	Key      string              `json:"key"`
+	Label    string              `json:"label,omitempty"`
+	Type     SubscriberFieldType `json:"type"`
+	Required bool                `json:"required"` // Subscribing and importing fail without it
+}
+
+// SubscriberFields is the schema of the custom attributes of a newsletter's subscribers.
+type SubscriberFields []SubscriberField
+
+// Validate performs business validation on the schema.
+func (fields SubscriberFields) Validate() error {
+	if len(fields) > MaxSubscriberFields {
+		return apperrors.WrapValidation(nil, fmt.Sprintf("a newsletter can have at most %d subscriber fields", MaxSubscriberFields))
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !subscriberFieldKeyRegex.MatchString(field.Key) {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("invalid field key '%s': use up to 40 lowercase letters, digits and underscores, starting with a letter", field.Key))
+		}
+		if seen[field.Key] {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("field '%s' is defined twice", field.Key))
+		}
+		seen[field.Key] = true
+		if !field.Type.IsValid() {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("invalid type '%s' of field '%s'", field.Type, field.Key))
+		}
+		if utf8.RuneCountInString(field.Label) > MaxSubscriberFieldLabelLength {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("label of field '%s' must not exceed %d characters", field.Key, MaxSubscriberFieldLabelLength))
+		}
+	}
+	return nil
+}
+
+// Normalize checks attributes against the schema and returns them in canonical form: text is trimmed, numbers,
+// booleans and dates are rewritten the way strconv and time format them. Empty values are dropped. Required
+// fields are not checked, since an update may only carry some of the attributes; use CheckRequired for that.
+func (fields SubscriberFields) Normalize(attributes map[string]string) (map[string]string, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	byKey := make(map[string]SubscriberField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	normalized := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, apperrors.WrapValidation(nil, fmt.Sprintf("unknown attribute '%s'", key))
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if utf8.RuneCountInString(value) > MaxSubscriberAttributeLength {
+			return nil, apperrors.WrapValidation(nil, fmt.Sprintf("attribute '%s' must not exceed %d characters", key, MaxSubscriberAttributeLength))
+		}
+
+		switch field.Type {
+		case SubscriberFieldTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, apperrors.WrapValidation(nil, fmt.Sprintf("attribute '%s' must be a number", key))
+			}
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		case SubscriberFieldTypeBoolean:
+			flag, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				return nil, apperrors.WrapValidation(nil, fmt.Sprintf("attribute '%s' must be true or false", key))
+			}
+			value = strconv.FormatBool(flag)
+		case SubscriberFieldTypeDate:
+			date, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return nil, apperrors.WrapValidation(nil, fmt.Sprintf("attribute '%s' must be a date such as 2024-01-15", key))
+			}
+			value = date.Format(time.DateOnly)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// CheckRequired reports the first required field the attributes leave out.
+func (fields SubscriberFields) CheckRequired(attributes map[string]string) error {
+	for _, field := range fields {
+		if field.Required && attributes[field.Key] == "" {
+			return apperrors.WrapValidation(nil, fmt.Sprintf("attribute '%s' is required", field.Key))
+		}
+	}
+	return nil
+}
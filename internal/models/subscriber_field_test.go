@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+func TestSubscriberFields_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     SubscriberFields
+		shouldFail bool
+	}{
+		{name: "no fields", fields: nil},
+		{name: "valid fields", fields: SubscriberFields{
+			{Key: "company", Label: "Company", Type: SubscriberFieldTypeText},
+			{Key: "seats_2", Type: SubscriberFieldTypeNumber, Required: true},
+		}},
+		{name: "key with uppercase letters", fields: SubscriberFields{{Key: "Company", Type: SubscriberFieldTypeText}}, shouldFail: true},
+		{name: "key starting with a digit", fields: SubscriberFields{{Key: "1st", Type: SubscriberFieldTypeText}}, shouldFail: true},
+		{name: "key too long", fields: SubscriberFields{{Key: strings.Repeat("a", 41), Type: SubscriberFieldTypeText}}, shouldFail: true},
+		{name: "duplicate key", fields: SubscriberFields{{Key: "plan", Type: SubscriberFieldTypeText}, {Key: "plan", Type: SubscriberFieldTypeDate}}, shouldFail: true},
+		{name: "unknown type", fields: SubscriberFields{{Key: "plan", Type: "list"}}, shouldFail: true},
+		{name: "label too long", fields: SubscriberFields{{Key: "plan", Label: strings.Repeat("a", 101), Type: SubscriberFieldTypeText}}, shouldFail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fields.Validate()
+			if tt.shouldFail {
+				assert.True(t, apperrors.IsValidation(err), "got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSubscriberFields_Normalize(t *testing.T) {
+	fields := SubscriberFields{
+		{Key: "company", Type: SubscriberFieldTypeText},
+		{Key: "seats", Type: SubscriberFieldTypeNumber},
+		{Key: "trial", Type: SubscriberFieldTypeBoolean},
+		{Key: "renews_on", Type: SubscriberFieldTypeDate},
+	}
+
+	normalized, err := fields.Normalize(map[string]string{
+		"company":   "  Acme  ",
+		"seats":     "010.50",
+		"trial":     "TRUE",
+		"renews_on": "2024-01-15",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"company": "Acme", "seats": "10.5", "trial": "true", "renews_on": "2024-01-15"}, normalized)
+
+	normalized, err = fields.Normalize(map[string]string{"company": "  "})
+	require.NoError(t, err)
+	assert.Empty(t, normalized, "empty values are dropped")
+
+	invalid := []map[string]string{
+		{"unknown": "x"},
+		{"seats": "many"},
+		{"trial": "maybe"},
+		{"renews_on": "15/01/2024"},
+		{"company": strings.Repeat("a", MaxSubscriberAttributeLength+1)},
+	}
+	for _, attributes := range invalid {
+		_, err := fields.Normalize(attributes)
+		assert.True(t, apperrors.IsValidation(err), "attributes %v: got %v", attributes, err)
+	}
+}
+
+func TestSubscriberFields_CheckRequired(t *testing.T) {
+	fields := SubscriberFields{{Key: "company", Type: SubscriberFieldTypeText, Required: true}, {Key: "plan", Type: SubscriberFieldTypeText}}
+
+	assert.NoError(t, fields.CheckRequired(map[string]string{"company": "Acme"}))
+	assert.True(t, apperrors.IsValidation(fields.CheckRequired(map[string]string{"plan": "pro"})))
+	assert.True(t, apperrors.IsValidation(fields.CheckRequired(nil)))
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+func TestDeliveryFrequency_NextDigestAt(t *testing.T) {
+	// 2024-01-17 is a Wednesday.
+	wednesdayMorning := time.Date(2024, 1, 17, 7, 0, 0, 0, time.UTC)
+	sundayNight := time.Date(2024, 1, 21, 23, 30, 0, 0, time.UTC)
+	monday := time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency DeliveryFrequency
+		published time.Time
+		expected  time.Time
+	}{
+		{"daily is sent the next morning", DeliveryFrequencyDaily, wednesdayMorning, time.Date(2024, 1, 18, DigestHourUTC, 0, 0, 0, time.UTC)},
+		{"daily late at night", DeliveryFrequencyDaily, sundayNight, time.Date(2024, 1, 22, DigestHourUTC, 0, 0, 0, time.UTC)},
+		{"weekly is sent on Monday", DeliveryFrequencyWeekly, wednesdayMorning, time.Date(2024, 1, 22, DigestHourUTC, 0, 0, 0, time.UTC)},
+		{"weekly from Sunday night", DeliveryFrequencyWeekly, sundayNight, time.Date(2024, 1, 22, DigestHourUTC, 0, 0, 0, time.UTC)},
+		{"weekly from Monday waits a week", DeliveryFrequencyWeekly, monday, time.Date(2024, 1, 29, DigestHourUTC, 0, 0, 0, time.UTC)},
+		{"other time zones are converted", DeliveryFrequencyDaily, time.Date(2024, 1, 17, 23, 0, 0, 0, time.FixedZone("CET", 3600)), time.Date(2024, 1, 18, DigestHourUTC, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.frequency.NextDigestAt(tt.published))
+		})
+	}
+}
+
+func TestDeliveryFrequency_IsDigest(t *testing.T) {
+	assert.False(t, DeliveryFrequencyImmediate.IsDigest())
+	assert.True(t, DeliveryFrequencyDaily.IsDigest())
+	assert.True(t, DeliveryFrequencyWeekly.IsDigest())
+	assert.False(t, DeliveryFrequency("hourly").IsValid())
+}
+
+func TestSubscriber_IsPaused(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	assert.False(t, (&Subscriber{}).IsPaused(now))
+	assert.True(t, (&Subscriber{PausedUntil: &later}).IsPaused(now))
+	assert.False(t, (&Subscriber{PausedUntil: &earlier}).IsPaused(now), "the pause is over")
+}
+
+func TestNormalizeSubscriberTags(t *testing.T) {
+	tags, err := NormalizeSubscriberTags([]string{" VIP ", "beta", "vip", "", "early_adopter"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beta", "early_adopter", "vip"}, tags)
+
+	tags, err = NormalizeSubscriberTags(nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	tooMany := make([]string, MaxSubscriberTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag-%d", i)
+	}
+	for _, invalid := range [][]string{{"two words"}, {"-leading-dash"}, {strings.Repeat("a", MaxSubscriberTagLength+1)}, tooMany} {
+		_, err := NormalizeSubscriberTags(invalid)
+		assert.True(t, apperrors.IsValidation(err), "tags %v: got %v", invalid, err)
+	}
+}
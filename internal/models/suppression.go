@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+// SuppressionScope defines whose newsletters a suppression applies to.
+type SuppressionScope string
+
+const (
+	// SuppressionScopeEditor applies to every newsletter of the editor who added it.
+	SuppressionScopeEditor SuppressionScope = "editor"
+	// SuppressionScopeGlobal applies to every newsletter of the instance and is managed by admins.
+	SuppressionScopeGlobal SuppressionScope = "global"
+)
+
+// SuppressionType defines what a suppression matches.
+type SuppressionType string
+
+const (
+	// SuppressionTypeAddress matches a single email address.
+	SuppressionTypeAddress SuppressionType = "address"
+	// SuppressionTypeDomain matches every address at the domain and its subdomains.
+	SuppressionTypeDomain SuppressionType = "domain"
+)
+
+// SuppressionSource records how an entry got on the suppression list.
+type SuppressionSource string
+
+const (
+	SuppressionSourceManual    SuppressionSource = "manual"
+	SuppressionSourceImport    SuppressionSource = "import"
+	SuppressionSourceBounce    SuppressionSource = "bounce"
+	SuppressionSourceComplaint SuppressionSource = "complaint"
+)
+
+// IsValid checks if the source is one of the defined sources.
+func (s SuppressionSource) IsValid() bool {
+	switch s {
+	case SuppressionSourceManual, SuppressionSourceImport, SuppressionSourceBounce, SuppressionSourceComplaint:
+		return true
+	}
+	return false
+}
+
+var suppressionDomainRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9\-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// Suppression blocks an address or a whole domain from being subscribed or sent any issue
+type Suppression struct {
+	ID        string            `json:"id"`
+	Scope     SuppressionScope  `json:"scope"`
+	EditorID  string            `json:"editor_id,omitempty"` // Owner of an editor-scoped entry; empty for global entries
+	Type      SuppressionType   `json:"type"`
+	Value     string            `json:"value"` // Lower-case address or domain
+	Reason    string            `json:"reason,omitempty"`
+	Source    SuppressionSource `json:"source"`
+	CreatedBy string            `json:"created_by,omitempty"` // Editor who added the entry
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ParseSuppressionValue normalises an address ("Reader@Example.com") or domain ("example.com" or "@example.com")
+// and reports which of the two it is.
+func ParseSuppressionValue(value string) (SuppressionType, string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if domain, ok := strings.CutPrefix(value, "@"); ok {
+		value = domain
+	}
+	if value == "" {
+		return "", "", apperrors.WrapValidation(nil, "value is required")
+	}
+
+	if strings.Contains(value, "@") {
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return "", "", apperrors.ErrInvalidEmail
+		}
+		return SuppressionTypeAddress, value, nil
+	}
+	if !suppressionDomainRegex.MatchString(value) {
+		return "", "", apperrors.WrapValidation(nil, fmt.Sprintf("invalid domain: %s", value))
+	}
+	return SuppressionTypeDomain, value, nil
+}
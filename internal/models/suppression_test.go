@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+)
+
+func TestParseSuppressionValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedType  SuppressionType
+		expectedValue string
+		expectError   bool
+	}{
+		{"address", "Reader@Example.com", SuppressionTypeAddress, "reader@example.com", false},
+		{"domain", " Example.COM ", SuppressionTypeDomain, "example.com", false},
+		{"domain with at sign", "@mail.example.com", SuppressionTypeDomain, "mail.example.com", false},
+		{"empty", "  ", "", "", true},
+		{"display name", "Reader <reader@example.com>", "", "", true},
+		{"single label domain", "localhost", "", "", true},
+		{"domain with spaces", "example .com", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suppressionType, value, err := ParseSuppressionValue(tt.value)
+			if tt.expectError {
+				assert.True(t, errors.Is(err, apperrors.ErrValidation), "got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedType, suppressionType)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
@@ -3,39 +3,157 @@ package setup
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/config"
 	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
 	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
 )
 
-// NewGmailEmailService creates and configures a Gmail SMTP email service.
-// It validates all required configuration and returns a ready-to-use service.
+// emailProviderFactory builds the provider selected by EMAIL_PROVIDER.
+type emailProviderFactory func(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error)
+
+// emailProviders is the registry of the supported EMAIL_PROVIDER values.
+var emailProviders = map[string]emailProviderFactory{
+	"gmail":   newGmailProvider,
+	"smtp":    newSMTPProvider,
+	"http":    newHTTPEmailProvider,
+	"file":    newFileEmailProvider,
+	"maildir": newFileEmailProvider,
+	"mbox":    newFileEmailProvider,
+	"memory":  newMemoryMailbox,
+}
+
+// developmentEmailProviders never deliver mail, so they are refused outside development
+// where they would silently swallow every email.
+var developmentEmailProviders = map[string]bool{
+	"file":    true,
+	"maildir": true,
+	"mbox":    true,
+	"memory":  true,
+}
+
+// NewEmailService creates and configures the email service using the provider selected by EMAIL_PROVIDER.
+// It validates all required configuration and returns a ready-to-use service along with its provider,
+// so the caller can expose a development mailbox.
 // This follows the same pattern as other setup functions for consistency.
-func NewGmailEmailService(from, password, smtpHost, smtpPort string, logger *log.Logger) (service.EmailService, error) {
-	if from == "" {
-		return nil, fmt.Errorf("email from address is required: %w", apperrors.ErrValidation)
+func NewEmailService(cfg *config.Config, renderer service.EmailRenderer, logger *log.Logger) (service.EmailService, service.EmailProvider, error) {
+	if cfg.EmailFrom == "" {
+		return nil, nil, fmt.Errorf("email from address is required: %w", apperrors.ErrValidation)
 	}
-	if password == "" {
-		return nil, fmt.Errorf("email password is required: %w", apperrors.ErrValidation)
+
+	factory, ok := emailProviders[cfg.EmailProvider]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown email provider %q, expected one of %s: %w",
+			cfg.EmailProvider, strings.Join(emailProviderNames(), ", "), apperrors.ErrValidation)
 	}
-	if smtpHost == "" {
-		return nil, fmt.Errorf("SMTP host is required: %w", apperrors.ErrValidation)
+	if developmentEmailProviders[cfg.EmailProvider] && !cfg.IsDevelopment() {
+		return nil, nil, fmt.Errorf("email provider %q does not deliver mail and requires APP_ENV=development: %w",
+			cfg.EmailProvider, apperrors.ErrValidation)
 	}
-	if smtpPort == "" {
-		return nil, fmt.Errorf("SMTP port is required: %w", apperrors.ErrValidation)
+
+	dkim, err := newDKIMSigner(cfg.DKIMDomain, cfg.DKIMSelector, cfg.DKIMPrivateKey)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	config := service.GmailEmailServiceConfig{
-		From:     from,
-		Password: password,
-		SMTPHost: smtpHost,
-		SMTPPort: smtpPort,
+	provider, err := factory(cfg, dkim)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize %s email provider: %w", cfg.EmailProvider, err)
 	}
 
-	emailService, err := service.NewGmailEmailService(config, logger)
+	emailService, err := service.NewProviderEmailService(service.ProviderEmailServiceConfig{
+		From:     cfg.EmailFrom,
+		Renderer: renderer,
+		Provider: provider,
+	}, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Gmail email service: %w", apperrors.ErrInternal)
+		return nil, nil, fmt.Errorf("failed to initialize email service: %w", apperrors.ErrInternal)
 	}
 
-	return emailService, nil
-} 
\ No newline at end of file
+	return emailService, provider, nil
+}
+
+// newGmailProvider sends through Gmail SMTP, authenticating with a Google app password.
+func newGmailProvider(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error) {
+	if cfg.GoogleAppPassword == "" {
+		return nil, fmt.Errorf("email password is required: %w", apperrors.ErrValidation)
+	}
+
+	return service.NewSMTPProvider(service.SMTPProviderConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.EmailFrom,
+		Password: cfg.GoogleAppPassword,
+		Auth:     service.SMTPAuthPlain,
+		TLS:      service.SMTPTLSStartTLS,
+		DKIM:     dkim,
+
+		PoolSize:                 cfg.SMTPPoolSize,
+		MaxMessagesPerConnection: cfg.SMTPMaxMessagesPerConnection,
+		IdleTimeout:              cfg.SMTPIdleTimeout,
+	})
+}
+
+// newSMTPProvider sends through any SMTP relay with the configured authentication and TLS mode.
+func newSMTPProvider(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error) {
+	return service.NewSMTPProvider(service.SMTPProviderConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		Auth:     cfg.SMTPAuth,
+		TLS:      cfg.SMTPTLS,
+		DKIM:     dkim,
+
+		PoolSize:                 cfg.SMTPPoolSize,
+		MaxMessagesPerConnection: cfg.SMTPMaxMessagesPerConnection,
+		IdleTimeout:              cfg.SMTPIdleTimeout,
+	})
+}
+
+// newHTTPEmailProvider sends through an HTTP email API. The API signs messages itself, so DKIM is not used.
+func newHTTPEmailProvider(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error) {
+	if dkim != nil {
+		return nil, fmt.Errorf("DKIM signing is not supported by the http provider, configure it in the email API instead: %w", apperrors.ErrValidation)
+	}
+
+	return service.NewHTTPEmailProvider(service.HTTPEmailProviderConfig{
+		Endpoint: cfg.EmailAPIURL,
+		APIKey:   cfg.EmailAPIKey,
+	})
+}
+
+// newFileEmailProvider writes messages to EMAIL_DIR as .eml files, a Maildir or an mbox file.
+func newFileEmailProvider(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error) {
+	return service.NewFileEmailProvider(cfg.EmailDir, cfg.EmailProvider, dkim)
+}
+
+// newMemoryMailbox keeps messages in memory for the /dev/mailbox viewer.
+func newMemoryMailbox(cfg *config.Config, dkim *service.DKIMSigner) (service.EmailProvider, error) {
+	return service.NewMemoryMailbox(service.DefaultMemoryMailboxSize), nil
+}
+
+func emailProviderNames() []string {
+	names := make([]string, 0, len(emailProviders))
+	for name := range emailProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newDKIMSigner creates the DKIM signer for outgoing mail.
+// It returns nil without an error when DKIM is not configured, so messages are sent unsigned.
+func newDKIMSigner(domain, selector, privateKeyPEM string) (*service.DKIMSigner, error) {
+	if domain == "" && selector == "" && privateKeyPEM == "" {
+		return nil, nil
+	}
+
+	signer, err := service.NewDKIMSigner(domain, selector, []byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DKIM signer: %w", err)
+	}
+	return signer, nil
+}
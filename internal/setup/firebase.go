@@ -88,3 +88,14 @@ func NewPasswordResetService(apiKey string, httpClient *http.Client, logger *log
 	}
 	return service.NewFirebasePasswordResetService(config)
 }
+
+// NewEmailPasswordResetService creates a password reset service that sends the reset link with our own
+// email template instead of Firebase's. The Firebase auth client generates the link.
+func NewEmailPasswordResetService(client *auth.Client, emailService service.EmailService, logger *log.Logger) (service.PasswordResetService, error) {
+	config := service.EmailPasswordResetServiceConfig{
+		LinkGenerator: client,
+		EmailService:  emailService,
+		Logger:        logger,
+	}
+	return service.NewEmailPasswordResetService(config)
+}
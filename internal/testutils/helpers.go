@@ -41,7 +41,6 @@ func CreateTestSubscriber(newsletterID string, suffix int) *models.Subscriber {
 		NewsletterID:     newsletterID,
 		SubscriptionDate: now,
 		Status:           models.SubscriberStatusActive,
-		UnsubscribeToken: fmt.Sprintf("test_token_%d_%d", suffix, timestamp),
 	}
 }
 
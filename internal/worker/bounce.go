@@ -0,0 +1,258 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+)
+
+// BounceMailboxConfig holds the settings of the bounce mailbox poller.
+type BounceMailboxConfig struct {
+	Path         string        // Maildir directory or mbox file the bounce address is delivered to
+	Format       string        // maildir or mbox
+	PollInterval time.Duration // How often the mailbox is checked for new messages
+}
+
+// BounceMailboxWorker reads delivery status notifications from a local mailbox and hands them to the bounce service.
+// Feedback loop reports delivered to the same mailbox are handed to the complaint service.
+// Maildir messages are moved from new/ to cur/ once processed. An mbox file is renamed before it is read, so the
+// mail server starts a new file for later bounces, and removed once all of its messages are processed.
+// Messages that fail because of a temporary error, such as the database being down, are tried again on the next poll.
+type BounceMailboxWorker struct {
+	bounceService    service.BounceServiceInterface
+	complaintService service.ComplaintServiceInterface // Optional
+	config           BounceMailboxConfig
+	logger           *log.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBounceMailboxWorker creates a new BounceMailboxWorker. Without a complaint service, feedback loop reports are skipped.
+func NewBounceMailboxWorker(
+	bounceService service.BounceServiceInterface,
+	complaintService service.ComplaintServiceInterface,
+	config BounceMailboxConfig,
+	logger *log.Logger,
+) (*BounceMailboxWorker, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("bounce mailbox path is required")
+	}
+	if config.Format != service.LocalEmailFormatMaildir && config.Format != service.LocalEmailFormatMbox {
+		return nil, fmt.Errorf("unsupported bounce mailbox format %q, expected maildir or mbox", config.Format)
+	}
+	if config.PollInterval <= 0 {
+		return nil, fmt.Errorf("bounce mailbox poll interval must be positive")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	return &BounceMailboxWorker{
+		bounceService:    bounceService,
+		complaintService: complaintService,
+		config:           config,
+		logger:           logger,
+	}, nil
+}
+
+// Start launches the poller goroutine. It runs until Stop is called or ctx is cancelled.
+func (w *BounceMailboxWorker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.wg.Add(1)
+	go w.run(ctx)
+	w.logger.Printf("Bounce mailbox worker started, reading %s (%s) every %s", w.config.Path, w.config.Format, w.config.PollInterval)
+}
+
+// Stop signals the poller to finish the current message and waits for it to exit.
+func (w *BounceMailboxWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	w.logger.Printf("Bounce mailbox worker stopped")
+}
+
+func (w *BounceMailboxWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Printf("Failed to read bounce mailbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll processes every message waiting in the mailbox.
+func (w *BounceMailboxWorker) poll(ctx context.Context) error {
+	if w.config.Format == service.LocalEmailFormatMbox {
+		return w.pollMbox(ctx)
+	}
+	return w.pollMaildir(ctx)
+}
+
+// pollMaildir processes the messages in new/ and marks each as seen in cur/ once it is done.
+func (w *BounceMailboxWorker) pollMaildir(ctx context.Context) error {
+	entries, err := os.ReadDir(filepath.Join(w.config.Path, "new"))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(w.config.Path, "new", entry.Name())
+		message, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := w.process(ctx, entry.Name(), message); err != nil {
+			return err
+		}
+		if err := os.Rename(path, filepath.Join(w.config.Path, "cur", entry.Name()+":2,S")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollMbox moves the mbox file aside and processes its messages. When processing stops early,
+// the unprocessed messages are written back to the moved file and picked up by the next poll.
+func (w *BounceMailboxWorker) pollMbox(ctx context.Context) error {
+	processing := w.config.Path + ".processing"
+	if _, err := os.Stat(processing); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(w.config.Path, processing); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	content, err := os.ReadFile(processing)
+	if err != nil {
+		return err
+	}
+	messages := splitMbox(content)
+
+	for i, message := range messages {
+		err := ctx.Err()
+		if err == nil {
+			err = w.process(ctx, fmt.Sprintf("%s #%d", filepath.Base(w.config.Path), i+1), message.body)
+		}
+		if err != nil {
+			var rest bytes.Buffer
+			for _, m := range messages[i:] {
+				rest.Write(m.raw)
+			}
+			if writeErr := os.WriteFile(processing, rest.Bytes(), 0o600); writeErr != nil {
+				return writeErr
+			}
+			return err
+		}
+	}
+	return os.Remove(processing)
+}
+
+// process hands one message to the bounce service, or to the complaint service when it is not a delivery
+// status notification. Messages that are neither are logged and skipped, so they do not block the mailbox.
+func (w *BounceMailboxWorker) process(ctx context.Context, name string, message []byte) error {
+	result, err := w.bounceService.ProcessBounceMessage(ctx, bytes.NewReader(message))
+	if errors.Is(err, service.ErrNotDeliveryStatusReport) && w.complaintService != nil {
+		return w.processComplaint(ctx, name, message)
+	}
+	if errors.Is(err, service.ErrNotDeliveryStatusReport) {
+		w.logger.Printf("Skipping bounce mailbox message %s: %v", name, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("processing message %s: %w", name, err)
+	}
+	for _, email := range result.Suppressed {
+		w.logger.Printf("Suppressed %s after repeated hard bounces", email)
+	}
+	return nil
+}
+
+// processComplaint hands a message that is not a delivery status notification to the complaint service.
+func (w *BounceMailboxWorker) processComplaint(ctx context.Context, name string, message []byte) error {
+	result, err := w.complaintService.ProcessComplaintMessage(ctx, bytes.NewReader(message))
+	if errors.Is(err, service.ErrNotFeedbackReport) {
+		w.logger.Printf("Skipping bounce mailbox message %s: neither a delivery status notification nor an abuse report", name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("processing message %s: %w", name, err)
+	}
+	switch {
+	case result.Complaint != nil:
+		w.logger.Printf("Suppressed %s after a spam complaint about newsletter %s", result.Complaint.Email, result.Complaint.NewsletterID)
+	case !result.Matched:
+		w.logger.Printf("Abuse report %s did not match a subscription", name)
+	}
+	return nil
+}
+
+// mboxMessage is a message of an mbox file: raw is the entry as stored, body the unquoted message.
+type mboxMessage struct {
+	raw  []byte
+	body []byte
+}
+
+// splitMbox splits an mbox file at its "From " separator lines. Lines quoted as ">From " (mboxrd) are unquoted.
+func splitMbox(content []byte) []mboxMessage {
+	var starts []int
+	for offset := 0; offset < len(content); {
+		if bytes.HasPrefix(content[offset:], []byte("From ")) {
+			starts = append(starts, offset)
+		}
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+
+	messages := make([]mboxMessage, 0, len(starts))
+	for i, start := range starts {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		raw := content[start:end]
+
+		var body bytes.Buffer
+		lines := bytes.SplitAfter(raw, []byte("\n"))
+		for _, line := range lines[1:] {
+			if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) && line[0] == '>' {
+				line = line[1:]
+			}
+			body.Write(line)
+		}
+		messages = append(messages, mboxMessage{raw: raw, body: body.Bytes()})
+	}
+	return messages
+}
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// fakeBounceService records the subjects of the messages it is given and fails on request.
+type fakeBounceService struct {
+	subjects []string
+	failOn   string // Subject that fails with a temporary error
+}
+
+func (f *fakeBounceService) ProcessBounceMessage(ctx context.Context, message io.Reader) (*service.BounceResult, error) {
+	content, _ := io.ReadAll(message)
+	subject := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		if s, ok := strings.CutPrefix(line, "Subject: "); ok {
+			subject = strings.TrimSpace(s)
+			break
+		}
+	}
+	if subject == f.failOn {
+		return nil, errors.New("connection refused")
+	}
+	f.subjects = append(f.subjects, subject)
+	if strings.HasPrefix(subject, "Out of office") {
+		return nil, service.ErrNotDeliveryStatusReport
+	}
+	return &service.BounceResult{}, nil
+}
+
+// fakeComplaintService counts the messages it is given; only those with "Abuse report" in the subject are reports.
+type fakeComplaintService struct {
+	reports int
+	skipped int
+}
+
+func (f *fakeComplaintService) ProcessComplaintMessage(ctx context.Context, message io.Reader) (*service.ComplaintResult, error) {
+	content, _ := io.ReadAll(message)
+	if !strings.Contains(string(content), "Abuse report") {
+		f.skipped++
+		return nil, service.ErrNotFeedbackReport
+	}
+	f.reports++
+	return &service.ComplaintResult{Matched: true, Complaint: &models.Complaint{Email: "reader@example.net", NewsletterID: "nl-1"}}, nil
+}
+
+func (f *fakeComplaintService) GetComplaintStats(ctx context.Context, editorID string, newsletterID string, since time.Time) (*models.ComplaintStats, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newTestBounceWorker(t *testing.T, bounceService service.BounceServiceInterface, path, format string) *BounceMailboxWorker {
+	w, err := NewBounceMailboxWorker(bounceService, nil, BounceMailboxConfig{Path: path, Format: format, PollInterval: 1}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	return w
+}
+
+func TestBounceMailboxWorker_Maildir(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0o755))
+	}
+	for name, subject := range map[string]string{"1.a.host": "Bounce 1", "2.b.host": "Out of office", "3.c.host": "Bounce 3"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new", name), []byte("Subject: "+subject+"\n\nbody\n"), 0o644))
+	}
+
+	bounces := &fakeBounceService{failOn: "Bounce 3"}
+	w := newTestBounceWorker(t, bounces, dir, service.LocalEmailFormatMaildir)
+
+	// A temporary failure leaves the message in new/ for the next poll; skipped messages are done.
+	assert.Error(t, w.poll(context.Background()))
+	assert.Equal(t, []string{"Bounce 1", "Out of office"}, bounces.subjects)
+	assertDirEntries(t, filepath.Join(dir, "new"), "3.c.host")
+	assertDirEntries(t, filepath.Join(dir, "cur"), "1.a.host:2,S", "2.b.host:2,S")
+
+	bounces.failOn = ""
+	require.NoError(t, w.poll(context.Background()))
+	assertDirEntries(t, filepath.Join(dir, "new"))
+	assert.Equal(t, []string{"Bounce 1", "Out of office", "Bounce 3"}, bounces.subjects)
+}
+
+func TestBounceMailboxWorker_Mbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bounces")
+	mbox := "From MAILER-DAEMON Mon May  6 10:00:00 2024\n" +
+		"Subject: Bounce 1\n\n>From the original message\n\n" +
+		"From MAILER-DAEMON Mon May  6 10:01:00 2024\n" +
+		"Subject: Bounce 2\n\nbody\n\n" +
+		"From MAILER-DAEMON Mon May  6 10:02:00 2024\n" +
+		"Subject: Bounce 3\n\nbody\n\n"
+	require.NoError(t, os.WriteFile(path, []byte(mbox), 0o600))
+
+	bounces := &fakeBounceService{failOn: "Bounce 2"}
+	w := newTestBounceWorker(t, bounces, path, service.LocalEmailFormatMbox)
+
+	assert.Error(t, w.poll(context.Background()))
+	assert.Equal(t, []string{"Bounce 1"}, bounces.subjects)
+	_, err := os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "the mbox is moved aside while it is processed")
+
+	// New bounces delivered meanwhile wait until the moved file is done.
+	require.NoError(t, os.WriteFile(path, []byte("From MAILER-DAEMON Mon May  6 11:00:00 2024\nSubject: Bounce 4\n\nbody\n"), 0o600))
+
+	bounces.failOn = ""
+	require.NoError(t, w.poll(context.Background()))
+	assert.Equal(t, []string{"Bounce 1", "Bounce 2", "Bounce 3"}, bounces.subjects)
+	require.NoError(t, w.poll(context.Background()))
+	assert.Equal(t, []string{"Bounce 1", "Bounce 2", "Bounce 3", "Bounce 4"}, bounces.subjects)
+
+	_, err = os.Stat(path + ".processing")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	require.NoError(t, w.poll(context.Background()), "a missing mbox means there is nothing to do")
+}
+
+func TestBounceMailboxWorker_Complaints(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0o755))
+	}
+	for name, subject := range map[string]string{"1.a.host": "Bounce 1", "2.b.host": "Out of office", "3.c.host": "Out of office: Abuse report"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new", name), []byte("Subject: "+subject+"\n\nbody\n"), 0o644))
+	}
+
+	bounces := &fakeBounceService{}
+	complaints := &fakeComplaintService{}
+	w, err := NewBounceMailboxWorker(bounces, complaints, BounceMailboxConfig{Path: dir, Format: service.LocalEmailFormatMaildir, PollInterval: 1}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+
+	// Messages that are not delivery status notifications are tried as abuse reports.
+	require.NoError(t, w.poll(context.Background()))
+	assert.Equal(t, 1, complaints.reports)
+	assert.Equal(t, 1, complaints.skipped)
+	assertDirEntries(t, filepath.Join(dir, "new"))
+}
+
+func TestSplitMbox(t *testing.T) {
+	messages := splitMbox([]byte("From a@example.org Mon May  6 10:00:00 2024\nSubject: One\n\n>From here\n>>From there\n\n" +
+		"From b@example.org Mon May  6 10:01:00 2024\nSubject: Two\n\nbody\n"))
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Subject: One\n\nFrom here\n>From there\n\n", string(messages[0].body))
+	assert.True(t, strings.HasPrefix(string(messages[1].raw), "From b@example.org"))
+	assert.Equal(t, "Subject: Two\n\nbody\n", string(messages[1].body))
+}
+
+func assertDirEntries(t *testing.T, dir string, expected ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if expected == nil {
+		expected = []string{}
+	}
+	assert.ElementsMatch(t, expected, names)
+}
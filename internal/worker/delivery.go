@@ -0,0 +1,395 @@
+// Package worker contains background processes that run inside the server,
+// such as the pool that drains the email delivery queue.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// DeliveryWorkerConfig holds tuning parameters for the delivery worker pool.
+type DeliveryWorkerConfig struct {
+	Workers      int           // Number of concurrent workers
+	BatchSize    int           // Deliveries claimed per poll by a single worker
+	PollInterval time.Duration // How long an idle worker waits before polling again
+	LockTimeout  time.Duration // After this long a claimed delivery is considered abandoned and reclaimed
+	MaxAttempts  int           // Attempts before a transiently failing delivery is given up on
+	RetryBase    time.Duration // Delay before the first retry; doubles with every further attempt
+	RetryMax     time.Duration // Upper bound for the retry delay
+	AppBaseURL   string        // For generating unsubscribe and preference center links
+	Limits       SendLimits    // Pacing of outgoing messages
+}
+
+// DeliveryWorker drains the Postgres-backed delivery queue and sends newsletter issues.
+// Deliveries live in the database, so anything not yet sent survives a restart.
+// Transient SMTP failures are retried with exponential backoff; permanent rejections are not.
+// Sends are paced by the configured SendLimits; deliveries over the daily limit wait for the next day.
+// Right before sending, deliveries to subscribers who left, bounced, complained or paused since the post was
+// published, and to addresses suppressed since, are cancelled.
+type DeliveryWorker struct {
+	deliveryRepo    repository.DeliveryRepository
+	postRepo        repository.PostRepository
+	newsletterRepo  repository.NewsletterRepository
+	subscriberRepo  repository.SubscriberRepository
+	suppressionRepo repository.SuppressionRepository
+	emailService    service.EmailService
+	config          DeliveryWorkerConfig
+	limiter         *SendLimiter
+	logger          *log.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDeliveryWorker creates a new DeliveryWorker.
+func NewDeliveryWorker(
+	deliveryRepo repository.DeliveryRepository,
+	postRepo repository.PostRepository,
+	newsletterRepo repository.NewsletterRepository,
+	subscriberRepo repository.SubscriberRepository,
+	suppressionRepo repository.SuppressionRepository,
+	emailService service.EmailService,
+	config DeliveryWorkerConfig,
+	logger *log.Logger,
+) (*DeliveryWorker, error) {
+	if config.Workers <= 0 {
+		return nil, fmt.Errorf("delivery worker count must be positive")
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("delivery batch size must be positive")
+	}
+	if config.PollInterval <= 0 {
+		return nil, fmt.Errorf("delivery poll interval must be positive")
+	}
+	if config.LockTimeout <= 0 {
+		return nil, fmt.Errorf("delivery lock timeout must be positive")
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("delivery max attempts must be positive")
+	}
+	if config.RetryBase <= 0 || config.RetryMax < config.RetryBase {
+		return nil, fmt.Errorf("delivery retry delays must be positive and max must not be below base")
+	}
+	if err := config.Limits.validate(); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	return &DeliveryWorker{
+		deliveryRepo:    deliveryRepo,
+		postRepo:        postRepo,
+		newsletterRepo:  newsletterRepo,
+		subscriberRepo:  subscriberRepo,
+		suppressionRepo: suppressionRepo,
+		emailService:    emailService,
+		config:          config,
+		limiter:         newSendLimiter(config.Limits, deliveryRepo.CountDeliveriesSentSince),
+		logger:          logger,
+	}, nil
+}
+
+// Limiter returns the limiter pacing the worker's sends. Other senders share it so their messages count
+// against the same limits.
+func (w *DeliveryWorker) Limiter() *SendLimiter {
+	return w.limiter
+}
+
+// Start launches the worker goroutines. They run until Stop is called or ctx is cancelled.
+func (w *DeliveryWorker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	for i := 0; i < w.config.Workers; i++ {
+		w.wg.Add(1)
+		go w.run(ctx)
+	}
+	w.logger.Printf("Delivery worker pool started with %d workers", w.config.Workers)
+}
+
+// Stop signals all workers to finish their current delivery and waits for them to exit.
+func (w *DeliveryWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	w.logger.Printf("Delivery worker pool stopped")
+}
+
+// run is the main loop of a single worker.
+func (w *DeliveryWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		processed := w.processBatch(ctx)
+
+		// Keep draining while there is work; otherwise wait for the next poll.
+		if processed > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.config.PollInterval):
+		}
+	}
+}
+
+// processBatch claims a batch of due deliveries and sends them. It returns the number claimed.
+func (w *DeliveryWorker) processBatch(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	// Once the daily limit is reached nothing is claimed; due deliveries stay queued until the quota resets.
+	batchSize := w.config.BatchSize
+	remaining, resetAt, err := w.limiter.remaining(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Printf("Failed to check the daily send limit: %v", err)
+		}
+		return 0
+	}
+	if remaining == 0 {
+		w.logDailyLimitReached(resetAt)
+		return 0
+	}
+	if remaining > 0 && remaining < batchSize {
+		batchSize = remaining
+	}
+
+	// Claimed deliveries must all be sent before the lock times out, so the claim is sized by the rate
+	// limit, shared by all workers, and nothing is claimed while the rate allows no further message.
+	if limit := w.limiter.claimLimit(w.config.LockTimeout); limit > 0 {
+		batchSize = min(batchSize, max(limit/max(w.config.Workers, 1), 1))
+	}
+	if err := w.limiter.ready(ctx); err != nil {
+		return 0
+	}
+
+	deliveries, err := w.deliveryRepo.ClaimDeliveries(ctx, batchSize, w.config.LockTimeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Printf("Failed to claim deliveries: %v", err)
+		}
+		return 0
+	}
+
+	issues := make(map[string]*issue)
+	for i, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Shutting down: hand the rest back to the queue so the next process picks them up.
+			w.releaseDeliveries(deliveries[i:])
+			break
+		}
+		w.processDelivery(ctx, delivery, issues)
+	}
+
+	// Posts whose last pending delivery was just handled move from sending to sent or failed.
+	completeCtx := context.WithoutCancel(ctx)
+	for postID := range issues {
+		if err := w.postRepo.CompletePostSending(completeCtx, postID); err != nil {
+			w.logger.Printf("Failed to complete sending of post %s: %v", postID, err)
+		}
+	}
+	return len(deliveries)
+}
+
+// processDelivery sends a single delivery and records the outcome.
+// A delivery that has started is allowed to finish even if shutdown begins, so it is not sent twice.
+func (w *DeliveryWorker) processDelivery(ctx context.Context, delivery models.Delivery, issues map[string]*issue) {
+	done, ok := w.acquireSendSlot(ctx, delivery)
+	if !ok {
+		return
+	}
+	sent := false
+	defer func() { done(sent) }()
+
+	ctx = context.WithoutCancel(ctx)
+
+	iss, ok := issues[delivery.PostID]
+	if !ok {
+		loaded, err := w.loadIssue(ctx, delivery.PostID)
+		if err != nil {
+			w.recordFailure(ctx, delivery, err)
+			return
+		}
+		iss = loaded
+		issues[delivery.PostID] = iss
+	}
+
+	reason, err := w.skipReason(ctx, delivery, iss.newsletter.ID)
+	if err != nil {
+		w.recordFailure(ctx, delivery, err)
+		return
+	}
+	if reason != "" {
+		w.cancelDelivery(ctx, delivery, reason)
+		return
+	}
+
+	unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", w.config.AppBaseURL, delivery.UnsubscribeToken)
+	data := service.NewIssueEmailTemplateData(iss.newsletter, iss.post, delivery.Email, unsubscribeLink)
+	data.Subscriber = service.NewEmailSubscriberData(delivery.Email, delivery.SubscriberName, delivery.SubscriberAttributes)
+	data.Subscriber.ID = delivery.SubscriberID
+	data.Links.Preferences = service.PreferencesLink(w.config.AppBaseURL, delivery.UnsubscribeToken)
+
+	if err := w.emailService.SendNewsletterIssueHTML(ctx, delivery.Email, data); err != nil {
+		w.recordFailure(ctx, delivery, err)
+		return
+	}
+	sent = true
+
+	if err := w.deliveryRepo.MarkDeliverySent(ctx, delivery.ID); err != nil {
+		w.logger.Printf("Failed to mark delivery %s as sent: %v", delivery.ID, err)
+	}
+}
+
+// skipReason returns why the delivery must no longer be sent, or an empty string when it may go out.
+// Recipients are checked at enqueue time too, but a delivery may wait in the queue or for retries for long.
+func (w *DeliveryWorker) skipReason(ctx context.Context, delivery models.Delivery, newsletterID string) (string, error) {
+	subscriber, err := w.subscriberRepo.GetSubscriberByID(ctx, delivery.SubscriberID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+			return "subscriber no longer exists", nil
+		}
+		return "", fmt.Errorf("loading subscriber: %w", err)
+	}
+	if subscriber.Status != models.SubscriberStatusActive {
+		return fmt.Sprintf("subscriber is %s", subscriber.Status), nil
+	}
+	if subscriber.IsPaused(time.Now()) {
+		return "delivery is paused", nil
+	}
+
+	suppressed, err := w.suppressionRepo.FilterSuppressedEmails(ctx, newsletterID, []string{strings.ToLower(delivery.Email)})
+	if err != nil {
+		return "", fmt.Errorf("checking the suppression list: %w", err)
+	}
+	if len(suppressed) > 0 {
+		return "address is suppressed", nil
+	}
+	return "", nil
+}
+
+// cancelDelivery drops a delivery that must no longer be sent without counting an attempt.
+func (w *DeliveryWorker) cancelDelivery(ctx context.Context, delivery models.Delivery, reason string) {
+	w.logger.Printf("Cancelling delivery of post %s to %s: %s", delivery.PostID, delivery.Email, reason)
+	if err := w.deliveryRepo.CancelDelivery(ctx, delivery.ID, reason); err != nil {
+		w.logger.Printf("Failed to cancel delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// acquireSendSlot waits until the send limits allow the delivery to be sent. It returns false when the
+// delivery was handed back to the queue instead: rolled over because the daily limit was reached by another
+// worker, or released because shutdown began while waiting. Otherwise the returned function must be called
+// once the send is over, reporting whether the message went out.
+func (w *DeliveryWorker) acquireSendSlot(ctx context.Context, delivery models.Delivery) (func(sent bool), bool) {
+	done, ok, resetAt, err := w.limiter.acquire(ctx, delivery.Email)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		w.releaseDeliveries([]models.Delivery{delivery})
+		return nil, false
+	case err != nil:
+		w.logger.Printf("Failed to check the daily send limit for delivery %s: %v", delivery.ID, err)
+		w.deferDelivery(delivery, time.Now().UTC().Add(w.config.PollInterval))
+		return nil, false
+	case !ok:
+		w.logDailyLimitReached(resetAt)
+		w.deferDelivery(delivery, resetAt)
+		return nil, false
+	}
+	return done, true
+}
+
+// logDailyLimitReached logs once per day that the daily limit stops sending until resetAt.
+func (w *DeliveryWorker) logDailyLimitReached(resetAt time.Time) {
+	if !w.limiter.firstLimitReached(resetAt) {
+		return
+	}
+	w.logger.Printf("Daily send limit of %d messages reached, remaining deliveries and digests roll over to %s",
+		w.config.Limits.DailyLimit, resetAt.Format(time.RFC3339))
+}
+
+// deferDelivery hands a claimed delivery back to the queue until the given time without counting an attempt.
+func (w *DeliveryWorker) deferDelivery(delivery models.Delivery, until time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := w.deliveryRepo.DeferDelivery(ctx, delivery.ID, until); err != nil {
+		w.logger.Printf("Failed to defer delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// issue is a post together with its newsletter, loaded once per batch.
+type issue struct {
+	post       *models.Post
+	newsletter *models.Newsletter
+}
+
+// loadIssue loads the post of a delivery and the newsletter it belongs to.
+func (w *DeliveryWorker) loadIssue(ctx context.Context, postID string) (*issue, error) {
+	post, err := w.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("loading post: %w", err)
+	}
+	newsletter, err := w.newsletterRepo.GetNewsletterByID(ctx, post.NewsletterID)
+	if err != nil {
+		return nil, fmt.Errorf("loading newsletter: %w", err)
+	}
+	return &issue{post: post, newsletter: newsletter}, nil
+}
+
+// recordFailure stores the error and either schedules a retry or gives up on the delivery.
+func (w *DeliveryWorker) recordFailure(ctx context.Context, delivery models.Delivery, sendErr error) {
+	attempt := delivery.Attempts + 1
+
+	var retryAt *time.Time
+	if !service.IsPermanentEmailError(sendErr) && attempt < w.config.MaxAttempts {
+		next := time.Now().UTC().Add(retryDelay(attempt, w.config.RetryBase, w.config.RetryMax))
+		retryAt = &next
+		w.logger.Printf("Delivery of post %s to %s failed (attempt %d/%d), retrying at %s: %v",
+			delivery.PostID, delivery.Email, attempt, w.config.MaxAttempts, next.Format(time.RFC3339), sendErr)
+	} else {
+		w.logger.Printf("Delivery of post %s to %s permanently failed after %d attempt(s): %v",
+			delivery.PostID, delivery.Email, attempt, sendErr)
+	}
+
+	if err := w.deliveryRepo.MarkDeliveryFailed(ctx, delivery.ID, sendErr.Error(), retryAt); err != nil {
+		w.logger.Printf("Failed to record failure for delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// releaseDeliveries returns claimed but unprocessed deliveries to the queue.
+// It uses a fresh context because the worker context is already cancelled.
+func (w *DeliveryWorker) releaseDeliveries(deliveries []models.Delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, delivery := range deliveries {
+		if err := w.deliveryRepo.ReleaseDelivery(ctx, delivery.ID); err != nil {
+			w.logger.Printf("Failed to release delivery %s: %v", delivery.ID, err)
+		}
+	}
+}
+
+// retryDelay returns the backoff before the given retry: base, 2*base, 4*base, ... capped at max.
+func retryDelay(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
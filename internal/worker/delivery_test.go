@@ -0,0 +1,322 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// recipientSubscriberRepository stubs the subscriber lookup of the recheck before sending
+type recipientSubscriberRepository struct {
+	repository.SubscriberRepository
+	mock.Mock
+}
+
+func (m *recipientSubscriberRepository) GetSubscriberByID(ctx context.Context, subscriberID string) (*models.Subscriber, error) {
+	args := m.Called(ctx, subscriberID)
+	subscriber, _ := args.Get(0).(*models.Subscriber)
+	return subscriber, args.Error(1)
+}
+
+// recipientSuppressionRepository stubs the suppression list lookup of the recheck before sending
+type recipientSuppressionRepository struct {
+	repository.SuppressionRepository
+	mock.Mock
+}
+
+func (m *recipientSuppressionRepository) FilterSuppressedEmails(ctx context.Context, newsletterID string, emails []string) ([]string, error) {
+	args := m.Called(ctx, newsletterID, emails)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockDeliveryRepository mocks the delivery repository
+type MockDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryRepository) EnqueueDeliveries(ctx context.Context, postID string, deliveries []models.Delivery) (int, error) {
+	args := m.Called(ctx, postID, deliveries)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) ClaimDeliveries(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.Delivery, error) {
+	args := m.Called(ctx, limit, lockTimeout)
+	return args.Get(0).([]models.Delivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) MarkDeliverySent(ctx context.Context, deliveryID string) error {
+	args := m.Called(ctx, deliveryID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) MarkDeliveryFailed(ctx context.Context, deliveryID string, lastError string, retryAt *time.Time) error {
+	args := m.Called(ctx, deliveryID, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) ReleaseDelivery(ctx context.Context, deliveryID string) error {
+	args := m.Called(ctx, deliveryID)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) DeferDelivery(ctx context.Context, deliveryID string, until time.Time) error {
+	args := m.Called(ctx, deliveryID, until)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CancelDelivery(ctx context.Context, deliveryID string, reason string) error {
+	args := m.Called(ctx, deliveryID, reason)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) CountDeliveriesSentSince(ctx context.Context, since time.Time) (int, error) {
+	args := m.Called(ctx, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) CountNewsletterDeliveriesSentSince(ctx context.Context, newsletterID string, since time.Time) (int, error) {
+	args := m.Called(ctx, newsletterID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) CountSubscriberDeliveriesSince(ctx context.Context, newsletterID string, status models.DeliveryStatus, since time.Time) (map[string]int, error) {
+	args := m.Called(ctx, newsletterID, status, since)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) ListDeliveriesByPostID(ctx context.Context, postID string, status models.DeliveryStatus, limit int, offset int) ([]models.Delivery, int, error) {
+	args := m.Called(ctx, postID, status, limit, offset)
+	return args.Get(0).([]models.Delivery), args.Int(1), args.Error(2)
+}
+
+func (m *MockDeliveryRepository) CountDeliveriesByStatus(ctx context.Context, postID string) (map[models.DeliveryStatus]int, error) {
+	args := m.Called(ctx, postID)
+	return args.Get(0).(map[models.DeliveryStatus]int), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) CancelPendingDeliveries(ctx context.Context, postID string) (int, error) {
+	args := m.Called(ctx, postID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		expected time.Duration
+	}{
+		{name: "first retry uses base delay", attempt: 1, expected: time.Minute},
+		{name: "second retry doubles", attempt: 2, expected: 2 * time.Minute},
+		{name: "fourth retry", attempt: 4, expected: 8 * time.Minute},
+		{name: "capped at max", attempt: 10, expected: 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryDelay(tt.attempt, time.Minute, 30*time.Minute))
+		})
+	}
+}
+
+func TestDeliveryWorker_RecordFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    int
+		sendErr     error
+		expectRetry bool
+	}{
+		{
+			name:        "transient SMTP 4xx is retried",
+			attempts:    0,
+			sendErr:     &textproto.Error{Code: 451, Msg: "try again later"},
+			expectRetry: true,
+		},
+		{
+			name:        "network error is retried",
+			attempts:    1,
+			sendErr:     errors.New("connection reset by peer"),
+			expectRetry: true,
+		},
+		{
+			name:        "permanent SMTP 5xx is not retried",
+			attempts:    0,
+			sendErr:     &textproto.Error{Code: 550, Msg: "mailbox unavailable"},
+			expectRetry: false,
+		},
+		{
+			name:        "transient error on last attempt gives up",
+			attempts:    2,
+			sendErr:     &textproto.Error{Code: 421, Msg: "service not available"},
+			expectRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockDeliveryRepository{}
+			mockRepo.On("MarkDeliveryFailed", mock.Anything, "delivery_1", tt.sendErr.Error(), mock.MatchedBy(func(retryAt *time.Time) bool {
+				return (retryAt != nil) == tt.expectRetry
+			})).Return(nil)
+
+			w := &DeliveryWorker{
+				deliveryRepo: mockRepo,
+				config: DeliveryWorkerConfig{
+					MaxAttempts: 3,
+					RetryBase:   time.Minute,
+					RetryMax:    time.Hour,
+				},
+				logger: log.New(io.Discard, "", 0),
+			}
+
+			delivery := models.Delivery{ID: "delivery_1", PostID: "post_1", Email: "reader@example.com", Attempts: tt.attempts}
+			w.recordFailure(context.Background(), delivery, tt.sendErr)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeliveryWorker_DailyLimitRollsOver(t *testing.T) {
+	mockRepo := &MockDeliveryRepository{}
+	mockRepo.On("CountDeliveriesSentSince", mock.Anything, mock.Anything).Return(2, nil)
+
+	w := &DeliveryWorker{
+		deliveryRepo: mockRepo,
+		config:       DeliveryWorkerConfig{BatchSize: 20, Limits: SendLimits{DailyLimit: 3}},
+		logger:       log.New(io.Discard, "", 0),
+	}
+	w.limiter = newSendLimiter(w.config.Limits, mockRepo.CountDeliveriesSentSince)
+
+	// One message of today's quota is left.
+	done, ok := w.acquireSendSlot(context.Background(), models.Delivery{ID: "delivery_1", Email: "a@gmail.com"})
+	assert.True(t, ok)
+	done(true)
+
+	// The next delivery rolls over to tomorrow without counting as a failed attempt.
+	var deferredUntil time.Time
+	mockRepo.On("DeferDelivery", mock.Anything, "delivery_2", mock.Anything).Run(func(args mock.Arguments) {
+		deferredUntil = args.Get(2).(time.Time)
+	}).Return(nil)
+	_, ok = w.acquireSendSlot(context.Background(), models.Delivery{ID: "delivery_2", Email: "b@gmail.com"})
+	assert.False(t, ok)
+
+	now := time.Now().UTC()
+	assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC), deferredUntil)
+	mockRepo.AssertNotCalled(t, "MarkDeliveryFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Nothing more is claimed until the quota resets.
+	assert.Equal(t, 0, w.processBatch(context.Background()))
+	mockRepo.AssertNotCalled(t, "ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeliveryWorker_ClaimSizedByRate(t *testing.T) {
+	mockRepo := &MockDeliveryRepository{}
+	mockRepo.On("ClaimDeliveries", mock.Anything, 2, 4*time.Second).Return([]models.Delivery{}, nil).Once()
+
+	// At two messages a second, twenty claimed deliveries would wait ten seconds for the rate and be
+	// reclaimed after the four second lock timeout while still waiting.
+	w := &DeliveryWorker{
+		deliveryRepo: mockRepo,
+		config:       DeliveryWorkerConfig{Workers: 2, BatchSize: 20, LockTimeout: 4 * time.Second, Limits: SendLimits{RatePerSecond: 2}},
+		logger:       log.New(io.Discard, "", 0),
+	}
+	w.limiter = newSendLimiter(w.config.Limits, mockRepo.CountDeliveriesSentSince)
+
+	// Nothing is claimed until the rate allows the next message.
+	require.NoError(t, w.limiter.wait(context.Background()))
+	start := time.Now()
+	assert.Equal(t, 0, w.processBatch(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	mockRepo.AssertExpectations(t)
+
+	// The wait did not use up the message, so the first claimed delivery is sent at once.
+	start = time.Now()
+	require.NoError(t, w.limiter.wait(context.Background()))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestDeliveryWorker_SkipsRecipientsGoneSinceEnqueue(t *testing.T) {
+	pausedUntil := time.Now().Add(24 * time.Hour)
+	tests := []struct {
+		name        string
+		subscriber  *models.Subscriber
+		lookupErr   error
+		suppressed  []string
+		expectCheck bool
+		reason      string
+	}{
+		{
+			name:       "unsubscribed",
+			subscriber: &models.Subscriber{ID: "sub_1", Status: models.SubscriberStatusUnsubscribed},
+			reason:     "subscriber is unsubscribed",
+		},
+		{
+			name:       "bounced",
+			subscriber: &models.Subscriber{ID: "sub_1", Status: models.SubscriberStatusBounced},
+			reason:     "subscriber is bounced",
+		},
+		{
+			name:      "deleted",
+			lookupErr: apperrors.ErrSubscriberNotFound,
+			reason:    "subscriber no longer exists",
+		},
+		{
+			name:       "paused",
+			subscriber: &models.Subscriber{ID: "sub_1", Status: models.SubscriberStatusActive, PausedUntil: &pausedUntil},
+			reason:     "delivery is paused",
+		},
+		{
+			name:        "suppressed",
+			subscriber:  &models.Subscriber{ID: "sub_1", Status: models.SubscriberStatusActive},
+			suppressed:  []string{"reader@example.com"},
+			expectCheck: true,
+			reason:      "address is suppressed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deliveryRepo := &MockDeliveryRepository{}
+			deliveryRepo.On("CancelDelivery", mock.Anything, "delivery_1", tt.reason).Return(nil)
+
+			subscriberRepo := &recipientSubscriberRepository{}
+			subscriberRepo.On("GetSubscriberByID", mock.Anything, "sub_1").Return(tt.subscriber, tt.lookupErr)
+
+			suppressionRepo := &recipientSuppressionRepository{}
+			if tt.expectCheck {
+				suppressionRepo.On("FilterSuppressedEmails", mock.Anything, "newsletter_1", []string{"reader@example.com"}).Return(tt.suppressed, nil)
+			}
+
+			// No email service is set, so any attempt to send would panic.
+			w := &DeliveryWorker{
+				deliveryRepo:    deliveryRepo,
+				subscriberRepo:  subscriberRepo,
+				suppressionRepo: suppressionRepo,
+				logger:          log.New(io.Discard, "", 0),
+			}
+			w.limiter = newSendLimiter(SendLimits{}, deliveryRepo.CountDeliveriesSentSince)
+
+			issues := map[string]*issue{
+				"post_1": {post: &models.Post{ID: "post_1"}, newsletter: &models.Newsletter{ID: "newsletter_1"}},
+			}
+			delivery := models.Delivery{ID: "delivery_1", PostID: "post_1", SubscriberID: "sub_1", Email: "Reader@Example.com"}
+			w.processDelivery(context.Background(), delivery, issues)
+
+			deliveryRepo.AssertExpectations(t)
+			suppressionRepo.AssertExpectations(t)
+			deliveryRepo.AssertNotCalled(t, "MarkDeliveryFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "github.com/GOVSEteam/strv-vse-go-newsletter/internal/errors"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// DigestWorkerConfig holds tuning parameters for the digest worker.
+type DigestWorkerConfig struct {
+	PollInterval time.Duration // How often due digests are looked up
+	BatchSize    int           // Digests claimed per poll
+	LockTimeout  time.Duration // After this long a claimed digest is considered abandoned and reclaimed
+	MaxAttempts  int           // Attempts before a transiently failing digest is given up on
+	RetryBase    time.Duration // Delay before the first retry; doubles with every further attempt
+	RetryMax     time.Duration // Upper bound for the retry delay
+	AppBaseURL   string        // For generating unsubscribe and preference center links
+}
+
+// DigestWorker sends the daily and weekly digests of subscribers who chose not to receive every issue on its own.
+// A digest holds every post due for a subscriber, and its items are retried together when it fails.
+// Digests are sent outside the delivery queue but share its send limiter, so each digest counts as one message
+// against the rate, domain and daily limits; digests over the daily limit wait for the next day.
+type DigestWorker struct {
+	digestRepo      repository.DigestRepository
+	subscriberRepo  repository.SubscriberRepository
+	suppressionRepo repository.SuppressionRepository
+	postRepo        repository.PostRepository
+	newsletterRepo  repository.NewsletterRepository
+	emailService    service.EmailService
+	limiter         *SendLimiter
+	config          DigestWorkerConfig
+	logger          *log.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDigestWorker creates a new DigestWorker. The limiter is the one of the delivery worker, see DeliveryWorker.Limiter.
+func NewDigestWorker(
+	digestRepo repository.DigestRepository,
+	subscriberRepo repository.SubscriberRepository,
+	suppressionRepo repository.SuppressionRepository,
+	postRepo repository.PostRepository,
+	newsletterRepo repository.NewsletterRepository,
+	emailService service.EmailService,
+	limiter *SendLimiter,
+	config DigestWorkerConfig,
+	logger *log.Logger,
+) (*DigestWorker, error) {
+	if config.PollInterval <= 0 {
+		return nil, fmt.Errorf("digest poll interval must be positive")
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("digest batch size must be positive")
+	}
+	if config.LockTimeout <= 0 {
+		return nil, fmt.Errorf("digest lock timeout must be positive")
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("digest max attempts must be positive")
+	}
+	if config.RetryBase <= 0 || config.RetryMax < config.RetryBase {
+		return nil, fmt.Errorf("digest retry delays must be positive and max must not be below base")
+	}
+	if limiter == nil {
+		return nil, fmt.Errorf("send limiter is required")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	return &DigestWorker{
+		digestRepo:      digestRepo,
+		subscriberRepo:  subscriberRepo,
+		suppressionRepo: suppressionRepo,
+		postRepo:        postRepo,
+		newsletterRepo:  newsletterRepo,
+		emailService:    emailService,
+		limiter:         limiter,
+		config:          config,
+		logger:          logger,
+	}, nil
+}
+
+// Start launches the worker goroutine. It runs until Stop is called or ctx is cancelled.
+func (w *DigestWorker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.wg.Add(1)
+	go w.run(ctx)
+	w.logger.Printf("Digest worker started, polling every %s", w.config.PollInterval)
+}
+
+// Stop signals the worker to finish the current digest and waits for it to exit.
+func (w *DigestWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	w.logger.Printf("Digest worker stopped")
+}
+
+func (w *DigestWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.sendDueDigests(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueDigests sends every digest that is due, one batch at a time.
+func (w *DigestWorker) sendDueDigests(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Like deliveries, digests are claimed no faster than the send limits let them go out.
+		batchSize := w.config.BatchSize
+		remaining, resetAt, err := w.limiter.remaining(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Printf("Failed to check the daily send limit: %v", err)
+			}
+			return
+		}
+		if remaining == 0 {
+			w.logDailyLimitReached(resetAt)
+			return
+		}
+		if remaining > 0 && remaining < batchSize {
+			batchSize = remaining
+		}
+		if limit := w.limiter.claimLimit(w.config.LockTimeout); limit > 0 {
+			batchSize = min(batchSize, limit)
+		}
+		if err := w.limiter.ready(ctx); err != nil {
+			return
+		}
+
+		items, err := w.digestRepo.ClaimDigestItems(ctx, batchSize, w.config.LockTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Printf("Failed to claim digest items: %v", err)
+			}
+			return
+		}
+
+		digests := groupDigestItems(items)
+		for i, digest := range digests {
+			if ctx.Err() != nil {
+				var unsent []models.DigestItem
+				for _, rest := range digests[i:] {
+					unsent = append(unsent, rest...)
+				}
+				w.releaseItems(unsent)
+				return
+			}
+			w.sendDigest(ctx, digest)
+		}
+
+		// A short batch means there is nothing more to do until the next tick.
+		if len(digests) < batchSize {
+			return
+		}
+	}
+}
+
+// groupDigestItems splits claimed items into one digest per subscriber, each ordered by when its post was published.
+func groupDigestItems(items []models.DigestItem) [][]models.DigestItem {
+	var digests [][]models.DigestItem
+	index := make(map[string]int)
+	for _, item := range items {
+		i, ok := index[item.SubscriberID]
+		if !ok {
+			i = len(digests)
+			index[item.SubscriberID] = i
+			digests = append(digests, nil)
+		}
+		digests[i] = append(digests[i], item)
+	}
+	for _, digest := range digests {
+		sort.SliceStable(digest, func(i, j int) bool { return digest[i].CreatedAt.Before(digest[j].CreatedAt) })
+	}
+	return digests
+}
+
+// sendDigest sends the posts of one subscriber's digest in a single email. Subscribers who unsubscribed or
+// paused delivery, and addresses suppressed, since the posts were published get nothing.
+func (w *DigestWorker) sendDigest(ctx context.Context, items []models.DigestItem) {
+	// Finish the digest even if shutdown begins while it is being sent; only waiting for the limits stops.
+	waitCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+	first := items[0]
+
+	subscriber, err := w.subscriberRepo.GetSubscriberByID(ctx, first.SubscriberID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrSubscriberNotFound) {
+			w.cancelItems(ctx, items, "subscriber no longer exists")
+			return
+		}
+		w.recordFailure(ctx, items, fmt.Errorf("loading subscriber: %w", err))
+		return
+	}
+	if subscriber.Status != models.SubscriberStatusActive {
+		w.cancelItems(ctx, items, fmt.Sprintf("subscriber is %s", subscriber.Status))
+		return
+	}
+	if subscriber.IsPaused(time.Now()) {
+		w.cancelItems(ctx, items, "delivery is paused")
+		return
+	}
+	suppressed, err := w.suppressionRepo.FilterSuppressedEmails(ctx, subscriber.NewsletterID, []string{strings.ToLower(first.Email)})
+	if err != nil {
+		w.recordFailure(ctx, items, fmt.Errorf("checking the suppression list: %w", err))
+		return
+	}
+	if len(suppressed) > 0 {
+		w.cancelItems(ctx, items, "address is suppressed")
+		return
+	}
+
+	posts := make([]*models.Post, 0, len(items))
+	for _, item := range items {
+		post, err := w.postRepo.GetPostByID(ctx, item.PostID)
+		if err != nil {
+			w.recordFailure(ctx, items, fmt.Errorf("loading post %s: %w", item.PostID, err))
+			return
+		}
+		posts = append(posts, post)
+	}
+	newsletter, err := w.newsletterRepo.GetNewsletterByID(ctx, subscriber.NewsletterID)
+	if err != nil {
+		w.recordFailure(ctx, items, fmt.Errorf("loading newsletter: %w", err))
+		return
+	}
+
+	unsubscribeLink := fmt.Sprintf("%s/api/subscriptions/unsubscribe?token=%s", w.config.AppBaseURL, first.UnsubscribeToken)
+	data := service.NewDigestEmailTemplateData(newsletter, posts, first.Email, unsubscribeLink)
+	data.Subscriber = service.NewEmailSubscriberData(first.Email, subscriber.Name, subscriber.Attributes)
+	data.Subscriber.ID = subscriber.ID
+	data.Links.Preferences = service.PreferencesLink(w.config.AppBaseURL, first.UnsubscribeToken)
+
+	done, ok := w.acquireSendSlot(waitCtx, items)
+	if !ok {
+		return
+	}
+	sent := false
+	defer func() { done(sent) }()
+
+	if err := w.emailService.SendDigestHTML(ctx, first.Email, data); err != nil {
+		w.recordFailure(ctx, items, err)
+		return
+	}
+	sent = true
+	if err := w.digestRepo.MarkDigestItemsSent(ctx, digestItemIDs(items)); err != nil {
+		w.logger.Printf("Failed to mark digest of subscriber %s as sent: %v", first.SubscriberID, err)
+	}
+}
+
+// acquireSendSlot waits until the send limits allow the digest to be sent. It returns false when the items
+// were handed back to the queue instead: deferred because the daily limit was reached, or released because
+// shutdown began while waiting. Otherwise the returned function must be called once the send is over.
+func (w *DigestWorker) acquireSendSlot(ctx context.Context, items []models.DigestItem) (func(sent bool), bool) {
+	done, ok, resetAt, err := w.limiter.acquire(ctx, items[0].Email)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		w.releaseItems(items)
+		return nil, false
+	case err != nil:
+		w.logger.Printf("Failed to check the daily send limit for the digest of subscriber %s: %v", items[0].SubscriberID, err)
+		w.deferItems(items, time.Now().UTC().Add(w.config.PollInterval))
+		return nil, false
+	case !ok:
+		w.logDailyLimitReached(resetAt)
+		w.deferItems(items, resetAt)
+		return nil, false
+	}
+	return done, true
+}
+
+// logDailyLimitReached logs once per day that the daily limit stops sending until resetAt.
+func (w *DigestWorker) logDailyLimitReached(resetAt time.Time) {
+	if !w.limiter.firstLimitReached(resetAt) {
+		return
+	}
+	w.logger.Printf("Daily send limit of %d messages reached, remaining deliveries and digests roll over to %s",
+		w.limiter.limits.DailyLimit, resetAt.Format(time.RFC3339))
+}
+
+// deferItems hands claimed items back to the queue until the given time without counting an attempt.
+func (w *DigestWorker) deferItems(items []models.DigestItem, until time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := w.digestRepo.DeferDigestItems(ctx, digestItemIDs(items), until); err != nil {
+		w.logger.Printf("Failed to defer digest of subscriber %s: %v", items[0].SubscriberID, err)
+	}
+}
+
+// recordFailure stores the error and either schedules a retry of the whole digest or gives up on it.
+func (w *DigestWorker) recordFailure(ctx context.Context, items []models.DigestItem, sendErr error) {
+	// Items join a digest while it is retried, so the one tried most often decides.
+	attempt := 0
+	for _, item := range items {
+		if item.Attempts > attempt {
+			attempt = item.Attempts
+		}
+	}
+	attempt++
+
+	var retryAt *time.Time
+	if !service.IsPermanentEmailError(sendErr) && attempt < w.config.MaxAttempts {
+		next := time.Now().UTC().Add(retryDelay(attempt, w.config.RetryBase, w.config.RetryMax))
+		retryAt = &next
+		w.logger.Printf("Digest to %s failed (attempt %d/%d), retrying at %s: %v",
+			items[0].Email, attempt, w.config.MaxAttempts, next.Format(time.RFC3339), sendErr)
+	} else {
+		w.logger.Printf("Digest to %s permanently failed after %d attempt(s): %v", items[0].Email, attempt, sendErr)
+	}
+
+	if err := w.digestRepo.MarkDigestItemsFailed(ctx, digestItemIDs(items), sendErr.Error(), retryAt); err != nil {
+		w.logger.Printf("Failed to record failure for digest of subscriber %s: %v", items[0].SubscriberID, err)
+	}
+}
+
+func (w *DigestWorker) cancelItems(ctx context.Context, items []models.DigestItem, reason string) {
+	w.logger.Printf("Dropping digest of subscriber %s: %s", items[0].SubscriberID, reason)
+	if err := w.digestRepo.CancelDigestItems(ctx, digestItemIDs(items)); err != nil {
+		w.logger.Printf("Failed to cancel digest of subscriber %s: %v", items[0].SubscriberID, err)
+	}
+}
+
+// releaseItems returns claimed but unsent items to the queue.
+// It uses a fresh context because the worker context is already cancelled.
+func (w *DigestWorker) releaseItems(items []models.DigestItem) {
+	if len(items) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := w.digestRepo.ReleaseDigestItems(ctx, digestItemIDs(items)); err != nil {
+		w.logger.Printf("Failed to release %d digest items: %v", len(items), err)
+	}
+}
+
+func digestItemIDs(items []models.DigestItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/models"
+)
+
+// MockDigestRepository mocks the digest repository
+type MockDigestRepository struct {
+	mock.Mock
+}
+
+func (m *MockDigestRepository) EnqueueDigestItems(ctx context.Context, postID string, items []models.DigestItem) (int, error) {
+	args := m.Called(ctx, postID, items)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDigestRepository) ClaimDigestItems(ctx context.Context, limit int, lockTimeout time.Duration) ([]models.DigestItem, error) {
+	args := m.Called(ctx, limit, lockTimeout)
+	return args.Get(0).([]models.DigestItem), args.Error(1)
+}
+
+func (m *MockDigestRepository) MarkDigestItemsSent(ctx context.Context, itemIDs []string) error {
+	args := m.Called(ctx, itemIDs)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) MarkDigestItemsFailed(ctx context.Context, itemIDs []string, lastError string, retryAt *time.Time) error {
+	args := m.Called(ctx, itemIDs, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) ReleaseDigestItems(ctx context.Context, itemIDs []string) error {
+	args := m.Called(ctx, itemIDs)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) DeferDigestItems(ctx context.Context, itemIDs []string, until time.Time) error {
+	args := m.Called(ctx, itemIDs, until)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) CancelDigestItems(ctx context.Context, itemIDs []string) error {
+	args := m.Called(ctx, itemIDs)
+	return args.Error(0)
+}
+
+func (m *MockDigestRepository) CancelPendingDigestItems(ctx context.Context, postID string) (int, error) {
+	args := m.Called(ctx, postID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestGroupDigestItems(t *testing.T) {
+	published := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	items := []models.DigestItem{
+		{ID: "item_1", SubscriberID: "sub_1", PostID: "post_2", CreatedAt: published.Add(time.Hour)},
+		{ID: "item_2", SubscriberID: "sub_2", PostID: "post_1", CreatedAt: published},
+		{ID: "item_3", SubscriberID: "sub_1", PostID: "post_1", CreatedAt: published},
+	}
+
+	digests := groupDigestItems(items)
+
+	require.Len(t, digests, 2)
+	assert.Equal(t, []string{"item_3", "item_1"}, digestItemIDs(digests[0]), "posts are ordered by publication")
+	assert.Equal(t, []string{"item_2"}, digestItemIDs(digests[1]))
+}
+
+func TestDigestWorker_RecordFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    []int
+		sendErr     error
+		expectRetry bool
+	}{
+		{
+			name:        "transient failure is retried",
+			attempts:    []int{0, 0},
+			sendErr:     &textproto.Error{Code: 451, Msg: "try again later"},
+			expectRetry: true,
+		},
+		{
+			name:        "permanent failure is not retried",
+			attempts:    []int{0},
+			sendErr:     &textproto.Error{Code: 550, Msg: "mailbox unavailable"},
+			expectRetry: false,
+		},
+		{
+			name:        "item retried most often decides",
+			attempts:    []int{0, 2},
+			sendErr:     &textproto.Error{Code: 421, Msg: "service not available"},
+			expectRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []models.DigestItem
+			var ids []string
+			for i, attempts := range tt.attempts {
+				id := string(rune('a' + i))
+				items = append(items, models.DigestItem{ID: id, SubscriberID: "sub_1", Email: "reader@example.com", Attempts: attempts})
+				ids = append(ids, id)
+			}
+
+			mockRepo := &MockDigestRepository{}
+			mockRepo.On("MarkDigestItemsFailed", mock.Anything, ids, tt.sendErr.Error(), mock.MatchedBy(func(retryAt *time.Time) bool {
+				return (retryAt != nil) == tt.expectRetry
+			})).Return(nil)
+
+			w := &DigestWorker{
+				digestRepo: mockRepo,
+				config: DigestWorkerConfig{
+					MaxAttempts: 3,
+					RetryBase:   time.Minute,
+					RetryMax:    time.Hour,
+				},
+				logger: log.New(io.Discard, "", 0),
+			}
+			w.recordFailure(context.Background(), items, tt.sendErr)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDigestWorker_DailyLimitDefersDigest(t *testing.T) {
+	mockRepo := &MockDigestRepository{}
+	limiter := newSendLimiter(SendLimits{DailyLimit: 3}, func(ctx context.Context, since time.Time) (int, error) {
+		return 2, nil // Single issues and digests sent today
+	})
+
+	w := &DigestWorker{
+		digestRepo: mockRepo,
+		limiter:    limiter,
+		config:     DigestWorkerConfig{BatchSize: 20, LockTimeout: time.Minute},
+		logger:     log.New(io.Discard, "", 0),
+	}
+
+	// The digest takes the last message of today's quota, whatever number of posts it holds.
+	done, ok := w.acquireSendSlot(context.Background(), []models.DigestItem{
+		{ID: "a", SubscriberID: "sub_1", Email: "one@example.com"},
+		{ID: "b", SubscriberID: "sub_1", Email: "one@example.com"},
+	})
+	require.True(t, ok)
+	done(true)
+
+	// The next digest waits for tomorrow without counting as a failed attempt.
+	var deferredUntil time.Time
+	mockRepo.On("DeferDigestItems", mock.Anything, []string{"c"}, mock.Anything).Run(func(args mock.Arguments) {
+		deferredUntil = args.Get(2).(time.Time)
+	}).Return(nil)
+	_, ok = w.acquireSendSlot(context.Background(), []models.DigestItem{{ID: "c", SubscriberID: "sub_2", Email: "two@example.com"}})
+	assert.False(t, ok)
+
+	now := time.Now().UTC()
+	assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC), deferredUntil)
+	mockRepo.AssertNotCalled(t, "MarkDigestItemsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Nothing more is claimed until the quota resets.
+	w.sendDueDigests(context.Background())
+	mockRepo.AssertNotCalled(t, "ClaimDigestItems", mock.Anything, mock.Anything, mock.Anything)
+}
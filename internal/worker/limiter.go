@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// SendLimits caps how fast the delivery and digest workers hand messages to the email provider, so large issues
+// are paced instead of getting the sender throttled or blocked. A zero value disables the limit.
+type SendLimits struct {
+	RatePerSecond     float64        // Messages per second across all workers
+	DailyLimit        int            // Messages per UTC day; deliveries over the limit roll over to the next day
+	DomainConcurrency int            // Messages sent to the same recipient domain at the same time
+	DomainLimits      map[string]int // Per-domain overrides of DomainConcurrency, keyed by lower-case domain
+}
+
+// validate checks that no limit is negative.
+func (l SendLimits) validate() error {
+	if l.RatePerSecond < 0 || l.DailyLimit < 0 || l.DomainConcurrency < 0 {
+		return fmt.Errorf("send limits must not be negative")
+	}
+	for domain, limit := range l.DomainLimits {
+		if limit < 0 {
+			return fmt.Errorf("send limit for domain %s must not be negative", domain)
+		}
+	}
+	return nil
+}
+
+// SendLimiter enforces SendLimits for all workers of a process; the digest worker shares the delivery worker's.
+// The daily count is seeded from the delivery and digest logs, so it survives restarts. Several processes sharing
+// the queue each count their own sends on top of that, so the limit is only approximate for them.
+type SendLimiter struct {
+	limits    SendLimits
+	rate      *rate.Limiter // nil without a rate limit
+	countSent func(ctx context.Context, since time.Time) (int, error)
+	now       func() time.Time
+
+	mu        sync.Mutex
+	day       time.Time // Start of the UTC day sentToday belongs to; zero until loaded
+	sentToday int       // Messages sent or being sent today
+
+	domainsMu sync.Mutex
+	domains   map[string]*domainSlots
+
+	limitLogMu     sync.Mutex
+	limitLoggedFor time.Time // Reset time of the daily limit last reported as reached
+}
+
+// domainSlots holds the sends in flight to one recipient domain.
+// The entry is dropped once no worker holds or waits for a slot, so the map does not grow with every domain seen.
+type domainSlots struct {
+	slots chan struct{}
+	refs  int
+}
+
+// newSendLimiter creates a limiter. countSent returns the messages already sent since the given time.
+func newSendLimiter(limits SendLimits, countSent func(ctx context.Context, since time.Time) (int, error)) *SendLimiter {
+	l := &SendLimiter{
+		limits:    limits,
+		countSent: countSent,
+		now:       time.Now,
+		domains:   make(map[string]*domainSlots),
+	}
+	if limits.RatePerSecond > 0 {
+		// A burst of one spaces the messages evenly instead of sending a batch at once.
+		l.rate = rate.NewLimiter(rate.Limit(limits.RatePerSecond), 1)
+	}
+	return l
+}
+
+// remaining returns how many messages may still be sent today and when the quota resets.
+// Without a daily limit it returns -1.
+func (l *SendLimiter) remaining(ctx context.Context) (int, time.Time, error) {
+	if l.limits.DailyLimit <= 0 {
+		return -1, time.Time{}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.loadDay(ctx); err != nil {
+		return 0, time.Time{}, err
+	}
+	return max(l.limits.DailyLimit-l.sentToday, 0), l.day.AddDate(0, 0, 1), nil
+}
+
+// reserve takes one message of today's quota. When the quota is used up it returns false and the time
+// the quota resets, when the delivery should be tried again. The returned day is passed to unreserve.
+func (l *SendLimiter) reserve(ctx context.Context) (ok bool, day time.Time, resetAt time.Time, err error) {
+	if l.limits.DailyLimit <= 0 {
+		return true, time.Time{}, time.Time{}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.loadDay(ctx); err != nil {
+		return false, time.Time{}, time.Time{}, err
+	}
+	if l.sentToday >= l.limits.DailyLimit {
+		return false, l.day, l.day.AddDate(0, 0, 1), nil
+	}
+	l.sentToday++
+	return true, l.day, time.Time{}, nil
+}
+
+// unreserve gives back a reservation for a message that was not sent.
+func (l *SendLimiter) unreserve(day time.Time) {
+	if l.limits.DailyLimit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.day.Equal(day) && l.sentToday > 0 {
+		l.sentToday--
+	}
+}
+
+// loadDay starts counting a new day when the UTC date has changed since the last call. Must hold l.mu.
+func (l *SendLimiter) loadDay(ctx context.Context) error {
+	now := l.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if l.day.Equal(today) {
+		return nil
+	}
+
+	sent, err := l.countSent(ctx, today)
+	if err != nil {
+		return fmt.Errorf("counting messages sent today: %w", err)
+	}
+	l.day, l.sentToday = today, sent
+	return nil
+}
+
+// acquire waits until the limits allow a message to the recipient. When today's quota is used up it returns
+// false and the time the quota resets, when the message should be tried again. Otherwise the returned function
+// must be called once the send is over, reporting whether the message went out.
+func (l *SendLimiter) acquire(ctx context.Context, email string) (done func(sent bool), ok bool, resetAt time.Time, err error) {
+	reserved, day, resetAt, err := l.reserve(ctx)
+	if err != nil || !reserved {
+		return nil, false, resetAt, err
+	}
+
+	releaseDomain, err := l.acquireDomain(ctx, email)
+	if err == nil {
+		if err = l.wait(ctx); err != nil {
+			releaseDomain()
+		}
+	}
+	if err != nil {
+		l.unreserve(day)
+		return nil, false, time.Time{}, err
+	}
+
+	return func(sent bool) {
+		releaseDomain()
+		if !sent {
+			l.unreserve(day)
+		}
+	}, true, time.Time{}, nil
+}
+
+// firstLimitReached reports whether this is the first time the daily limit resetting at resetAt was reached,
+// so it is logged once per day rather than by every worker and message.
+func (l *SendLimiter) firstLimitReached(resetAt time.Time) bool {
+	l.limitLogMu.Lock()
+	defer l.limitLogMu.Unlock()
+	if l.limitLoggedFor.Equal(resetAt) {
+		return false
+	}
+	l.limitLoggedFor = resetAt
+	return true
+}
+
+// acquireDomain waits for a free sending slot for the domain of the recipient.
+// The returned function frees the slot and must be called once the send is over.
+func (l *SendLimiter) acquireDomain(ctx context.Context, email string) (func(), error) {
+	domain := recipientDomain(email)
+	limit := l.limits.DomainConcurrency
+	if override, ok := l.limits.DomainLimits[domain]; ok {
+		limit = override
+	}
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	l.domainsMu.Lock()
+	entry, ok := l.domains[domain]
+	if !ok {
+		entry = &domainSlots{slots: make(chan struct{}, limit)}
+		l.domains[domain] = entry
+	}
+	entry.refs++
+	l.domainsMu.Unlock()
+
+	done := func() {
+		l.domainsMu.Lock()
+		defer l.domainsMu.Unlock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.domains, domain)
+		}
+	}
+
+	select {
+	case entry.slots <- struct{}{}:
+		return func() {
+			<-entry.slots
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
+
+// wait blocks until the global rate allows another message.
+func (l *SendLimiter) wait(ctx context.Context) error {
+	if l.rate == nil {
+		return nil
+	}
+	return l.rate.Wait(ctx)
+}
+
+// ready blocks until the global rate would allow another message, without using up the allowance.
+// Workers call it before claiming, so claimed messages do not sit in processing while the rate is saturated.
+func (l *SendLimiter) ready(ctx context.Context) error {
+	if l.rate == nil {
+		return nil
+	}
+	tokens := l.rate.Tokens()
+	if tokens >= 1 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration((1 - tokens) / float64(l.rate.Limit()) * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// claimLimit returns how many messages the workers may hold claimed at once so that all of them go out
+// within the lock timeout at the configured rate. Claiming more would leave messages waiting for the
+// rate until they are reclaimed as abandoned and sent twice. Without a rate limit it returns -1.
+func (l *SendLimiter) claimLimit(lockTimeout time.Duration) int {
+	if l.rate == nil {
+		return -1
+	}
+	// Only half the lock timeout is planned for, leaving room for slow sends and other senders on the limiter.
+	return max(int(l.limits.RatePerSecond*lockTimeout.Seconds()/2), 1)
+}
+
+// recipientDomain returns the lower-case domain of an email address.
+func recipientDomain(email string) string {
+	return strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendLimiter_DailyLimit(t *testing.T) {
+	var countedSince []time.Time
+	limiter := newSendLimiter(SendLimits{DailyLimit: 3}, func(ctx context.Context, since time.Time) (int, error) {
+		countedSince = append(countedSince, since)
+		return 1, nil
+	})
+	now := time.Date(2024, 5, 1, 22, 30, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	remaining, resetAt, err := limiter.remaining(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remaining, "messages sent earlier today count against the limit")
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), resetAt)
+
+	ok, day, _, err := limiter.reserve(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _, _, err = limiter.reserve(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _, resetAt, err = limiter.reserve(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), resetAt)
+
+	// A message that was not sent frees its reservation.
+	limiter.unreserve(day)
+	ok, _, _, err = limiter.reserve(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A new day starts from the messages sent since midnight.
+	now = now.Add(2 * time.Hour)
+	remaining, _, err = limiter.remaining(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}, countedSince)
+
+	// A reservation from the previous day is not given back to the new one.
+	limiter.unreserve(day)
+	remaining, _, err = limiter.remaining(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+}
+
+func TestSendLimiter_DailyLimitCountError(t *testing.T) {
+	limiter := newSendLimiter(SendLimits{DailyLimit: 3}, func(ctx context.Context, since time.Time) (int, error) {
+		return 0, errors.New("connection refused")
+	})
+
+	_, _, _, err := limiter.reserve(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSendLimiter_NoLimits(t *testing.T) {
+	limiter := newSendLimiter(SendLimits{}, nil)
+	ctx := context.Background()
+
+	remaining, _, err := limiter.remaining(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, -1, remaining)
+
+	ok, _, _, err := limiter.reserve(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	release, err := limiter.acquireDomain(ctx, "reader@gmail.com")
+	require.NoError(t, err)
+	release()
+	assert.NoError(t, limiter.wait(ctx))
+}
+
+func TestSendLimiter_DomainConcurrency(t *testing.T) {
+	limiter := newSendLimiter(SendLimits{
+		DomainConcurrency: 2,
+		DomainLimits:      map[string]int{"gmail.com": 1},
+	}, nil)
+	ctx := context.Background()
+
+	releaseGmail, err := limiter.acquireDomain(ctx, "a@gmail.com")
+	require.NoError(t, err)
+
+	// gmail.com is at its limit of one, other domains are not affected.
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquireDomain(waitCtx, "b@GMAIL.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	releaseOther1, err := limiter.acquireDomain(ctx, "a@example.org")
+	require.NoError(t, err)
+	releaseOther2, err := limiter.acquireDomain(ctx, "b@example.org")
+	require.NoError(t, err)
+
+	// A waiting send proceeds as soon as the slot is freed.
+	acquired := make(chan func())
+	go func() {
+		release, err := limiter.acquireDomain(ctx, "c@gmail.com")
+		if err == nil {
+			acquired <- release
+		}
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a slot while gmail.com was at its limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	releaseGmail()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("slot was not handed to the waiting send")
+	}
+
+	releaseOther1()
+	releaseOther2()
+	assert.Empty(t, limiter.domains, "idle domains are forgotten")
+}
+
+func TestSendLimiter_Rate(t *testing.T) {
+	limiter := newSendLimiter(SendLimits{RatePerSecond: 50}, nil)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, limiter.wait(ctx))
+	}
+	// The first message goes out at once, the other four are spaced 20ms apart.
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, limiter.wait(cancelled))
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/repository"
+	"github.com/GOVSEteam/strv-vse-go-newsletter/internal/layers/service"
+)
+
+// PostSchedulerConfig holds tuning parameters for the post scheduler.
+type PostSchedulerConfig struct {
+	PollInterval time.Duration // How often due posts are looked up
+	BatchSize    int           // Due posts published per poll
+}
+
+// PostScheduler publishes posts once their scheduled time has passed.
+// Publishing enqueues deliveries idempotently, so running several instances at once never double-sends.
+type PostScheduler struct {
+	postRepo          repository.PostRepository
+	publishingService service.PublishingServiceInterface
+	config            PostSchedulerConfig
+	logger            *log.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPostScheduler creates a new PostScheduler.
+func NewPostScheduler(
+	postRepo repository.PostRepository,
+	publishingService service.PublishingServiceInterface,
+	config PostSchedulerConfig,
+	logger *log.Logger,
+) (*PostScheduler, error) {
+	if config.PollInterval <= 0 {
+		return nil, fmt.Errorf("scheduler poll interval must be positive")
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("scheduler batch size must be positive")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	return &PostScheduler{
+		postRepo:          postRepo,
+		publishingService: publishingService,
+		config:            config,
+		logger:            logger,
+	}, nil
+}
+
+// Start launches the scheduler goroutine. It runs until Stop is called or ctx is cancelled.
+func (s *PostScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.run(ctx)
+	s.logger.Printf("Post scheduler started, polling every %s", s.config.PollInterval)
+}
+
+// Stop signals the scheduler to finish the current post and waits for it to exit.
+func (s *PostScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.logger.Printf("Post scheduler stopped")
+}
+
+func (s *PostScheduler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDuePosts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDuePosts publishes every post whose scheduled time has passed, one batch at a time.
+func (s *PostScheduler) publishDuePosts(ctx context.Context) {
+	for ctx.Err() == nil {
+		posts, err := s.postRepo.ListDueScheduledPosts(ctx, time.Now().UTC(), s.config.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Printf("Failed to list scheduled posts: %v", err)
+			}
+			return
+		}
+
+		published := 0
+		for _, post := range posts {
+			if ctx.Err() != nil {
+				return
+			}
+			ok, err := s.publishingService.PublishScheduledPost(ctx, post.ID)
+			if err != nil {
+				s.logger.Printf("Failed to publish scheduled post %s: %v", post.ID, err)
+				continue
+			}
+			if !ok {
+				// Cancelled, rescheduled or published by another instance since it was listed.
+				continue
+			}
+			published++
+			s.logger.Printf("Published scheduled post %s (scheduled for %s)", post.ID, post.ScheduledAt.Format(time.RFC3339))
+		}
+
+		// A short batch, or one where nothing was published, means there is nothing more to do until the next tick.
+		if len(posts) < s.config.BatchSize || published == 0 {
+			return
+		}
+	}
+}
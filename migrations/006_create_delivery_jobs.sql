@@ -0,0 +1,34 @@
+-- +goose Up
+-- This migration creates the delivery_jobs table used as a durable queue for outgoing newsletter issues.
+-- Publishing a post enqueues one job per recipient; background workers claim and send them.
+CREATE TABLE IF NOT EXISTS delivery_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    subscriber_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    unsubscribe_token TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    last_error TEXT,
+    locked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT delivery_jobs_post_id_subscriber_id_key UNIQUE (post_id, subscriber_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_jobs_status_created_at ON delivery_jobs(status, created_at);
+
+-- Create trigger function to automatically update updated_at field
+CREATE OR REPLACE FUNCTION update_delivery_jobs_updated_at()
+RETURNS TRIGGER AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+
+-- Create trigger to call the function before each update
+CREATE TRIGGER trigger_delivery_jobs_updated_at
+    BEFORE UPDATE ON delivery_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_delivery_jobs_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_delivery_jobs_updated_at ON delivery_jobs;
+DROP FUNCTION IF EXISTS update_delivery_jobs_updated_at();
+DROP INDEX IF EXISTS idx_delivery_jobs_status_created_at;
+DROP TABLE IF EXISTS delivery_jobs;
@@ -0,0 +1,58 @@
+-- +goose Up
+-- This migration turns the delivery_jobs queue into the per-recipient deliveries log.
+-- Each row now records how many attempts were made, the last SMTP error and when the next retry is due.
+ALTER TABLE delivery_jobs RENAME TO deliveries;
+ALTER INDEX delivery_jobs_pkey RENAME TO deliveries_pkey;
+ALTER TABLE deliveries RENAME CONSTRAINT delivery_jobs_post_id_subscriber_id_key TO deliveries_post_id_subscriber_id_key;
+
+ALTER TABLE deliveries
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN sent_at TIMESTAMPTZ NULL,
+    ADD CONSTRAINT deliveries_status_check
+        CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'permanently_failed'));
+
+DROP INDEX IF EXISTS idx_delivery_jobs_status_created_at;
+CREATE INDEX IF NOT EXISTS idx_deliveries_status_next_attempt_at ON deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_deliveries_post_id ON deliveries(post_id);
+
+DROP TRIGGER IF EXISTS trigger_delivery_jobs_updated_at ON deliveries;
+DROP FUNCTION IF EXISTS update_delivery_jobs_updated_at();
+
+-- Create trigger function to automatically update updated_at field
+CREATE OR REPLACE FUNCTION update_deliveries_updated_at()
+RETURNS TRIGGER AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+
+-- Create trigger to call the function before each update
+CREATE TRIGGER trigger_deliveries_updated_at
+    BEFORE UPDATE ON deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_deliveries_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_deliveries_updated_at ON deliveries;
+DROP FUNCTION IF EXISTS update_deliveries_updated_at();
+
+DROP INDEX IF EXISTS idx_deliveries_post_id;
+DROP INDEX IF EXISTS idx_deliveries_status_next_attempt_at;
+
+UPDATE deliveries SET status = 'failed' WHERE status = 'permanently_failed';
+ALTER TABLE deliveries
+    DROP CONSTRAINT deliveries_status_check,
+    DROP COLUMN sent_at,
+    DROP COLUMN next_attempt_at,
+    DROP COLUMN attempts;
+
+ALTER TABLE deliveries RENAME CONSTRAINT deliveries_post_id_subscriber_id_key TO delivery_jobs_post_id_subscriber_id_key;
+ALTER INDEX deliveries_pkey RENAME TO delivery_jobs_pkey;
+ALTER TABLE deliveries RENAME TO delivery_jobs;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_jobs_status_created_at ON delivery_jobs(status, created_at);
+
+CREATE OR REPLACE FUNCTION update_delivery_jobs_updated_at()
+RETURNS TRIGGER AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_delivery_jobs_updated_at
+    BEFORE UPDATE ON delivery_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_delivery_jobs_updated_at();
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Posts can be scheduled to publish at a future time; the scheduler publishes them once scheduled_at has passed.
+ALTER TABLE posts ADD COLUMN scheduled_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled_at ON posts(scheduled_at)
+    WHERE scheduled_at IS NOT NULL AND published_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_scheduled_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS scheduled_at;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Posts get an explicit lifecycle status instead of deriving it from published_at.
+ALTER TABLE posts
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'draft',
+    ADD CONSTRAINT posts_status_check
+        CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'failed', 'archived'));
+
+-- Backfill: published posts whose deliveries are still pending are sending, other published posts are sent.
+UPDATE posts p SET status = CASE
+    WHEN p.published_at IS NULL AND p.scheduled_at IS NOT NULL THEN 'scheduled'
+    WHEN p.published_at IS NULL THEN 'draft'
+    WHEN EXISTS (
+        SELECT 1 FROM deliveries d
+        WHERE d.post_id = p.id AND d.status IN ('queued', 'processing', 'failed')
+    ) THEN 'sending'
+    ELSE 'sent'
+END;
+
+DROP INDEX IF EXISTS idx_posts_scheduled_at;
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled_at ON posts(scheduled_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_posts_newsletter_id_status ON posts(newsletter_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_newsletter_id_status;
+DROP INDEX IF EXISTS idx_posts_scheduled_at;
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled_at ON posts(scheduled_at)
+    WHERE scheduled_at IS NOT NULL AND published_at IS NULL;
+
+ALTER TABLE posts
+    DROP CONSTRAINT posts_status_check,
+    DROP COLUMN status;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Published posts can be recalled: the post returns to draft and its pending deliveries are cancelled.
+ALTER TABLE posts
+    ADD COLUMN recalled_at TIMESTAMPTZ NULL,
+    ADD COLUMN recalled_by UUID NULL REFERENCES editors(id) ON DELETE SET NULL;
+
+ALTER TABLE deliveries DROP CONSTRAINT deliveries_status_check;
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_status_check
+    CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'permanently_failed', 'cancelled'));
+
+-- +goose Down
+UPDATE deliveries SET status = 'permanently_failed' WHERE status = 'cancelled';
+ALTER TABLE deliveries DROP CONSTRAINT deliveries_status_check;
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_status_check
+    CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'permanently_failed'));
+
+ALTER TABLE posts
+    DROP COLUMN recalled_by,
+    DROP COLUMN recalled_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Editors can override the built-in email templates per newsletter. A missing row means the default is used.
+CREATE TABLE IF NOT EXISTS newsletter_email_templates (
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (newsletter_id, name)
+);
+
+-- Create trigger function to automatically update updated_at field
+CREATE OR REPLACE FUNCTION update_newsletter_email_templates_updated_at()
+RETURNS TRIGGER AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+
+-- Create trigger to call the function before each update
+CREATE TRIGGER trigger_newsletter_email_templates_updated_at
+    BEFORE UPDATE ON newsletter_email_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_newsletter_email_templates_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_newsletter_email_templates_updated_at ON newsletter_email_templates;
+DROP FUNCTION IF EXISTS update_newsletter_email_templates_updated_at();
+DROP TABLE IF EXISTS newsletter_email_templates;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Per-newsletter branding applied to every email sent on behalf of the newsletter.
+-- The mailing address is the sender's physical postal address that CAN-SPAM requires in commercial email.
+ALTER TABLE newsletters
+    ADD COLUMN logo_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN accent_color TEXT NOT NULL DEFAULT '',
+    ADD COLUMN footer_text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN mailing_address TEXT NOT NULL DEFAULT '',
+    ADD COLUMN header_html TEXT NOT NULL DEFAULT '',
+    ADD COLUMN footer_html TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE newsletters
+    DROP COLUMN footer_html,
+    DROP COLUMN header_html,
+    DROP COLUMN mailing_address,
+    DROP COLUMN footer_text,
+    DROP COLUMN accent_color,
+    DROP COLUMN logo_url;
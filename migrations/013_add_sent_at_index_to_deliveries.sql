@@ -0,0 +1,6 @@
+-- +goose Up
+-- The delivery worker counts the messages sent since the start of the day to enforce the daily send limit.
+CREATE INDEX IF NOT EXISTS idx_deliveries_sent_at ON deliveries(sent_at) WHERE status = 'sent';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deliveries_sent_at;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Log of bounced deliveries parsed from delivery status notifications (RFC 3464).
+-- Addresses that hard-bounce too often are marked as bounced in the subscriber store and no longer receive issues.
+CREATE TABLE IF NOT EXISTS bounces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('hard', 'soft')),
+    action TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    diagnostic_code TEXT NOT NULL DEFAULT '',
+    reporting_mta TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounces_email_type ON bounces(email, type);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bounces_email_type;
+DROP TABLE IF EXISTS bounces;
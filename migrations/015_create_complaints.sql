@@ -0,0 +1,19 @@
+-- +goose Up
+-- Log of spam complaints received from mailbox providers' feedback loops as ARF reports (RFC 5965).
+-- Complaints are matched to the subscription through a header embedded in every issue email; the subscriber
+-- is marked as complained in the subscriber store and the per-newsletter complaint rate is shown to the editor.
+CREATE TABLE IF NOT EXISTS complaints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    subscriber_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    feedback_type TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_complaints_newsletter_id_received_at ON complaints(newsletter_id, received_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_complaints_newsletter_id_received_at;
+DROP TABLE IF EXISTS complaints;
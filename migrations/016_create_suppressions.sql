@@ -0,0 +1,23 @@
+-- +goose Up
+-- Suppression list: addresses and whole domains that are never subscribed or sent an issue.
+-- Entries with an editor_id apply to that editor's newsletters, entries without one to the whole instance.
+CREATE TABLE IF NOT EXISTS suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    editor_id UUID NULL REFERENCES editors(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('address', 'domain')),
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'import', 'bounce', 'complaint')),
+    created_by UUID NULL REFERENCES editors(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One entry per value and scope; global entries share the nil UUID.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_suppressions_scope_value
+    ON suppressions(COALESCE(editor_id, '00000000-0000-0000-0000-000000000000'::uuid), type, value);
+CREATE INDEX IF NOT EXISTS idx_suppressions_value ON suppressions(value);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_suppressions_value;
+DROP INDEX IF EXISTS idx_suppressions_scope_value;
+DROP TABLE IF EXISTS suppressions;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Whether subscribers are active right away (single) or only after clicking the link in a confirmation email (double).
+-- Existing newsletters keep single opt-in; newsletters created from now on default to double opt-in.
+ALTER TABLE newsletters
+    ADD COLUMN opt_in_mode TEXT NOT NULL DEFAULT 'single' CHECK (opt_in_mode IN ('single', 'double'));
+ALTER TABLE newsletters
+    ALTER COLUMN opt_in_mode SET DEFAULT 'double';
+
+-- +goose Down
+ALTER TABLE newsletters
+    DROP COLUMN opt_in_mode;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Posts waiting to be sent to subscribers who chose a daily or weekly digest instead of every issue.
+-- Subscribers live in Firestore, so subscriber_id is not a foreign key, like in deliveries.
+CREATE TABLE IF NOT EXISTS digest_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    subscriber_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    unsubscribe_token TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'permanently_failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    locked_at TIMESTAMPTZ NULL,
+    sent_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (post_id, subscriber_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_items_status_due_at ON digest_items(status, due_at);
+CREATE INDEX IF NOT EXISTS idx_digest_items_subscriber_id ON digest_items(subscriber_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_digest_items_subscriber_id;
+DROP INDEX IF EXISTS idx_digest_items_status_due_at;
+DROP TABLE IF EXISTS digest_items;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- The schema of the custom attributes a newsletter's subscribers can carry, as a JSON array of
+-- {key, label, type, required} objects. Subscriber attributes themselves are stored with the subscribers.
+ALTER TABLE newsletters
+    ADD COLUMN subscriber_fields JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE newsletters
+    DROP COLUMN subscriber_fields;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The subscriber's name and custom attributes, captured when the post is published, so the delivery worker
+-- can render the post's merge tags without looking up every recipient.
+ALTER TABLE deliveries
+    ADD COLUMN subscriber_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN subscriber_attributes JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE deliveries
+    DROP COLUMN subscriber_attributes,
+    DROP COLUMN subscriber_name;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Saved segments: rules selecting part of a newsletter's subscribers, which posts can be published to.
+-- Subscribers live in Firestore, so the rules are stored as JSON and evaluated when a post is published.
+CREATE TABLE IF NOT EXISTS segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    match TEXT NOT NULL DEFAULT 'all' CHECK (match IN ('all', 'any')),
+    rules JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (newsletter_id, name)
+);
+
+-- Create trigger function to automatically update updated_at field
+CREATE OR REPLACE FUNCTION update_segments_updated_at()
+RETURNS TRIGGER AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+
+-- Create trigger to call the function before each update
+CREATE TRIGGER trigger_segments_updated_at
+    BEFORE UPDATE ON segments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_segments_updated_at();
+
+-- Engagement rules count the deliveries and digest items of each subscriber.
+CREATE INDEX IF NOT EXISTS idx_deliveries_subscriber_id ON deliveries(subscriber_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deliveries_subscriber_id;
+DROP TRIGGER IF EXISTS trigger_segments_updated_at ON segments;
+DROP FUNCTION IF EXISTS update_segments_updated_at();
+DROP TABLE IF EXISTS segments;
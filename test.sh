@@ -328,7 +328,7 @@ if [[ -n "$EDITOR_1_TOKEN" && -n "$EDITOR_1_NL_ID" ]]; then
             PUBLISH_1_RAW=$(make_request "POST" "${BASE_URL}/api/posts/${EDITOR_1_POST_ID}/publish" \
                 "-H 'Authorization: Bearer ${EDITOR_1_TOKEN}'" "")
             
-            run_test "First Post Publish" check_status "200" "$PUBLISH_1_RAW" "First Post Publish"
+            run_test "First Post Publish" check_status "202" "$PUBLISH_1_RAW" "First Post Publish"
         else
             echo -e "${YELLOW}⚠ Skipping post publish (failed to extract post ID)${NC}"
         fi